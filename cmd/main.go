@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/avealice/filmhub/internal/app"
 	_ "github.com/avealice/filmhub/internal/handler"
 	_ "github.com/avealice/filmhub/internal/model"
@@ -22,5 +24,11 @@ import (
 // @name Authorization
 func main() {
 	a := app.NewApp()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		a.Migrate(os.Args[2:])
+		return
+	}
+
 	a.Run()
 }
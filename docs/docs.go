@@ -18,6 +18,32 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Возвращает набор открытых ключей в формате JWKS (RFC 7517). Ключ для проверки токена выбирается по заголовку kid.\nКлючи алгоритма HS256 являются общими секретами и не публикуются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/.well-known/"
+                ],
+                "summary": "Открытые ключи для проверки токенов",
+                "responses": {
+                    "200": {
+                        "description": "Набор открытых ключей",
+                        "schema": {
+                            "$ref": "#/definitions/model.JSONWebKeySet"
+                        }
+                    },
+                    "405": {
+                        "description": "Некорректный метод",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/actor": {
             "post": {
                 "security": [
@@ -49,9 +75,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Актер успешно создан",
+                        "description": "Созданный актер",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ActorWithMovies"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия созданного актера"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданного актера, например /api/actor/1"
+                            }
                         }
                     },
                     "401": {
@@ -61,7 +97,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -72,6 +108,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Такой актер уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -103,6 +151,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag имеющейся у клиента копии актера",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -110,8 +164,17 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.ActorWithMovies"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия актера"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Актер не изменился, у клиента актуальная копия"
+                    },
                     "400": {
                         "description": "Некорректный запрос или данные",
                         "schema": {
@@ -124,6 +187,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Актер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "405": {
                         "description": "Некорректный метод",
                         "schema": {
@@ -171,13 +240,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.InputActor"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag актера, полученный при его чтении: изменение выполняется, только если актер с тех пор не изменился",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Информация об актере успешно обновлена",
+                        "description": "Обновленный актер",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ActorWithMovies"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия обновленного актера"
+                            }
                         }
                     },
                     "400": {
@@ -193,7 +274,25 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Актер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Актер изменился после чтения, ETag не соответствует заголовку If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -224,13 +323,19 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag актера, полученный при его чтении: изменение выполняется, только если актер с тех пор не изменился",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Актер успешно удален",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.MessageResponse"
                         }
                     },
                     "400": {
@@ -246,7 +351,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Актер изменился или был удален после чтения, ETag не соответствует заголовку If-Match",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -267,7 +378,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получить всех актеров из базы данных.",
+                "description": "Получить всех актеров из базы данных с постраничным выводом.",
                 "produces": [
                     "application/json"
                 ],
@@ -275,14 +386,49 @@ const docTemplate = `{
                     "/api/actors"
                 ],
                 "summary": "Получить всех актеров.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Критерии сортировки через запятую (name, gender, birth_date), префикс - задает порядок по убыванию, например gender,-birth_date",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Порядок сортировки полей без префикса (asc, desc)",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество актеров на странице (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых актеров",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из поля next_cursor",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ActorWithMovies"
-                            }
+                            "$ref": "#/definitions/model.ActorsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные, для параметров сортировки в поле allowed перечислены допустимые значения",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "401": {
@@ -306,38 +452,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/movie": {
-            "post": {
+        "/api/actors/search": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создает новый фильм.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Ищет актеров, имя которых похоже на запрос (например, Di Caprio найдет Leonardo DiCaprio).\nРезультаты упорядочены по убыванию степени сходства, которая возвращается в поле similarity.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "/api/movie"
+                    "/api/actors/search"
                 ],
-                "summary": "Создать фильм",
+                "summary": "Поиск актеров по имени.",
                 "parameters": [
                     {
-                        "description": "Данные нового фильма",
-                        "name": "movie",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.InputMovie"
-                        }
+                        "type": "string",
+                        "description": "Имя актера или его часть",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество актеров (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Фильм создан успешно"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ActorSearchHit"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Некорректный запрос или данные",
@@ -351,8 +504,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Некорректная роль",
+                    "405": {
+                        "description": "Некорректный метод",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -366,51 +519,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/movie/search": {
+        "/api/admin/api-keys": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Выполняет поиск фильмов по указанным критериям (название или актер).",
+                "description": "Возвращает все ключи API, включая отозванные и истекшие. Сами ключи не возвращаются, только их начало (поле prefix).\nТребуется разрешение user:manage.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "/api/movie/search"
-                ],
-                "summary": "Поиск фильмов",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Название фильма для поиска",
-                        "name": "title",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Имя актера для поиска",
-                        "name": "actor",
-                        "in": "query"
-                    }
+                    "/api/admin/api-keys"
                 ],
+                "summary": "Получить все ключи API",
                 "responses": {
                     "200": {
-                        "description": "Список фильмов, удовлетворяющих критериям поиска",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.MovieWithActors"
+                                "$ref": "#/definitions/model.APIKey"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Некорректный запрос или данные",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "401": {
                         "description": "Пустой заголовок авторизации",
                         "schema": {
@@ -418,7 +551,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -430,41 +563,44 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/movie/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получает информацию о фильме по его идентификатору.",
+                "description": "Создает долгоживущий ключ API для доступа сервисов к API. Ключ действует от имени создавшего его пользователя\nи может получить только те разрешения, которые есть у этого пользователя. Сам ключ возвращается только в ответе на этот запрос,\nв базе данных хранится лишь его хеш. Требуется разрешение user:manage.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "/api/movie/{id}"
+                    "/api/admin/api-keys"
                 ],
-                "summary": "Получить информацию о фильме",
+                "summary": "Создать ключ API",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Идентификатор фильма",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Данные ключа",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.APIKeyInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Информация о фильме",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.MovieWithActors"
+                            "$ref": "#/definitions/model.CreatedAPIKey"
                         }
                     },
                     "400": {
-                        "description": "Некорректный запрос или данные",
+                        "description": "Некорректный запрос",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -476,13 +612,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "405": {
-                        "description": "Некорректный метод",
+                    "422": {
+                        "description": "Некорректные данные ключа, например недопустимые разрешения",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -494,45 +630,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/admin/api-keys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Обновляет информацию о фильме.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Отзывает ключ API, после чего запросы с ним отклоняются. Требуется разрешение user:manage.",
                 "tags": [
-                    "/api/movie/{id}"
+                    "/api/admin/api-keys/{id}"
                 ],
-                "summary": "Обновить информацию о фильме",
+                "summary": "Отозвать ключ API",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор фильма",
+                        "description": "Идентификатор ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Новые данные о фильме",
-                        "name": "movie",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.InputMovie"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Фильм обновлен успешно"
+                    "204": {
+                        "description": "Ключ отозван"
                     },
                     "400": {
                         "description": "Некорректный запрос или данные",
@@ -547,7 +670,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ключ не найден или уже отозван",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -565,30 +694,43 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/users": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Удаляет фильм по его идентификатору.",
+                "description": "Возвращает список пользователей с постраничным выводом. Требуется разрешение user:manage.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "/api/movie/{id}"
+                    "/api/admin/users"
                 ],
-                "summary": "Удалить фильм",
+                "summary": "Получить всех пользователей",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор фильма",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Количество пользователей на странице (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых пользователей",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Фильм удален успешно"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UsersPage"
+                        }
                     },
                     "400": {
                         "description": "Некорректный запрос или данные",
@@ -603,7 +745,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Некорректная роль",
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -623,43 +765,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/movies": {
+        "/api/admin/users/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получает список всех фильмов с возможностью сортировки.",
+                "description": "Возвращает информацию о пользователе по его идентификатору. Требуется разрешение user:manage.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "/api/movies"
+                    "/api/admin/users/{id}"
                 ],
-                "summary": "Получить все фильмы",
+                "summary": "Получить пользователя",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Критерий сортировки (title, rating, release_date)",
-                        "name": "sort_by",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Порядок сортировки (asc, desc)",
-                        "name": "sort_order",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список фильмов",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Movie"
-                            }
+                            "$ref": "#/definitions/model.UserAccount"
                         }
                     },
                     "400": {
@@ -674,14 +808,1457 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "405": {
-                        "description": "Некорректный метод",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет пользователя вместе с его токенами обновления. Требуется разрешение user:manage.",
+                "tags": [
+                    "/api/admin/users/{id}"
+                ],
+                "summary": "Удалить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пользователь удален"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Нельзя удалить самого себя",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет роль пользователя, блокирует или разблокирует его или задает новый пароль. Поля, которые не указаны, не изменяются.\nЗаблокированный пользователь не может войти в систему, а его уже выданные токены перестают действовать.\nБлокировка и смена пароля отзывают все токены обновления пользователя. Требуется разрешение user:manage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/admin/users/{id}"
+                ],
+                "summary": "Изменить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UserAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Нельзя заблокировать или понизить в правах самого себя",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Неизвестная роль",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает имя, роль, время регистрации и настройки пользователя, от имени которого выполняется запрос.\nДля ключа API возвращается учетная запись его создателя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/me"
+                ],
+                "summary": "Текущий пользователь",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Profile"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет настройки текущего пользователя: сортировку списка фильмов по умолчанию (movie_sort, в формате параметра sort_by)\nи предпочитаемый язык (locale, например ru или en-US). Поля, которые не указаны, не изменяются, пустая строка возвращает значение по умолчанию.\nНедоступно для ключей API.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/me"
+                ],
+                "summary": "Изменить настройки",
+                "parameters": [
+                    {
+                        "description": "Изменения настроек",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdatePreferencesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрос аутентифицирован ключом API",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет пароль текущего пользователя после проверки текущего пароля. Все сеансы пользователя завершаются,\nа в ответе возвращается новая пара токенов, чтобы текущий клиент остался в системе.\nНеверный текущий пароль считается неудачной попыткой входа. Недоступно для ключей API.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/me"
+                ],
+                "summary": "Смена пароля",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChangePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Новая пара токенов",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос, неверный текущий пароль",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрос аутентифицирован ключом API",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, новый пароль не соответствует требованиям",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток, время ожидания в секундах указано в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movie": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает новый фильм.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/movie"
+                ],
+                "summary": "Создать фильм",
+                "parameters": [
+                    {
+                        "description": "Данные нового фильма",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InputMovie"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный фильм",
+                        "schema": {
+                            "$ref": "#/definitions/model.MovieWithActors"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия созданного фильма"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданного фильма, например /api/movie/1"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Такой фильм уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movie/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Выполняет поиск фильмов по указанным критериям (полнотекстовый запрос, название или актер).\nПолнотекстовый поиск по параметру q ищет по названию и описанию фильма и упорядочивает результаты по релевантности.\nСлова в кавычках ищутся как фраза, слово с * на конце - как префикс. Каждый результат такого поиска\nдополнительно содержит поля rank (релевантность) и snippet (фрагмент описания, экранированный для HTML, совпадения выделены тегом mark).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/movie/search"
+                ],
+                "summary": "Поиск фильмов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Полнотекстовый запрос, например termin* или фраза в кавычках",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название фильма для поиска",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя актера для поиска",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Режим поиска по актеру: substring (по подстроке, по умолчанию) или fuzzy (с учетом опечаток, результаты упорядочены по степени сходства имени)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Критерии сортировки через запятую (title, rating, release_date), префикс - задает порядок по убыванию, например rating,-release_date",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Порядок сортировки полей без префикса (asc, desc)",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество результатов полнотекстового поиска (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых результатов полнотекстового поиска",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список фильмов, удовлетворяющих критериям поиска",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.MovieWithActors"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movie/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получает информацию о фильме по его идентификатору.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/movie/{id}"
+                ],
+                "summary": "Получить информацию о фильме",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag имеющейся у клиента копии фильма",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Информация о фильме",
+                        "schema": {
+                            "$ref": "#/definitions/model.MovieWithActors"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия фильма"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Фильм не изменился, у клиента актуальная копия"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Фильм не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Некорректный метод",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Заменяет все поля фильма и его список актеров данными из запроса. Поля, которые не указаны, получают пустые значения,\nа актеры, которых нет в списке actors, удаляются из фильма. Актеры из списка, которых еще нет, создаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/movie/{id}"
+                ],
+                "summary": "Заменить фильм",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные о фильме",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InputMovie"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag фильма, полученный при его чтении: изменение выполняется, только если фильм с тех пор не изменился",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный фильм",
+                        "schema": {
+                            "$ref": "#/definitions/model.MovieWithActors"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия обновленного фильма"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Фильм не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Некорректный метод",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Фильм изменился после чтения, ETag не соответствует заголовку If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет фильм по его идентификатору.",
+                "tags": [
+                    "/api/movie/{id}"
+                ],
+                "summary": "Удалить фильм",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag фильма, полученный при его чтении: изменение выполняется, только если фильм с тех пор не изменился",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Фильм удален успешно",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Некорректный метод",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Фильм изменился или был удален после чтения, ETag не соответствует заголовку If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет фильм по документу JSON Merge Patch (RFC 7396): поля, которые не указаны, не изменяются,\nа поле со значением null удаляется - описание очищается, рейтинг становится равным 0, а название и дату выхода удалить нельзя.\nСписок актеров изменяется эндпоинтами /api/movie/{id}/actors/{actorId}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/movie/{id}"
+                ],
+                "summary": "Изменить фильм",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения фильма",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PatchMovieInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag фильма, полученный при его чтении: изменение выполняется, только если фильм с тех пор не изменился",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный фильм",
+                        "schema": {
+                            "$ref": "#/definitions/model.MovieWithActors"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия обновленного фильма"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Фильм не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Фильм изменился после чтения, ETag не соответствует заголовку If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса не является документом JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movie/{id}/actors/{actorId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Добавляет существующего актера в список актеров фильма. Повторное добавление не изменяет фильм.",
+                "tags": [
+                    "/api/movie/{id}"
+                ],
+                "summary": "Добавить актера в фильм",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор актера",
+                        "name": "actorId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Актер добавлен в фильм"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Фильм или актер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет актера из списка актеров фильма. Сам актер не удаляется.",
+                "tags": [
+                    "/api/movie/{id}"
+                ],
+                "summary": "Удалить актера из фильма",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор актера",
+                        "name": "actorId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Актер удален из фильма"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Актера нет в фильме",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получает список всех фильмов с возможностью фильтрации, сортировки и постраничного вывода.\nВсе указанные фильтры применяются одновременно.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/api/movies"
+                ],
+                "summary": "Получить все фильмы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фрагмент названия фильма",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальный рейтинг включительно (0-10)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный рейтинг включительно (0-10)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Самая ранняя дата выхода включительно (YYYY-MM-DD)",
+                        "name": "released_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Самая поздняя дата выхода включительно (YYYY-MM-DD)",
+                        "name": "released_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификатор актера, можно указать несколько раз: фильмы, в которых снялся хотя бы один из актеров",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Критерии сортировки через запятую (title, rating, release_date), префикс - задает порядок по убыванию, например rating,-release_date. По умолчанию используется сортировка из настроек пользователя (movie_sort)",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Порядок сортировки полей без префикса (asc, desc)",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество фильмов на странице (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых фильмов",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из поля next_cursor",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница списка фильмов",
+                        "schema": {
+                            "$ref": "#/definitions/model.MoviesPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные, для параметров сортировки в поле allowed перечислены допустимые значения",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Некорректный метод",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Выдает одноразовый токен сброса пароля и отправляет его пользователю. Ответ не зависит от того,\nсуществует ли пользователь, чтобы по нему нельзя было узнать занятые имена. Частые запросы для одного\nимени пользователя или с одного адреса игнорируются, но ответ на них тот же.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/auth/"
+                ],
+                "summary": "Запрос сброса пароля",
+                "parameters": [
+                    {
+                        "description": "Имя пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ForgotPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Если пользователь существует, токен отправлен"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отзывает текущий токен доступа и, если он передан, токен обновления вместе со всеми токенами, полученными при том же входе.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/auth/"
+                ],
+                "summary": "Выход из системы",
+                "parameters": [
+                    {
+                        "description": "Токен обновления",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сеанс завершен"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные, запрос аутентифицирован ключом API",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пустой заголовок авторизации",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Принимает код авторизации от поставщика, проверяет токен ID и выдает токены FilmHub.\nПри первом входе создается пользователь с именем из токена ID, роль пользователя при каждом входе определяется его группами у поставщика.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/auth/"
+                ],
+                "summary": "Завершение входа через поставщика удостоверений",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код авторизации",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Состояние, выданное при начале входа",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токен доступа и токен обновления",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос, недействительное или истекшее состояние входа",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Поставщик отклонил вход или токен ID недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Учетная запись заблокирована",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Вход через поставщика не настроен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Имя пользователя занято локальной учетной записью",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Поставщик удостоверений недоступен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Начинает вход по протоколу OpenID Connect (authorization code flow) и перенаправляет пользователя на страницу входа поставщика.\nПосле входа поставщик возвращает пользователя на /auth/oidc/callback. Доступно, если поставщик задан в разделе oidc файла конфигурации.",
+                "tags": [
+                    "/auth/"
+                ],
+                "summary": "Вход через поставщика удостоверений",
+                "responses": {
+                    "302": {
+                        "description": "Перенаправление на страницу входа поставщика"
+                    },
+                    "404": {
+                        "description": "Вход через поставщика не настроен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Некорректный метод",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Поставщик удостоверений недоступен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Обменивает токен обновления на новую пару токенов. Каждый токен обновления можно использовать только один раз:\nповторное использование уже обмененного токена отзывает все токены, полученные при том же входе.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/auth/"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "description": "Токен обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Новая пара токенов",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или данные",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Недействительный токен обновления",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Задает новый пароль по одноразовому токену, полученному через /auth/forgot-password.\nВсе сеансы пользователя завершаются: токены обновления и выданные ранее токены доступа отзываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "/auth/"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен сброса и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ResetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пароль изменен"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос, недействительный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Учетная запись заблокирована",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, новый пароль не соответствует требованиям",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -691,7 +2268,7 @@ const docTemplate = `{
         },
         "/auth/sign-in": {
             "post": {
-                "description": "Авторизует пользователя с заданными учетными данными и возвращает токен доступа",
+                "description": "Авторизует пользователя с заданными учетными данными и возвращает короткоживущий токен доступа\nи токен обновления, по которому можно получить новую пару токенов через /auth/refresh.\nПосле нескольких неудачных попыток входа для имени пользователя или IP-адреса вход временно блокируется.",
                 "consumes": [
                     "application/json"
                 ],
@@ -715,7 +2292,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Токен доступа",
+                        "description": "Токен доступа и токен обновления",
                         "schema": {
                             "$ref": "#/definitions/handler.TokenResponse"
                         }
@@ -726,6 +2303,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Неверное имя пользователя или пароль",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Учетная запись заблокирована",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток входа, время ожидания в секундах указано в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -737,7 +2332,7 @@ const docTemplate = `{
         },
         "/auth/sign-up": {
             "post": {
-                "description": "Регистрирует нового пользователя с заданными данными",
+                "description": "Регистрирует нового пользователя с заданными данными. Имя пользователя должно состоять из 3-32 латинских букв, цифр,\nточек, дефисов и подчеркиваний, пароль - соответствовать парольной политике. Все ошибки в данных возвращаются в поле errors.",
                 "consumes": [
                     "application/json"
                 ],
@@ -755,7 +2350,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/handler.SignUpInput"
                         }
                     }
                 ],
@@ -767,7 +2362,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Некорректный запрос или данные",
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Имя пользователя уже занято",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли проверку, в поле errors перечислены некорректные поля",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -780,25 +2387,119 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ErrorResponse": {
-            "description": "JSON-структура ответа с сообщением об ошибке.",
+        }
+    },
+    "definitions": {
+        "handler.ChangePasswordInput": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "description": "Текущий пароль",
+                    "type": "string"
+                },
+                "new_password": {
+                    "description": "Новый пароль, соответствующий парольной политике",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "description": "Описание ошибки в формате RFC 7807.",
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "description": "Допустимые значения параметра сортировки",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code": {
+                    "description": "Стабильный машиночитаемый код ошибки, например movie_not_found",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "Описание конкретной ошибки",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Ошибки отдельных полей запроса",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FieldError"
+                    }
+                },
+                "status": {
+                    "description": "HTTP-статус ответа",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Краткое описание статуса ответа",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "URI типа ошибки, about:blank означает, что смысл ошибки определяется статусом",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ForgotPasswordInput": {
+            "type": "object",
+            "properties": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RefreshInput": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ResetPasswordInput": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "description": "Новый пароль, соответствующий парольной политике",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Одноразовый токен сброса пароля",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SignInInput": {
             "type": "object",
             "properties": {
-                "message": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "handler.SignInInput": {
+        "handler.SignUpInput": {
             "type": "object",
             "properties": {
                 "password": {
+                    "description": "Пароль, соответствующий парольной политике",
                     "type": "string"
                 },
                 "username": {
+                    "description": "Имя пользователя: от 3 до 32 латинских букв, цифр, точек, дефисов и подчеркиваний",
                     "type": "string"
                 }
             }
@@ -806,7 +2507,16 @@ const docTemplate = `{
         "handler.TokenResponse": {
             "type": "object",
             "properties": {
+                "expires_in": {
+                    "description": "Время жизни токена доступа в секундах",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "description": "Одноразовый токен для получения новой пары токенов",
+                    "type": "string"
+                },
                 "token": {
+                    "description": "Токен доступа",
                     "type": "string"
                 }
             }
@@ -819,6 +2529,70 @@ const docTemplate = `{
                 }
             }
         },
+        "model.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Creation time of the key",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Expiration time of the key, nil if the key does not expire",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier of the key",
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "description": "Time the key was last used to authenticate a request",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Human readable name of the key",
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Permissions the key is scoped to",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prefix": {
+                    "description": "First characters of the key that help to recognize it",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Revocation time of the key, nil if the key is active",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Administrator who created the key and on whose behalf it acts",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.APIKeyInput": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "Expiration time of the key, omit for a key that does not expire",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Human readable name of the key",
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Permissions the key is scoped to, a subset of the creator's permissions",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "model.Actor": {
             "type": "object",
             "properties": {
@@ -836,6 +2610,38 @@ const docTemplate = `{
                 }
             }
         },
+        "model.ActorSearchHit": {
+            "type": "object",
+            "properties": {
+                "birth_date": {
+                    "description": "Format: \"YYYY-M-D\".",
+                    "type": "string"
+                },
+                "gender": {
+                    "description": "Valid values: \"male\", \"female\", \"other\".",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier for the actor",
+                    "type": "integer"
+                },
+                "movies": {
+                    "description": "Movies associated with the actor",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Movie"
+                    }
+                },
+                "name": {
+                    "description": "Name of the actor",
+                    "type": "string"
+                },
+                "similarity": {
+                    "description": "Word similarity of the name to the search query, from 0 to 1",
+                    "type": "number"
+                }
+            }
+        },
         "model.ActorWithMovies": {
             "type": "object",
             "properties": {
@@ -864,6 +2670,87 @@ const docTemplate = `{
                 }
             }
         },
+        "model.ActorsPage": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "description": "Actors on the page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ActorWithMovies"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Cursor for the next page, empty on the last one",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total number of actors",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Creation time of the key",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Expiration time of the key, nil if the key does not expire",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier of the key",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "The key itself, shown only once",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Time the key was last used to authenticate a request",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Human readable name of the key",
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Permissions the key is scoped to",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prefix": {
+                    "description": "First characters of the key that help to recognize it",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Revocation time of the key, nil if the key is active",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Administrator who created the key and on whose behalf it acts",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Name of the field as it appears in the request",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Reason the value was rejected",
+                    "type": "string"
+                }
+            }
+        },
         "model.InputActor": {
             "type": "object",
             "properties": {
@@ -916,6 +2803,59 @@ const docTemplate = `{
                 }
             }
         },
+        "model.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "description": "Signing algorithm: RS256, ES256 or EdDSA",
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Curve of an EC key (P-256) or an OKP key (Ed25519)",
+                    "type": "string"
+                },
+                "e": {
+                    "description": "RSA public exponent",
+                    "type": "string"
+                },
+                "kid": {
+                    "description": "Identifier matching the kid header of tokens",
+                    "type": "string"
+                },
+                "kty": {
+                    "description": "Key type: RSA, EC or OKP",
+                    "type": "string"
+                },
+                "n": {
+                    "description": "RSA modulus",
+                    "type": "string"
+                },
+                "use": {
+                    "description": "Intended use of the key, always sig",
+                    "type": "string"
+                },
+                "x": {
+                    "description": "Ed25519 public key or x coordinate of an EC public key",
+                    "type": "string"
+                },
+                "y": {
+                    "description": "y coordinate of an EC public key",
+                    "type": "string"
+                }
+            }
+        },
+        "model.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "description": "Public keys",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.JSONWebKey"
+                    }
+                }
+            }
+        },
         "model.Movie": {
             "type": "object",
             "properties": {
@@ -969,11 +2909,136 @@ const docTemplate = `{
                 }
             }
         },
-        "model.User": {
+        "model.MoviesPage": {
+            "type": "object",
+            "properties": {
+                "movies": {
+                    "description": "Movies on the page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MovieWithActors"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Cursor for the next page, empty on the last one",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total number of movies",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PatchMovieInput": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "New description of the movie",
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "New rating of the movie",
+                    "type": "integer"
+                },
+                "release_date": {
+                    "description": "New release date, format: \"YYYY-M-D\".",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "New title of the movie",
+                    "type": "string"
+                }
+            }
+        },
+        "model.Preferences": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "description": "Preferred language as a BCP 47 tag, e.g. \"ru\" or \"en-US\"",
+                    "type": "string"
+                },
+                "movie_sort": {
+                    "description": "Default sort of the movie list in the format of sort_by, e.g. \"-rating,title\"",
+                    "type": "string"
+                }
+            }
+        },
+        "model.Profile": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Registration time of the user",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier for the user",
+                    "type": "integer"
+                },
+                "preferences": {
+                    "description": "Settings of the user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Preferences"
+                        }
+                    ]
+                },
+                "role": {
+                    "description": "Role of the user",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Username of the user",
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpdatePreferencesInput": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "description": "New preferred language",
+                    "type": "string"
+                },
+                "movie_sort": {
+                    "description": "New default sort of the movie list",
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpdateUserInput": {
             "type": "object",
             "properties": {
+                "disabled": {
+                    "description": "Whether the user is not allowed to sign in",
+                    "type": "boolean"
+                },
                 "password": {
-                    "description": "Password hash of the user",
+                    "description": "New password of the user",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "New role of the user",
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserAccount": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Registration time of the user",
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Whether the user is not allowed to sign in",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "Unique identifier for the user",
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "Role of the user",
                     "type": "string"
                 },
                 "username": {
@@ -981,6 +3046,22 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "model.UsersPage": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "description": "Total number of users",
+                    "type": "integer"
+                },
+                "users": {
+                    "description": "Users on the page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserAccount"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -9,11 +11,14 @@ import (
 	"time"
 
 	"github.com/avealice/filmhub/internal/handler"
+	"github.com/avealice/filmhub/internal/migrate"
 	"github.com/avealice/filmhub/internal/repository"
 	"github.com/avealice/filmhub/internal/service"
+	"github.com/avealice/filmhub/migrations"
 
 	_ "github.com/lib/pq"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -35,34 +40,62 @@ func initConfig() error {
 }
 
 func (a *App) Run() {
-	logrus.SetLevel(logrus.InfoLevel)
-	logrus.SetFormatter(&logrus.TextFormatter{
-		ForceColors: true,
-	})
-	logrus.SetOutput(os.Stdout)
+	initLogger()
 
-	if err := initConfig(); err != nil {
-		logrus.Fatalf("error initializing configs: %s", err)
+	db := initDB()
+
+	if viper.GetBool("migrations.run_on_startup") {
+		migrator, err := migrate.New(db, migrations.FS)
+		if err != nil {
+			logrus.Fatalf("failed to initialize migrations: %s", err)
+		}
+
+		if err := migrator.Up(context.Background()); err != nil {
+			logrus.Fatalf("failed to apply migrations: %s", err)
+		}
+
+		logrus.Info("Migrations applied")
 	}
 
-	if err := godotenv.Load(); err != nil {
-		logrus.Fatalf("error loading env variables: %s", err.Error())
+	keys, err := initSigningKeys()
+	if err != nil {
+		logrus.Fatalf("failed to load token signing keys: %s", err)
 	}
 
-	db, err := repository.NewPostgresDB(repository.Config{
-		Host:     viper.GetString("db.host"),
-		Port:     viper.GetString("db.port"),
-		Username: viper.GetString("db.username"),
-		DBName:   viper.GetString("db.dbname"),
-		SSLMode:  viper.GetString("db.sslmode"),
-		Password: os.Getenv("DB_PASSWORD"),
-	})
+	passwordPolicy, err := initPasswordPolicy()
 	if err != nil {
-		logrus.Fatalf("failed to initialize db: %s", err)
+		logrus.Fatalf("failed to load password policy: %s", err)
+	}
+
+	notifier, err := initNotifier()
+	if err != nil {
+		logrus.Fatalf("failed to initialize notifier: %s", err)
+	}
+
+	oidc, err := initOIDC()
+	if err != nil {
+		logrus.Fatalf("failed to load OIDC settings: %s", err)
 	}
 
 	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
+	services := service.NewService(repos, service.Config{
+		Auth: service.AuthConfig{
+			TokenTTL:        viper.GetDuration("auth.token_ttl"),
+			RefreshTokenTTL: viper.GetDuration("auth.refresh_token_ttl"),
+			Keys:            keys,
+			LoginThrottle: service.ThrottleConfig{
+				Username: throttlePolicy("auth.login_throttle.username"),
+				IP:       throttlePolicy("auth.login_throttle.ip"),
+			},
+			PasswordPolicy:   passwordPolicy,
+			PasswordResetTTL: viper.GetDuration("auth.password_reset_ttl"),
+			Notifier:         notifier,
+		},
+		OIDC: oidc,
+		Search: service.SearchConfig{
+			SimilarityThreshold: viper.GetFloat64("search.similarity_threshold"),
+		},
+	})
 	handlers := handler.NewHandler(services)
 
 	if err := a.run(viper.GetString("port"), handlers.InitRoutes()); err != nil {
@@ -84,6 +117,173 @@ func (a *App) Run() {
 	}
 }
 
+// signingKeyConfig describes a token signing key in the configuration file.
+// Secrets of HS256 keys are read from the environment variable named by SecretEnv.
+type signingKeyConfig struct {
+	ID        string `mapstructure:"id"`
+	Algorithm string `mapstructure:"algorithm"`
+	SecretEnv string `mapstructure:"secret_env"`
+	File      string `mapstructure:"file"`
+}
+
+// initSigningKeys loads the token signing keys. There is no default key: the application does not start
+// if no key is configured or the environment variable with the secret of an HS256 key is empty.
+func initSigningKeys() (*service.KeySet, error) {
+	var configs []signingKeyConfig
+	if err := viper.UnmarshalKey("auth.keys", &configs); err != nil {
+		return nil, err
+	}
+
+	if len(configs) == 0 {
+		return nil, errors.New("no signing keys are configured in auth.keys")
+	}
+
+	keys := make([]service.KeyConfig, 0, len(configs))
+	for _, cfg := range configs {
+		key := service.KeyConfig{
+			ID:        cfg.ID,
+			Algorithm: cfg.Algorithm,
+			File:      cfg.File,
+		}
+		if cfg.SecretEnv != "" {
+			key.Secret = os.Getenv(cfg.SecretEnv)
+			if key.Secret == "" {
+				return nil, fmt.Errorf("secret of signing key %q is not set: environment variable %s is empty", cfg.ID, cfg.SecretEnv)
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	return service.NewKeySet(keys, viper.GetString("auth.active_key"))
+}
+
+// throttlePolicy reads a sign-in throttling policy from the configuration section key.
+// Unset values fall back to the defaults of the service.
+func throttlePolicy(key string) service.ThrottlePolicy {
+	return service.ThrottlePolicy{
+		FreeAttempts:    viper.GetInt(key + ".free_attempts"),
+		BaseDelay:       viper.GetDuration(key + ".base_delay"),
+		MaxDelay:        viper.GetDuration(key + ".max_delay"),
+		LockoutAfter:    viper.GetInt(key + ".lockout_after"),
+		LockoutDuration: viper.GetDuration(key + ".lockout_duration"),
+		ResetAfter:      viper.GetDuration(key + ".reset_after"),
+	}
+}
+
+func initPasswordPolicy() (service.PasswordPolicy, error) {
+	policy := service.PasswordPolicy{
+		MinLength:        viper.GetInt("auth.password_policy.min_length"),
+		MaxLength:        viper.GetInt("auth.password_policy.max_length"),
+		RequireLowercase: viper.GetBool("auth.password_policy.require_lowercase"),
+		RequireUppercase: viper.GetBool("auth.password_policy.require_uppercase"),
+		RequireDigit:     viper.GetBool("auth.password_policy.require_digit"),
+		RequireSymbol:    viper.GetBool("auth.password_policy.require_symbol"),
+	}
+
+	path := viper.GetString("auth.password_policy.denylist_file")
+	if path == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return service.PasswordPolicy{}, err
+	}
+	defer file.Close()
+
+	policy.Denylist, err = service.ReadPasswordDenylist(file)
+	if err != nil {
+		return service.PasswordPolicy{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return policy, nil
+}
+
+// oidcRoleMappingConfig describes a group to role rule of the OIDC provider in the configuration file.
+type oidcRoleMappingConfig struct {
+	Group string `mapstructure:"group"`
+	Role  string `mapstructure:"role"`
+}
+
+func initOIDC() (service.OIDCConfig, error) {
+	var mappings []oidcRoleMappingConfig
+	if err := viper.UnmarshalKey("oidc.role_mapping", &mappings); err != nil {
+		return service.OIDCConfig{}, err
+	}
+
+	cfg := service.OIDCConfig{
+		Issuer:        viper.GetString("oidc.issuer"),
+		ClientID:      viper.GetString("oidc.client_id"),
+		RedirectURL:   viper.GetString("oidc.redirect_url"),
+		Scopes:        viper.GetStringSlice("oidc.scopes"),
+		UsernameClaim: viper.GetString("oidc.username_claim"),
+		GroupsClaim:   viper.GetString("oidc.groups_claim"),
+		DefaultRole:   viper.GetString("oidc.default_role"),
+		StateTTL:      viper.GetDuration("oidc.state_ttl"),
+	}
+	if env := viper.GetString("oidc.client_secret_env"); env != "" {
+		cfg.ClientSecret = os.Getenv(env)
+	}
+	for _, mapping := range mappings {
+		cfg.RoleMapping = append(cfg.RoleMapping, service.OIDCRoleMapping{Group: mapping.Group, Role: mapping.Role})
+	}
+
+	if cfg.Issuer != "" && (cfg.ClientID == "" || cfg.RedirectURL == "") {
+		return service.OIDCConfig{}, errors.New("oidc.client_id and oidc.redirect_url are required")
+	}
+
+	return cfg, nil
+}
+
+// initNotifier creates the notifier that delivers password reset tokens to users.
+func initNotifier() (service.Notifier, error) {
+	switch kind := viper.GetString("notifier.type"); kind {
+	case "", "stdout":
+		return service.NewWriterNotifier(os.Stdout), nil
+	case "file":
+		file, err := os.OpenFile(viper.GetString("notifier.file"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		return service.NewWriterNotifier(file), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", kind)
+	}
+}
+
+func initLogger() {
+	logrus.SetLevel(logrus.InfoLevel)
+	logrus.SetFormatter(&logrus.TextFormatter{
+		ForceColors: true,
+	})
+	logrus.SetOutput(os.Stdout)
+}
+
+// initDB reads the configuration and environment variables and connects to the database.
+func initDB() *sqlx.DB {
+	if err := initConfig(); err != nil {
+		logrus.Fatalf("error initializing configs: %s", err)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logrus.Fatalf("error loading env variables: %s", err.Error())
+	}
+
+	db, err := repository.NewPostgresDB(repository.Config{
+		Host:     viper.GetString("db.host"),
+		Port:     viper.GetString("db.port"),
+		Username: viper.GetString("db.username"),
+		DBName:   viper.GetString("db.dbname"),
+		SSLMode:  viper.GetString("db.sslmode"),
+		Password: os.Getenv("DB_PASSWORD"),
+	})
+	if err != nil {
+		logrus.Fatalf("failed to initialize db: %s", err)
+	}
+
+	return db
+}
+
 func (a *App) run(port string, handler http.Handler) error {
 	a.httpServer = &http.Server{
 		Addr:           ":" + port,
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/avealice/filmhub/internal/migrate"
+	"github.com/avealice/filmhub/migrations"
+
+	"github.com/sirupsen/logrus"
+)
+
+const migrateUsage = `usage: filmhub migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    revert the last N applied migrations (default 1)
+  status      list migrations and whether they are applied
+  goto N      migrate up or down to version N (0 reverts all migrations)`
+
+// Migrate runs the migrate subcommand with the given arguments.
+func (a *App) Migrate(args []string) {
+	initLogger()
+
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	db := initDB()
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		logrus.Fatalf("failed to initialize migrations: %s", err)
+	}
+
+	ctx := context.Background()
+
+	switch command := args[0]; command {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				logrus.Fatalf("invalid number of migrations: %s", args[1])
+			}
+		}
+		err = migrator.Down(ctx, n)
+	case "goto":
+		if len(args) < 2 {
+			logrus.Fatal("goto requires a version")
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			logrus.Fatalf("invalid version: %s", args[1])
+		}
+		err = migrator.Goto(ctx, version)
+	case "status":
+		err = printStatus(ctx, migrator)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s\n", command, migrateUsage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		logrus.Fatalf("migrate %s failed: %s", args[0], err)
+	}
+}
+
+func printStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return w.Flush()
+}
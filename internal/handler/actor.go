@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/avealice/filmhub/internal/model"
@@ -15,24 +14,42 @@ import (
 // getAllActors получает всех актеров.
 //
 // @Summary Получить всех актеров.
-// @Description Получить всех актеров из базы данных.
+// @Description Получить всех актеров из базы данных с постраничным выводом.
 // @Tags /api/actors
 // @Produce json
-// @Success 200 {array} model.ActorWithMovies
+// @Param sort_by query string false "Критерии сортировки через запятую (name, gender, birth_date), префикс - задает порядок по убыванию, например gender,-birth_date"
+// @Param sort_order query string false "Порядок сортировки полей без префикса (asc, desc)"
+// @Param limit query int false "Количество актеров на странице (1-100, по умолчанию 20)"
+// @Param offset query int false "Количество пропускаемых актеров"
+// @Param after query string false "Курсор следующей страницы из поля next_cursor"
+// @Success 200 {object} model.ActorsPage
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные, для параметров сортировки в поле allowed перечислены допустимые значения"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/actors [get]
 // @Security ApiKeyAuth
 func (h *Handler) getAllActors(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	sort, err := parseActorSort(r)
+	if err != nil {
+		newSortErrorResponse(w, err)
+		return
+	}
+
+	page, err := parsePagination(r)
+	if err != nil {
+		newBadRequestResponse(w, err)
 		return
 	}
 
-	actors, err := h.services.Actor.GetAllActors()
+	actors, err := h.services.Actor.GetAllActors(sort, page)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "Failed to get actors")
+		if errors.As(err, new(*model.SortError)) {
+			newSortErrorResponse(w, err)
+			return
+		}
+
+		newServiceErrorResponse(w, err, "Failed to get actors")
 		return
 	}
 
@@ -40,13 +57,67 @@ func (h *Handler) getAllActors(w http.ResponseWriter, r *http.Request) {
 
 	logrus.WithFields(logrus.Fields{
 		"user_id": userID,
-		"count":   len(actors),
+		"sort":    sort.String(),
+		"count":   len(actors.Actors),
+		"total":   actors.Total,
 	}).Info("Actors successfully fetched")
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(actors)
 }
 
+// searchActors ищет актеров по имени с учетом опечаток.
+//
+// @Summary Поиск актеров по имени.
+// @Description Ищет актеров, имя которых похоже на запрос (например, Di Caprio найдет Leonardo DiCaprio).
+// @Description Результаты упорядочены по убыванию степени сходства, которая возвращается в поле similarity.
+// @Tags /api/actors/search
+// @Produce json
+// @Param name query string true "Имя актера или его часть"
+// @Param limit query int false "Максимальное количество актеров (1-100, по умолчанию 20)"
+// @Success 200 {array} model.ActorSearchHit
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/actors/search [get]
+// @Security ApiKeyAuth
+func (h *Handler) searchActors(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		newErrorResponse(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	page, err := parsePagination(r)
+	if err != nil {
+		newBadRequestResponse(w, err)
+		return
+	}
+
+	if page.Offset != 0 || page.After != nil {
+		newErrorResponse(w, http.StatusBadRequest, "offset and after are not supported by actor search")
+		return
+	}
+
+	actors, err := h.services.Actor.SearchActors(name, page.Limit)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to search actors")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":          userID,
+		"name":             name,
+		"num_actors_found": len(actors),
+	}).Info("Actors search successful")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(actors)
+}
+
 // createActor создает актера.
 //
 // @Summary Создать актера.
@@ -55,34 +126,27 @@ func (h *Handler) getAllActors(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param actor body model.InputActor true "Данные нового актера"
-// @Success 201 {string} string "Актер успешно создан"
+// @Success 201 {object} model.ActorWithMovies "Созданный актер"
+// @Header 201 {string} Location "Адрес созданного актера, например /api/actor/1"
+// @Header 201 {string} ETag "Версия созданного актера"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 409 {object} ErrorResponse "Такой актер уже существует"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/actor [post]
 // @Security ApiKeyAuth
 func (h *Handler) CreateActor(w http.ResponseWriter, r *http.Request) {
-	role, err := getUserRole(r)
-	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "failed to get user role")
-		return
-	}
-
-	if role != "admin" {
-		newErrorResponse(w, http.StatusForbidden, "only admin can create actors")
-		return
-	}
-
 	var input model.InputActor
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		newErrorResponse(w, http.StatusBadRequest, errors.New("Invalid input").Error())
 		return
 	}
 
-	actorID, err := h.services.Actor.CreateActor(input)
+	actor, err := h.services.Actor.CreateActor(input)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, errors.New("Actor created unsuccessfully").Error())
+		newServiceErrorResponse(w, err, "Actor created unsuccessfully")
 		return
 	}
 
@@ -90,13 +154,13 @@ func (h *Handler) CreateActor(w http.ResponseWriter, r *http.Request) {
 
 	logEntry := logrus.WithFields(logrus.Fields{
 		"user_id":  userID,
-		"actor_id": actorID,
+		"actor_id": actor.ID,
 	})
 
 	logEntry.Info("Actor created successfully")
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("actor created successfully"))
+	w.Header().Set("ETag", etag(actor.Version))
+	newCreatedResponse(w, fmt.Sprintf("/api/actor/%d", actor.ID), actor)
 }
 
 // deleteActor удаляет актера.
@@ -105,42 +169,31 @@ func (h *Handler) CreateActor(w http.ResponseWriter, r *http.Request) {
 // @Description Удаляет актера по его идентификатору.
 // @Tags /api/actor/{id}
 // @Param id path int true "Идентификатор актера"
-// @Success 200 {string} string "Актер успешно удален"
+// @Param If-Match header string false "ETag актера, полученный при его чтении: изменение выполняется, только если актер с тех пор не изменился"
+// @Success 200 {object} MessageResponse "Актер успешно удален"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 412 {object} ErrorResponse "Актер изменился или был удален после чтения, ETag не соответствует заголовку If-Match"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/actor/{id} [delete]
 // @Security ApiKeyAuth
 func (h *Handler) deleteActor(w http.ResponseWriter, r *http.Request) {
-	role, err := getUserRole(r)
+	actorID, err := pathInt(r, "id")
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "failed to get user role")
-		return
-	}
-
-	if role != "admin" {
-		newErrorResponse(w, http.StatusForbidden, "only admin can delete actors")
-		return
-	}
-
-	path := strings.TrimSuffix(r.URL.Path, "/")
-	parts := strings.Split(path, "/")
-
-	if len(parts) != 3 || parts[1] != "actor" {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		newErrorResponse(w, http.StatusBadRequest, "Invalid actor ID")
 		return
 	}
 
-	actorID, err := strconv.Atoi(parts[2])
+	ifMatch, err := ifMatchVersions(r)
 	if err != nil {
-		newErrorResponse(w, http.StatusBadRequest, "Invalid actor ID")
+		newServiceErrorResponse(w, err, "Failed to delete actor")
 		return
 	}
 
-	err = h.services.Actor.Delete(actorID)
+	err = h.services.Actor.Delete(actorID, ifMatch)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "Failed to delete actor")
+		newServiceErrorResponse(w, err, "Failed to delete actor")
 		return
 	}
 
@@ -153,8 +206,7 @@ func (h *Handler) deleteActor(w http.ResponseWriter, r *http.Request) {
 
 	logEntry.Info("Actor deleted successfully")
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("actor deleted successfully"))
+	newMessageResponse(w, http.StatusOK, "actor deleted successfully")
 }
 
 // updateActor обновляет информацию об актере.
@@ -166,35 +218,21 @@ func (h *Handler) deleteActor(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Идентификатор актера"
 // @Param actor body model.InputActor true "Новые данные актера"
-// @Success 200 {string} string "Информация об актере успешно обновлена"
+// @Param If-Match header string false "ETag актера, полученный при его чтении: изменение выполняется, только если актер с тех пор не изменился"
+// @Success 200 {object} model.ActorWithMovies "Обновленный актер"
+// @Header 200 {string} ETag "Версия обновленного актера"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Актер не найден"
+// @Failure 412 {object} ErrorResponse "Актер изменился после чтения, ETag не соответствует заголовку If-Match"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/actor/{id} [put]
 // @Security ApiKeyAuth
 func (h *Handler) updateActor(w http.ResponseWriter, r *http.Request) {
-	role, err := getUserRole(r)
+	actorID, err := pathInt(r, "id")
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "failed to get user role")
-		return
-	}
-
-	if role != "admin" {
-		newErrorResponse(w, http.StatusForbidden, "only admin can update actors")
-		return
-	}
-
-	path := strings.TrimSuffix(r.URL.Path, "/")
-	parts := strings.Split(path, "/")
-
-	if len(parts) != 3 || parts[1] != "actor" {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	actorID, err := strconv.Atoi(parts[2])
-	if err != nil || actorID < 0 {
 		newErrorResponse(w, http.StatusBadRequest, "Invalid actor ID")
 		return
 	}
@@ -205,10 +243,15 @@ func (h *Handler) updateActor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.services.Actor.Update(actorID, input)
+	ifMatch, err := ifMatchVersions(r)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to update actor")
+		return
+	}
+
+	actor, err := h.services.Actor.Update(actorID, input, ifMatch)
 	if err != nil {
-		fmt.Println(err.Error())
-		newErrorResponse(w, http.StatusInternalServerError, "Failed to update actor")
+		newServiceErrorResponse(w, err, "Failed to update actor")
 		return
 	}
 
@@ -221,8 +264,9 @@ func (h *Handler) updateActor(w http.ResponseWriter, r *http.Request) {
 
 	logEntry.Info("Actor updates successfully")
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("actor updated successfully"))
+	w.Header().Set("ETag", etag(actor.Version))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(actor)
 }
 
 // getActor получает информацию об актере.
@@ -232,23 +276,19 @@ func (h *Handler) updateActor(w http.ResponseWriter, r *http.Request) {
 // @Tags /api/actor/{id}
 // @Produce json
 // @Param id path int true "Идентификатор актера"
+// @Param If-None-Match header string false "ETag имеющейся у клиента копии актера"
 // @Success 200 {object} model.ActorWithMovies
+// @Header 200 {string} ETag "Версия актера"
+// @Success 304 "Актер не изменился, у клиента актуальная копия"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 404 {object} ErrorResponse "Актер не найден"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/actor/{id} [get]
 // @Security ApiKeyAuth
 func (h *Handler) getActor(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimSuffix(r.URL.Path, "/")
-	parts := strings.Split(path, "/")
-
-	if len(parts) != 3 || parts[1] != "actor" {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	actorID, err := strconv.Atoi(parts[2])
+	actorID, err := pathInt(r, "id")
 	if err != nil {
 		newErrorResponse(w, http.StatusBadRequest, "Invalid actor ID")
 		return
@@ -256,7 +296,7 @@ func (h *Handler) getActor(w http.ResponseWriter, r *http.Request) {
 
 	actor, err := h.services.Actor.Get(actorID)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, errors.New("Failed to get actor").Error())
+		newServiceErrorResponse(w, err, "Failed to get actor")
 		return
 	}
 
@@ -265,6 +305,10 @@ func (h *Handler) getActor(w http.ResponseWriter, r *http.Request) {
 		"actor":    actor,
 	}).Info("Actor information successfully retrieved")
 
+	if conditionalGet(w, r, actor.Version) {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 
 	err = json.NewEncoder(w).Encode(actor)
@@ -273,16 +317,3 @@ func (h *Handler) getActor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
-
-func (h *Handler) actorHandle(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodDelete:
-		h.deleteActor(w, r)
-	case http.MethodPut:
-		h.updateActor(w, r)
-	case http.MethodGet:
-		h.getActor(w, r)
-	default:
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
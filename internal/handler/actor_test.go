@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -15,6 +14,8 @@ import (
 	mock_service "github.com/avealice/filmhub/internal/service/mocks"
 
 	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 )
 
 func TestHandler_getAllActors(t *testing.T) {
@@ -42,9 +43,14 @@ func TestHandler_getAllActors(t *testing.T) {
 		}
 	}
 
-	mockActorService.EXPECT().GetAllActors().Return(expectedActorsWithMovies, nil)
+	nextCursor := model.Cursor{ID: 2}.Encode()
+	mockActorService.EXPECT().GetAllActors(model.Sort{{Field: "name"}}, model.Pagination{Limit: 2, Offset: 4}).Return(model.ActorsPage{
+		Actors:     expectedActorsWithMovies,
+		NextCursor: nextCursor,
+		Total:      10,
+	}, nil)
 
-	req := httptest.NewRequest("GET", "/api/actors", nil)
+	req := httptest.NewRequest("GET", "/api/actors?limit=2&offset=4", nil)
 	w := httptest.NewRecorder()
 
 	handler.getAllActors(w, req)
@@ -53,11 +59,16 @@ func TestHandler_getAllActors(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 
-	var responseActors []model.ActorWithMovies
-	if err := json.NewDecoder(w.Body).Decode(&responseActors); err != nil {
+	var response model.ActorsPage
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
 		t.Errorf("Error decoding response body: %v", err)
 	}
 
+	if response.NextCursor != nextCursor || response.Total != 10 {
+		t.Errorf("Expected next cursor %q and total %d, got %q and %d", nextCursor, 10, response.NextCursor, response.Total)
+	}
+
+	responseActors := response.Actors
 	if len(responseActors) != len(expectedActors) {
 		t.Errorf("Expected %d actors, got %d", len(expectedActors), len(responseActors))
 	}
@@ -78,11 +89,15 @@ func TestHandler_getAllActors_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest("POST", "/api/actors", nil)
 	w := httptest.NewRecorder()
 
-	handler.getAllActors(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
 	}
+
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Errorf("Expected Allow header %q, got %q", "GET, HEAD", allow)
+	}
 }
 
 func TestHandler_getAllActors_InternalServerError(t *testing.T) {
@@ -97,7 +112,7 @@ func TestHandler_getAllActors_InternalServerError(t *testing.T) {
 		},
 	}
 
-	mockActorService.EXPECT().GetAllActors().Return(nil, errors.New("database error"))
+	mockActorService.EXPECT().GetAllActors(gomock.Any(), gomock.Any()).Return(model.ActorsPage{}, errors.New("database error"))
 
 	req := httptest.NewRequest("GET", "/api/actors", nil)
 	w := httptest.NewRecorder()
@@ -109,12 +124,176 @@ func TestHandler_getAllActors_InternalServerError(t *testing.T) {
 	}
 }
 
+func TestHandler_getAllActors_Sorting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockActorService := mock_service.NewMockActor(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Actor: mockActorService,
+		},
+	}
+
+	expectedSort := model.Sort{{Field: "gender", Desc: true}, {Field: "birth_date"}}
+	mockActorService.EXPECT().GetAllActors(expectedSort, gomock.Any()).Return(model.ActorsPage{}, nil)
+
+	req := httptest.NewRequest("GET", "/api/actors?sort_by=-gender,birth_date", nil)
+	w := httptest.NewRecorder()
+
+	handler.getAllActors(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestHandler_getAllActors_UnknownSortField(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockActorService := mock_service.NewMockActor(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Actor: mockActorService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/actors?sort_by=rating", nil)
+	w := httptest.NewRecorder()
+
+	handler.getAllActors(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Error decoding response body: %v", err)
+	}
+
+	if strings.Join(response.Allowed, ",") != strings.Join(model.ActorSortFields, ",") {
+		t.Errorf("Expected allowed values %v, got %v", model.ActorSortFields, response.Allowed)
+	}
+}
+
+func TestHandler_searchActors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockActorService := mock_service.NewMockActor(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Actor: mockActorService,
+		},
+	}
+
+	expectedHits := []model.ActorSearchHit{
+		{
+			ActorWithMovies: model.ActorWithMovies{ID: 3, Name: "Leonardo DiCaprio", Movies: []model.Movie{}},
+			Similarity:      0.72,
+		},
+	}
+	mockActorService.EXPECT().SearchActors("Leonardo DeCaprio", 5).Return(expectedHits, nil)
+
+	req := httptest.NewRequest("GET", "/api/actors/search?name=Leonardo+DeCaprio&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.searchActors(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response []model.ActorSearchHit
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Error decoding response body: %v", err)
+	}
+
+	if len(response) != 1 || response[0].Name != "Leonardo DiCaprio" || response[0].Similarity != 0.72 {
+		t.Errorf("Expected hits %+v, got %+v", expectedHits, response)
+	}
+}
+
+func TestHandler_searchActors_InternalServerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	mockActorService := mock_service.NewMockActor(ctrl)
+	mockActorService.EXPECT().SearchActors("DiCaprio", defaultPageLimit).Return(nil, errors.New("database error"))
+
+	handler := &Handler{
+		services: &service.Service{
+			Actor: mockActorService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/actors/search?name=DiCaprio", nil)
+	w := httptest.NewRecorder()
+
+	handler.searchActors(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "database error") {
+		t.Errorf("Expected the internal error not to be disclosed, got %s", w.Body.String())
+	}
+
+	// The underlying error is logged once instead of being returned to the client.
+	if len(hook.Entries) != 1 || hook.LastEntry().Level != logrus.ErrorLevel || hook.LastEntry().Data[logrus.ErrorKey] == nil {
+		t.Errorf("Expected a single error log entry with the underlying error, got %+v", hook.Entries)
+	}
+}
+
+func TestHandler_searchActors_BadRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "missing name", query: ""},
+		{name: "blank name", query: "name=+"},
+		{name: "invalid limit", query: "name=DiCaprio&limit=0"},
+		{name: "offset", query: "name=DiCaprio&offset=10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := &Handler{
+				services: &service.Service{
+					Actor: mock_service.NewMockActor(ctrl),
+				},
+			}
+
+			req := httptest.NewRequest("GET", "/api/actors/search?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.searchActors(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
 func TestHandler_CreateActor_Successful(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockActorService := mock_service.NewMockActor(ctrl)
-	mockActorService.EXPECT().CreateActor(gomock.Any()).Return(0, nil)
+	created := model.ActorWithMovies{ID: 4, Name: "Test Actor", Gender: "male", BirthDate: "2000-01-01"}
+	mockActorService.EXPECT().CreateActor(gomock.Any()).Return(created, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -124,8 +303,7 @@ func TestHandler_CreateActor_Successful(t *testing.T) {
 
 	reqBody := `{"name":"Test Actor", "gender":"male", "birth_date":"2000-01-01"}`
 	req := httptest.NewRequest("POST", "/actor", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.CreateActor(w, req)
@@ -134,9 +312,17 @@ func TestHandler_CreateActor_Successful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
 	}
 
-	expectedResponse := "actor created successfully"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	if location := w.Header().Get("Location"); location != "/api/actor/4" {
+		t.Errorf("Expected Location %q, got %q", "/api/actor/4", location)
+	}
+
+	var response model.ActorWithMovies
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response body: %v", err)
+	}
+
+	if response.ID != created.ID || response.Name != created.Name {
+		t.Errorf("Expected created actor %+v, got %+v", created, response)
 	}
 }
 
@@ -145,7 +331,7 @@ func TestHandler_CreateActor_Unsuccessful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockActorService := mock_service.NewMockActor(ctrl)
-	mockActorService.EXPECT().CreateActor(gomock.Any()).Return(-1, errors.New("Failed to create actor"))
+	mockActorService.EXPECT().CreateActor(gomock.Any()).Return(model.ActorWithMovies{}, errors.New("Failed to create actor"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -155,8 +341,7 @@ func TestHandler_CreateActor_Unsuccessful(t *testing.T) {
 
 	reqBody := `{"name":"Test Actor", "gender":"male", "birth_date":"2000-01-01"}`
 	req := httptest.NewRequest("POST", "/actor", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.CreateActor(w, req)
@@ -165,10 +350,7 @@ func TestHandler_CreateActor_Unsuccessful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Actor created unsuccessfully\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "internal_error", "Actor created unsuccessfully")
 }
 
 func TestHandler_CreateActor_Unauthorized(t *testing.T) {
@@ -176,20 +358,16 @@ func TestHandler_CreateActor_Unauthorized(t *testing.T) {
 	handler := &Handler{}
 
 	req := httptest.NewRequest("POST", "/actor", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "user")
-	req = req.WithContext(ctx)
+	req = withIdentity(req, "user")
 	w := httptest.NewRecorder()
 
-	handler.CreateActor(w, req)
+	handler.requirePermission(model.PermissionActorWrite, http.HandlerFunc(handler.CreateActor)).ServeHTTP(w, req)
 
 	if w.Code != http.StatusForbidden {
 		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"only admin can create actors\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "forbidden", "permission actor:write is required")
 }
 
 func TestHandler_CreateActor_BadRequest(t *testing.T) {
@@ -197,8 +375,7 @@ func TestHandler_CreateActor_BadRequest(t *testing.T) {
 	handler := &Handler{}
 
 	req := httptest.NewRequest("POST", "/actor", strings.NewReader("invalid json"))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.CreateActor(w, req)
@@ -207,10 +384,7 @@ func TestHandler_CreateActor_BadRequest(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Invalid input\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "bad_request", "Invalid input")
 }
 
 func TestHandler_deleteActor_Successful(t *testing.T) {
@@ -218,7 +392,7 @@ func TestHandler_deleteActor_Successful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockActorService := mock_service.NewMockActor(ctrl)
-	mockActorService.EXPECT().Delete(1).Return(nil)
+	mockActorService.EXPECT().Delete(1, gomock.Nil()).Return(nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -226,9 +400,9 @@ func TestHandler_deleteActor_Successful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/actor/1", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/actor/1", nil)
+	req.SetPathValue("id", "1")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.deleteActor(w, req)
@@ -237,7 +411,7 @@ func TestHandler_deleteActor_Successful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
 	}
 
-	expectedResponse := "actor deleted successfully"
+	expectedResponse := `{"message":"actor deleted successfully"}`
 	if w.Body.String() != expectedResponse {
 		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
 	}
@@ -248,7 +422,7 @@ func TestHandler_deleteActor_Unsuccessful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockActorService := mock_service.NewMockActor(ctrl)
-	mockActorService.EXPECT().Delete(1).Return(errors.New("Failed to delete actor"))
+	mockActorService.EXPECT().Delete(1, gomock.Nil()).Return(errors.New("Failed to delete actor"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -256,9 +430,9 @@ func TestHandler_deleteActor_Unsuccessful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/actor/1", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/actor/1", nil)
+	req.SetPathValue("id", "1")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.deleteActor(w, req)
@@ -267,10 +441,7 @@ func TestHandler_deleteActor_Unsuccessful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Failed to delete actor\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "internal_error", "Failed to delete actor")
 }
 
 func TestHandler_deleteActor_InvalidID(t *testing.T) {
@@ -285,21 +456,17 @@ func TestHandler_deleteActor_InvalidID(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/actor/notanumber", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/actor/notanumber", nil)
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
-	handler.actorHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Invalid actor ID\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "bad_request", "Invalid actor ID")
 }
 
 func TestHandler_updateActor_Successful(t *testing.T) {
@@ -307,7 +474,8 @@ func TestHandler_updateActor_Successful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockActorService := mock_service.NewMockActor(ctrl)
-	mockActorService.EXPECT().Update(1, gomock.Any()).Return(nil)
+	updated := model.ActorWithMovies{ID: 1, Name: "Updated Actor", Gender: "male", BirthDate: "1990-01-01", Version: 3}
+	mockActorService.EXPECT().Update(1, gomock.Any(), gomock.Nil()).Return(updated, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -316,20 +484,27 @@ func TestHandler_updateActor_Successful(t *testing.T) {
 	}
 
 	actorData := `{"name":"Updated Actor","gender":"male","birth_date":"1990-01-01","movies":[]}`
-	req := httptest.NewRequest("PUT", "/actor/1", strings.NewReader(actorData))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("PUT", "/api/actor/1", strings.NewReader(actorData))
+	req.SetPathValue("id", "1")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.updateActor(w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 
-	expectedResponse := "actor updated successfully"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	if etag := w.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf("Expected ETag of the updated actor %q, got %q", `"3"`, etag)
+	}
+
+	var actor model.ActorWithMovies
+	if err := json.NewDecoder(w.Body).Decode(&actor); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if actor.ID != 1 || actor.Name != "Updated Actor" {
+		t.Errorf("Expected the updated actor %+v, got %+v", updated, actor)
 	}
 }
 
@@ -338,7 +513,7 @@ func TestHandler_updateActor_Unsuccessful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockActorService := mock_service.NewMockActor(ctrl)
-	mockActorService.EXPECT().Update(1, gomock.Any()).Return(errors.New("Failed to update actor"))
+	mockActorService.EXPECT().Update(1, gomock.Any(), gomock.Nil()).Return(model.ActorWithMovies{}, errors.New("Failed to update actor"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -347,21 +522,17 @@ func TestHandler_updateActor_Unsuccessful(t *testing.T) {
 	}
 
 	actorData := `{"name":"Updated Actor","gender":"male","birth_date":"1990-01-01","movies":[]}`
-	req := httptest.NewRequest("PUT", "/actor/1", strings.NewReader(actorData))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("PUT", "/api/actor/1", strings.NewReader(actorData))
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
-	handler.actorHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Failed to update actor\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "internal_error", "Failed to update actor")
 }
 
 func TestHandler_updateActor_InvalidID(t *testing.T) {
@@ -377,9 +548,9 @@ func TestHandler_updateActor_InvalidID(t *testing.T) {
 	}
 
 	actorData := `{"name":"Updated Actor","gender":"male","birth_date":"1990-01-01","movies":[]}`
-	req := httptest.NewRequest("PUT", "/actor/notanumber", strings.NewReader(actorData))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("PUT", "/api/actor/notanumber", strings.NewReader(actorData))
+	req.SetPathValue("id", "notanumber")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.updateActor(w, req)
@@ -388,10 +559,7 @@ func TestHandler_updateActor_InvalidID(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Invalid actor ID\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "bad_request", "Invalid actor ID")
 }
 func TestHandler_getActor_Successful(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -412,10 +580,11 @@ func TestHandler_getActor_Successful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("GET", "/actor/1", nil)
+	req := httptest.NewRequest("GET", "/api/actor/1", nil)
+	req = withRouteIdentity(ctrl, handler, req, "user")
 	w := httptest.NewRecorder()
 
-	handler.actorHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
@@ -443,7 +612,8 @@ func TestHandler_getActor_Unsuccessful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("GET", "/actor/1", nil)
+	req := httptest.NewRequest("GET", "/api/actor/1", nil)
+	req.SetPathValue("id", "1")
 	w := httptest.NewRecorder()
 
 	handler.getActor(w, req)
@@ -452,10 +622,7 @@ func TestHandler_getActor_Unsuccessful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Failed to get actor\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "internal_error", "Failed to get actor")
 }
 
 func TestHandler_getActor_InvalidID(t *testing.T) {
@@ -470,17 +637,15 @@ func TestHandler_getActor_InvalidID(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("GET", "/actor/notanumber", nil)
+	req := httptest.NewRequest("GET", "/api/actor/notanumber", nil)
+	req = withRouteIdentity(ctrl, handler, req, "user")
 	w := httptest.NewRecorder()
 
-	handler.actorHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Invalid actor ID\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "bad_request", "Invalid actor ID")
 }
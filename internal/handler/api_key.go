@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+// createAPIKey создает новый ключ API.
+//
+// @Summary Создать ключ API
+// @Description Создает долгоживущий ключ API для доступа сервисов к API. Ключ действует от имени создавшего его пользователя
+// @Description и может получить только те разрешения, которые есть у этого пользователя. Сам ключ возвращается только в ответе на этот запрос,
+// @Description в базе данных хранится лишь его хеш. Требуется разрешение user:manage.
+// @Tags /api/admin/api-keys
+// @Accept json
+// @Produce json
+// @Param key body model.APIKeyInput true "Данные ключа"
+// @Success 201 {object} model.CreatedAPIKey
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 422 {object} ErrorResponse "Некорректные данные ключа, например недопустимые разрешения"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/api-keys [post]
+// @Security ApiKeyAuth
+func (h *Handler) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	identity, err := getIdentity(r)
+	if err != nil {
+		newErrorResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var input model.APIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	key, err := h.services.APIKey.CreateAPIKey(identity, input)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to create API key")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":     identity.UserID,
+		"api_key_id":  key.ID,
+		"permissions": key.Permissions,
+	}).Info("API key created successfully")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+// getAllAPIKeys возвращает список ключей API.
+//
+// @Summary Получить все ключи API
+// @Description Возвращает все ключи API, включая отозванные и истекшие. Сами ключи не возвращаются, только их начало (поле prefix).
+// @Description Требуется разрешение user:manage.
+// @Tags /api/admin/api-keys
+// @Produce json
+// @Success 200 {array} model.APIKey
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/api-keys [get]
+// @Security ApiKeyAuth
+func (h *Handler) getAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.services.APIKey.GetAllAPIKeys()
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to get API keys")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// revokeAPIKey отзывает ключ API.
+//
+// @Summary Отозвать ключ API
+// @Description Отзывает ключ API, после чего запросы с ним отклоняются. Требуется разрешение user:manage.
+// @Tags /api/admin/api-keys/{id}
+// @Param id path int true "Идентификатор ключа"
+// @Success 204 "Ключ отозван"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Ключ не найден или уже отозван"
+// @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/api-keys/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := pathInt(r, "id")
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.services.APIKey.RevokeAPIKey(keyID); err != nil {
+		newServiceErrorResponse(w, err, "Failed to revoke API key")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"api_key_id": keyID,
+	}).Info("API key revoked successfully")
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+
+	mock_service "github.com/avealice/filmhub/internal/service/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_createAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_service.NewMockAPIKey(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			APIKey: mockAPIKeyService,
+		},
+	}
+
+	input := model.APIKeyInput{Name: "ingestion", Permissions: []string{model.PermissionMovieWrite}}
+	mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), input).Return(model.CreatedAPIKey{
+		APIKey: model.APIKey{ID: 3, Name: "ingestion", Prefix: "fh_abcdefgh", Permissions: input.Permissions},
+		Key:    "fh_abcdefgh_secret",
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/api-keys", strings.NewReader(`{"name": "ingestion", "permissions": ["movie:write"]}`))
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var response model.CreatedAPIKey
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Error decoding response body: %v", err)
+	}
+
+	if response.ID != 3 || response.Key != "fh_abcdefgh_secret" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestHandler_createAPIKey_InvalidInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_service.NewMockAPIKey(ctrl)
+	mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(model.CreatedAPIKey{}, service.ErrInvalidAPIKeyInput)
+
+	handler := &Handler{
+		services: &service.Service{
+			APIKey: mockAPIKeyService,
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/api/admin/api-keys", strings.NewReader(`{"name": "ingestion", "permissions": ["movie:delete"]}`))
+	req = withIdentity(req, "editor", model.PermissionMovieWrite)
+	w := httptest.NewRecorder()
+
+	handler.createAPIKey(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+
+	if response := decodeProblem(t, w); response.Code != "invalid_api_key_input" {
+		t.Errorf("Expected error code %q, got %q", "invalid_api_key_input", response.Code)
+	}
+}
+
+func TestHandler_revokeAPIKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		mockBehavior func(s *mock_service.MockAPIKey)
+		expectedCode int
+	}{
+		{
+			name: "revoked",
+			path: "/api/admin/api-keys/3",
+			mockBehavior: func(s *mock_service.MockAPIKey) {
+				s.EXPECT().RevokeAPIKey(3).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			path: "/api/admin/api-keys/3",
+			mockBehavior: func(s *mock_service.MockAPIKey) {
+				s.EXPECT().RevokeAPIKey(3).Return(service.ErrAPIKeyNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "invalid id",
+			path:         "/api/admin/api-keys/abc",
+			mockBehavior: func(s *mock_service.MockAPIKey) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAPIKeyService := mock_service.NewMockAPIKey(ctrl)
+			tt.mockBehavior(mockAPIKeyService)
+
+			handler := &Handler{
+				services: &service.Service{
+					APIKey: mockAPIKeyService,
+				},
+			}
+
+			req := httptest.NewRequest("DELETE", tt.path, nil)
+			req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+			w := httptest.NewRecorder()
+
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
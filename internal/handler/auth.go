@@ -3,9 +3,13 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,37 +18,72 @@ type SignInInput struct {
 	Password string `json:"password"`
 }
 
+// SignUpInput содержит данные для регистрации нового пользователя.
+type SignUpInput struct {
+	Username string `json:"username"` // Имя пользователя: от 3 до 32 латинских букв, цифр, точек, дефисов и подчеркиваний
+	Password string `json:"password"` // Пароль, соответствующий парольной политике
+}
+
 type TokenResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`         // Токен доступа
+	RefreshToken string `json:"refresh_token"` // Одноразовый токен для получения новой пары токенов
+	ExpiresIn    int    `json:"expires_in"`    // Время жизни токена доступа в секундах
+}
+
+type ForgotPasswordInput struct {
+	Username string `json:"username"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token"`        // Одноразовый токен сброса пароля
+	NewPassword string `json:"new_password"` // Новый пароль, соответствующий парольной политике
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func newTokenResponse(tokens model.Tokens) TokenResponse {
+	return TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
 }
 
 // signIn авторизует пользователя и возвращает токен доступа.
 //
 // @Summary Авторизация пользователя
-// @Description Авторизует пользователя с заданными учетными данными и возвращает токен доступа
+// @Description Авторизует пользователя с заданными учетными данными и возвращает короткоживущий токен доступа
+// @Description и токен обновления, по которому можно получить новую пару токенов через /auth/refresh.
+// @Description После нескольких неудачных попыток входа для имени пользователя или IP-адреса вход временно блокируется.
 // @Tags /auth/
 // @Accept json
 // @Produce json
 // @Param request body SignInInput true "Данные для входа"
-// @Success 200 {object} TokenResponse "Токен доступа"
+// @Success 200 {object} TokenResponse "Токен доступа и токен обновления"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Неверное имя пользователя или пароль"
+// @Failure 403 {object} ErrorResponse "Учетная запись заблокирована"
+// @Failure 429 {object} ErrorResponse "Слишком много неудачных попыток входа, время ожидания в секундах указано в заголовке Retry-After"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /auth/sign-in [post]
 func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	var input SignInInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		newErrorResponse(w, http.StatusBadRequest, errors.New("Invalid input").Error())
 		return
 	}
 
-	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+	tokens, err := h.services.Authorization.GenerateToken(input.Username, input.Password, clientIP(r))
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, errors.New("User signin in unsuccessfully").Error())
+		var throttled *service.ThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			newThrottledErrorResponse(w, throttled)
+		default:
+			newServiceErrorResponse(w, err, "User signin in unsuccessfully")
+		}
 		return
 	}
 
@@ -52,7 +91,7 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 
 	logrus.WithField("user_id", userID).Info("User signed in successfully")
 
-	response := TokenResponse{Token: token}
+	response := newTokenResponse(tokens)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		newErrorResponse(w, http.StatusInternalServerError, err.Error())
@@ -60,42 +99,132 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-type UserIDResponse struct {
-	ID int `json:"id"`
+// newThrottledErrorResponse отправляет клиенту ответ 429 с временем ожидания в заголовке Retry-After.
+func newThrottledErrorResponse(w http.ResponseWriter, throttled *service.ThrottledError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	writeErrorResponse(w, ErrorResponse{Status: http.StatusTooManyRequests, Detail: throttled.Error(), Code: codeTooManyAttempts})
 }
 
-// signUp регистрирует нового пользователя.
+// clientIP возвращает IP-адрес клиента, отправившего запрос.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// refresh выдает новую пару токенов в обмен на токен обновления.
 //
-// @Summary Регистрация пользователя
-// @Description Регистрирует нового пользователя с заданными данными
+// @Summary Обновление токенов
+// @Description Обменивает токен обновления на новую пару токенов. Каждый токен обновления можно использовать только один раз:
+// @Description повторное использование уже обмененного токена отзывает все токены, полученные при том же входе.
 // @Tags /auth/
 // @Accept json
 // @Produce json
-// @Param request body model.User true "Данные нового пользователя"
-// @Success 201 {object} UserIDResponse "ID нового пользователя"
+// @Param request body RefreshInput true "Токен обновления"
+// @Success 200 {object} TokenResponse "Новая пара токенов"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Недействительный токен обновления"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /auth/sign-up [post]
-func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+// @Router /auth/refresh [post]
+func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
+	var input RefreshInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.RefreshToken == "" {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
 		return
 	}
 
-	var input model.User
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		newErrorResponse(w, http.StatusBadRequest, errors.New("Invalid input").Error())
+	tokens, err := h.services.Authorization.RefreshTokens(input.RefreshToken)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to refresh tokens")
 		return
 	}
 
-	if len(input.Password) == 0 || len(input.Username) == 0 {
-		newErrorResponse(w, http.StatusBadRequest, errors.New("Invalid input").Error())
+	logrus.Info("Tokens refreshed successfully")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTokenResponse(tokens))
+}
+
+// logout завершает сеанс пользователя.
+//
+// @Summary Выход из системы
+// @Description Отзывает текущий токен доступа и, если он передан, токен обновления вместе со всеми токенами, полученными при том же входе.
+// @Tags /auth/
+// @Accept json
+// @Param request body RefreshInput false "Токен обновления"
+// @Success 204 "Сеанс завершен"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные, запрос аутентифицирован ключом API"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/logout [post]
+// @Security ApiKeyAuth
+func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
+	identity, err := getIdentity(r)
+	if err != nil {
+		newErrorResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if identity.APIKeyID != 0 {
+		newErrorResponse(w, http.StatusBadRequest, "API keys cannot log out, revoke the key instead")
+		return
+	}
+
+	var input RefreshInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+			return
+		}
+	}
+
+	if err := h.services.Authorization.Logout(identity, input.RefreshToken); err != nil {
+		newServiceErrorResponse(w, err, "Failed to log out")
+		return
+	}
+
+	logrus.WithField("user_id", identity.UserID).Info("User logged out successfully")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type UserIDResponse struct {
+	ID int `json:"id"`
+}
+
+// signUp регистрирует нового пользователя.
+//
+// @Summary Регистрация пользователя
+// @Description Регистрирует нового пользователя с заданными данными. Имя пользователя должно состоять из 3-32 латинских букв, цифр,
+// @Description точек, дефисов и подчеркиваний, пароль - соответствовать парольной политике. Все ошибки в данных возвращаются в поле errors.
+// @Tags /auth/
+// @Accept json
+// @Produce json
+// @Param request body SignUpInput true "Данные нового пользователя"
+// @Success 201 {object} UserIDResponse "ID нового пользователя"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 409 {object} ErrorResponse "Имя пользователя уже занято"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/sign-up [post]
+func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
+	var input SignUpInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input: "+err.Error())
 		return
 	}
 
-	id, err := h.services.Authorization.CreateUser(input)
+	id, err := h.services.Authorization.CreateUser(model.User{
+		Username: input.Username,
+		Password: input.Password,
+	})
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, errors.New("User signed up unsuccessfully").Error())
+		newServiceErrorResponse(w, err, "User signed up unsuccessfully")
 		return
 	}
 
@@ -108,3 +237,79 @@ func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// forgotPassword запрашивает сброс пароля.
+//
+// @Summary Запрос сброса пароля
+// @Description Выдает одноразовый токен сброса пароля и отправляет его пользователю. Ответ не зависит от того,
+// @Description существует ли пользователь, чтобы по нему нельзя было узнать занятые имена. Частые запросы для одного
+// @Description имени пользователя или с одного адреса игнорируются, но ответ на них тот же.
+// @Tags /auth/
+// @Accept json
+// @Param request body ForgotPasswordInput true "Имя пользователя"
+// @Success 202 "Если пользователь существует, токен отправлен"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Router /auth/forgot-password [post]
+func (h *Handler) forgotPassword(w http.ResponseWriter, r *http.Request) {
+	var input ForgotPasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Username == "" {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	// Ошибки, в том числе при отправке токена, только записываются в журнал: ответ не должен зависеть
+	// от того, существует ли пользователь.
+	if err := h.services.Authorization.RequestPasswordReset(input.Username, clientIP(r)); err != nil {
+		logrus.WithError(err).Error("Failed to request password reset")
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// resetPassword задает новый пароль по токену сброса.
+//
+// @Summary Сброс пароля
+// @Description Задает новый пароль по одноразовому токену, полученному через /auth/forgot-password.
+// @Description Все сеансы пользователя завершаются: токены обновления и выданные ранее токены доступа отзываются.
+// @Tags /auth/
+// @Accept json
+// @Param request body ResetPasswordInput true "Токен сброса и новый пароль"
+// @Success 204 "Пароль изменен"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос, недействительный токен"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, новый пароль не соответствует требованиям"
+// @Failure 403 {object} ErrorResponse "Учетная запись заблокирована"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/reset-password [post]
+func (h *Handler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var input ResetPasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Token == "" {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	err := h.services.Authorization.ResetPassword(input.Token, input.NewPassword)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to reset password")
+		return
+	}
+
+	logrus.Info("Password reset successfully")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// jwks возвращает открытые ключи, которыми можно проверить подпись токенов доступа.
+//
+// @Summary Открытые ключи для проверки токенов
+// @Description Возвращает набор открытых ключей в формате JWKS (RFC 7517). Ключ для проверки токена выбирается по заголовку kid.
+// @Description Ключи алгоритма HS256 являются общими секретами и не публикуются.
+// @Tags /.well-known/
+// @Produce json
+// @Success 200 {object} model.JSONWebKeySet "Набор открытых ключей"
+// @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) jwks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.services.Authorization.JWKS())
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/avealice/filmhub/internal/model"
 	"github.com/avealice/filmhub/internal/service"
@@ -84,16 +85,17 @@ func TestHandler_signUp_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest("GET", "/auth/sign-up", nil)
 	w := httptest.NewRecorder()
 
-	handler.signUp(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Method not allowed\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	if allow := w.Header().Get("Allow"); allow != "POST" {
+		t.Errorf("Expected Allow header %q, got %q", "POST", allow)
 	}
+
+	assertProblem(t, w, "method_not_allowed", "Method not allowed")
 }
 
 func TestHandler_signUp_InternalServerError(t *testing.T) {
@@ -126,14 +128,87 @@ func TestHandler_signUp_InternalServerError(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	var response map[string]interface{}
-	err := json.NewDecoder(w.Body).Decode(&response)
-	if err != nil {
-		t.Errorf("Error decoding response body: %v", err)
+	if response := decodeProblem(t, w); response.Detail == "" {
+		t.Error("Error detail not found in response")
 	}
+}
 
-	if _, ok := response["message"]; !ok {
-		t.Error("Error message not found in response")
+func TestHandler_signUp_UnknownField(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mock_service.NewMockAuthorization(ctrl),
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/auth/sign-up", strings.NewReader(`{"username": "test", "password": "Secret-123", "role": "admin"}`))
+	w := httptest.NewRecorder()
+
+	handler.signUp(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandler_signUp_ValidationError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	verr := &model.ValidationError{}
+	verr.Add("username", "must be 3 to 32 characters long")
+	verr.Add("password", "is too common")
+
+	mockAuthService := mock_service.NewMockAuthorization(ctrl)
+	mockAuthService.EXPECT().CreateUser(model.User{Username: "x", Password: "qwerty"}).Return(-1, verr)
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mockAuthService,
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/auth/sign-up", strings.NewReader(`{"username": "x", "password": "qwerty"}`))
+	w := httptest.NewRecorder()
+
+	handler.signUp(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+
+	response := decodeProblem(t, w)
+	if response.Code != "validation_failed" {
+		t.Errorf("Expected error code %q, got %q", "validation_failed", response.Code)
+	}
+
+	if len(response.Errors) != 2 || response.Errors[0].Field != "username" || response.Errors[1].Field != "password" {
+		t.Errorf("Expected errors of both fields, got %+v", response.Errors)
+	}
+}
+
+func TestHandler_signUp_Conflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_service.NewMockAuthorization(ctrl)
+	mockAuthService.EXPECT().CreateUser(gomock.Any()).Return(-1, service.ErrUsernameTaken)
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mockAuthService,
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/auth/sign-up", strings.NewReader(`{"username": "admin", "password": "Secret-123"}`))
+	w := httptest.NewRecorder()
+
+	handler.signUp(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, w.Code)
 	}
 }
 
@@ -151,7 +226,11 @@ func TestHandler_signIn_Success(t *testing.T) {
 
 	expectedToken := "test_token"
 
-	mockAuthService.EXPECT().GenerateToken(input.Username, input.Password).Return(expectedToken, nil)
+	mockAuthService.EXPECT().GenerateToken(input.Username, input.Password, "192.0.2.1").Return(model.Tokens{
+		AccessToken:  expectedToken,
+		RefreshToken: "refresh_token",
+		ExpiresIn:    900,
+	}, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -182,6 +261,10 @@ func TestHandler_signIn_Success(t *testing.T) {
 	if response["token"].(string) != expectedToken {
 		t.Errorf("Expected token %s, got %s", expectedToken, response["token"].(string))
 	}
+
+	if response["refresh_token"] != "refresh_token" || response["expires_in"] != 900.0 {
+		t.Errorf("Expected refresh token and expiration in response, got %v", response)
+	}
 }
 
 func TestHandler_signIn_BadRequest(t *testing.T) {
@@ -219,7 +302,7 @@ func TestHandler_signIn_InternalServerError(t *testing.T) {
 		Password: "qwerty",
 	}
 
-	mockAuthService.EXPECT().GenerateToken(input.Username, input.Password).Return("", errors.New("internal error"))
+	mockAuthService.EXPECT().GenerateToken(input.Username, input.Password, "192.0.2.1").Return(model.Tokens{}, errors.New("internal error"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -237,14 +320,55 @@ func TestHandler_signIn_InternalServerError(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	var response map[string]interface{}
-	err := json.NewDecoder(w.Body).Decode(&response)
-	if err != nil {
-		t.Errorf("Error decoding response body: %v", err)
+	if response := decodeProblem(t, w); response.Detail == "" {
+		t.Error("Error detail not found in response")
+	}
+}
+
+func TestHandler_signIn_Errors(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedCode       int
+		expectedRetryAfter string
+	}{
+		{name: "invalid credentials", err: service.ErrInvalidCredentials, expectedCode: http.StatusUnauthorized},
+		{name: "disabled user", err: service.ErrUserDisabled, expectedCode: http.StatusForbidden},
+		{
+			name:               "throttled",
+			err:                &service.ThrottledError{RetryAfter: 1500 * time.Millisecond},
+			expectedCode:       http.StatusTooManyRequests,
+			expectedRetryAfter: "2",
+		},
 	}
 
-	if _, ok := response["message"]; !ok {
-		t.Error("Error message not found in response")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mock_service.NewMockAuthorization(ctrl)
+			mockAuthService.EXPECT().GenerateToken("test", "qwerty", "192.0.2.1").Return(model.Tokens{}, tt.err)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mockAuthService,
+				},
+			}
+
+			req := httptest.NewRequest("POST", "/auth/sign-in", strings.NewReader(`{"username": "test", "password": "qwerty"}`))
+			w := httptest.NewRecorder()
+
+			handler.signIn(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if retryAfter := w.Header().Get("Retry-After"); retryAfter != tt.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.expectedRetryAfter, retryAfter)
+			}
+		})
 	}
 }
 
@@ -263,14 +387,295 @@ func TestHandler_signIn_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest("GET", "/auth/sign-in", nil)
 	w := httptest.NewRecorder()
 
-	handler.signIn(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Method not allowed\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	if allow := w.Header().Get("Allow"); allow != "POST" {
+		t.Errorf("Expected Allow header %q, got %q", "POST", allow)
+	}
+
+	assertProblem(t, w, "method_not_allowed", "Method not allowed")
+}
+
+func TestHandler_jwks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_service.NewMockAuthorization(ctrl)
+
+	expectedKeys := model.JSONWebKeySet{
+		Keys: []model.JSONWebKey{
+			{KeyType: "OKP", Use: "sig", KeyID: "ed-1", Algorithm: "EdDSA", Curve: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		},
+	}
+	mockAuthService.EXPECT().JWKS().Return(expectedKeys)
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mockAuthService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+
+	handler.jwks(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response model.JSONWebKeySet
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Error decoding response body: %v", err)
+	}
+
+	if len(response.Keys) != 1 || response.Keys[0] != expectedKeys.Keys[0] {
+		t.Errorf("Expected keys %+v, got %+v", expectedKeys, response)
+	}
+}
+
+func TestHandler_refresh(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		setup        func(m *mock_service.MockAuthorization)
+		expectedCode int
+	}{
+		{
+			name: "success",
+			body: `{"refresh_token":"old"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().RefreshTokens("old").Return(model.Tokens{AccessToken: "access", RefreshToken: "new", ExpiresIn: 900}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "reused token",
+			body: `{"refresh_token":"old"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().RefreshTokens("old").Return(model.Tokens{}, service.ErrInvalidRefreshToken)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "missing token",
+			body:         `{}`,
+			setup:        func(m *mock_service.MockAuthorization) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mock_service.NewMockAuthorization(ctrl)
+			tt.setup(mockAuthService)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mockAuthService,
+				},
+			}
+
+			req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.refresh(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if tt.expectedCode == http.StatusOK {
+				var response TokenResponse
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Errorf("Error decoding response body: %v", err)
+				}
+
+				if response.Token != "access" || response.RefreshToken != "new" {
+					t.Errorf("Unexpected response %+v", response)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_logout(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		refreshToken string
+	}{
+		{name: "with refresh token", body: `{"refresh_token":"refresh"}`, refreshToken: "refresh"},
+		{name: "without body", body: "", refreshToken: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			identity := model.Identity{UserID: 1, Role: "user", TokenID: "jti"}
+
+			mockAuthService := mock_service.NewMockAuthorization(ctrl)
+			mockAuthService.EXPECT().ParseToken("access").Return(identity, nil)
+			mockAuthService.EXPECT().Logout(identity, tt.refreshToken).Return(nil)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mockAuthService,
+				},
+			}
+
+			req := httptest.NewRequest("POST", "/auth/logout", strings.NewReader(tt.body))
+			req.Header.Set(authorizationHeader, "Bearer access")
+			w := httptest.NewRecorder()
+
+			handler.userIdentity(http.HandlerFunc(handler.logout)).ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_forgotPassword(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		setup        func(m *mock_service.MockAuthorization)
+		expectedCode int
+	}{
+		{
+			name: "success",
+			body: `{"username":"test"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().RequestPasswordReset("test", "192.0.2.1").Return(nil)
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "missing username",
+			body:         `{}`,
+			setup:        func(m *mock_service.MockAuthorization) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "notifier failure",
+			body: `{"username":"test"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().RequestPasswordReset("test", "192.0.2.1").Return(errors.New("notifier error"))
+			},
+			// The failure is only logged, so that it does not reveal that the user exists.
+			expectedCode: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mock_service.NewMockAuthorization(ctrl)
+			tt.setup(mockAuthService)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mockAuthService,
+				},
+			}
+
+			req := httptest.NewRequest("POST", "/auth/forgot-password", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.forgotPassword(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_resetPassword(t *testing.T) {
+	verr := &model.ValidationError{}
+	verr.Add("new_password", "is too common")
+
+	tests := []struct {
+		name         string
+		body         string
+		setup        func(m *mock_service.MockAuthorization)
+		expectedCode int
+	}{
+		{
+			name: "success",
+			body: `{"token":"reset","new_password":"Secret123"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ResetPassword("reset", "Secret123").Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "missing token",
+			body:         `{"new_password":"Secret123"}`,
+			setup:        func(m *mock_service.MockAuthorization) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid token",
+			body: `{"token":"reset","new_password":"Secret123"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ResetPassword("reset", "Secret123").Return(service.ErrInvalidResetToken)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "weak password",
+			body: `{"token":"reset","new_password":"password"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ResetPassword("reset", "password").Return(verr)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "disabled user",
+			body: `{"token":"reset","new_password":"Secret123"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ResetPassword("reset", "Secret123").Return(service.ErrUserDisabled)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mock_service.NewMockAuthorization(ctrl)
+			tt.setup(mockAuthService)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mockAuthService,
+				},
+			}
+
+			req := httptest.NewRequest("POST", "/auth/reset-password", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.resetPassword(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
 	}
 }
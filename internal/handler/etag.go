@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+)
+
+// etag возвращает ETag версии фильма или актера, например "3".
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersions возвращает версии ресурса, перечисленные в заголовке If-Match. Пустой список означает,
+// что заголовка нет или он равен *, то есть изменение выполняется безусловно. Если в заголовке нет ни одного
+// ETag, выданного API, ему не соответствует ни одна версия, и функция возвращает service.ErrVersionMismatch.
+func ifMatchVersions(r *http.Request) (model.Versions, error) {
+	tags := entityTags(r, "If-Match")
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	var versions model.Versions
+	for _, tag := range tags {
+		if tag == "*" {
+			return nil, nil
+		}
+
+		// If-Match сравнивает ETag строго, поэтому слабые ETag (W/"3") не соответствуют ни одной версии.
+		if version, ok := parseETag(tag); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, service.ErrVersionMismatch
+	}
+
+	return versions, nil
+}
+
+// notModified сообщает, соответствует ли ETag ресурса заголовку If-None-Match, то есть есть ли у клиента
+// актуальная копия ресурса. If-None-Match сравнивает ETag слабо, поэтому префикс W/ не учитывается.
+func notModified(r *http.Request, etag string) bool {
+	for _, tag := range entityTags(r, "If-None-Match") {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conditionalGet отправляет клиенту ETag ресурса. Если у клиента уже есть актуальная копия ресурса, отвечает 304
+// без тела и возвращает true.
+func conditionalGet(w http.ResponseWriter, r *http.Request, version int) bool {
+	tag := etag(version)
+	w.Header().Set("ETag", tag)
+
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
+
+// entityTags возвращает ETag, перечисленные через запятую в заголовках name.
+func entityTags(r *http.Request, name string) []string {
+	var tags []string
+	for _, value := range r.Header.Values(name) {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// parseETag возвращает версию, закодированную в сильном ETag, выданном функцией etag.
+func parseETag(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	return version, err == nil
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      []string
+		expected    model.Versions
+		expectedErr error
+	}{
+		{name: "no header"},
+		{name: "any version", header: []string{"*"}},
+		{name: "single version", header: []string{`"3"`}, expected: model.Versions{3}},
+		{name: "list of versions", header: []string{`"3", W/"4"`, `"5"`}, expected: model.Versions{3, 5}},
+		{name: "weak version only", header: []string{`W/"3"`}, expectedErr: service.ErrVersionMismatch},
+		{name: "foreign tag", header: []string{`"abc"`}, expectedErr: service.ErrVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/api/movie/1", nil)
+			for _, value := range tt.header {
+				req.Header.Add("If-Match", value)
+			}
+
+			versions, err := ifMatchVersions(req)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if !slices.Equal(versions, tt.expected) {
+				t.Errorf("Expected versions %v, got %v", tt.expected, versions)
+			}
+		})
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		expectedCode int
+	}{
+		{name: "no header", expectedCode: http.StatusOK},
+		{name: "current version", header: `"3"`, expectedCode: http.StatusNotModified},
+		{name: "weak current version", header: `W/"3"`, expectedCode: http.StatusNotModified},
+		{name: "one of versions", header: `"2", "3"`, expectedCode: http.StatusNotModified},
+		{name: "any version", header: "*", expectedCode: http.StatusNotModified},
+		{name: "old version", header: `"2"`, expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/movie/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-None-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			if !conditionalGet(w, req, 3) {
+				w.WriteHeader(http.StatusOK)
+			}
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+			if tag := w.Header().Get("ETag"); tag != `"3"` {
+				t.Errorf("Expected ETag %q, got %q", `"3"`, tag)
+			}
+		})
+	}
+}
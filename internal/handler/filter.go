@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+const dateLayout = "2006-1-2"
+
+// parseMovieFilter извлекает параметры фильтрации фильмов из строки запроса:
+// title, min_rating, max_rating, released_after, released_before и повторяемый actor_id.
+func parseMovieFilter(r *http.Request) (model.MovieFilter, error) {
+	query := r.URL.Query()
+	filter := model.MovieFilter{Title: query.Get("title")}
+
+	for _, param := range []struct {
+		name  string
+		value **int
+	}{
+		{"min_rating", &filter.MinRating},
+		{"max_rating", &filter.MaxRating},
+	} {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+
+		rating, err := strconv.Atoi(raw)
+		if err != nil || rating < 0 || rating > 10 {
+			return filter, fmt.Errorf("%s must be an integer between 0 and 10", param.name)
+		}
+		*param.value = &rating
+	}
+
+	if filter.MinRating != nil && filter.MaxRating != nil && *filter.MinRating > *filter.MaxRating {
+		return filter, errors.New("min_rating must not be greater than max_rating")
+	}
+
+	var after, before time.Time
+	for _, param := range []struct {
+		name  string
+		value *string
+		date  *time.Time
+	}{
+		{"released_after", &filter.ReleasedAfter, &after},
+		{"released_before", &filter.ReleasedBefore, &before},
+	} {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be a date in YYYY-MM-DD format", param.name)
+		}
+		*param.value = date.Format(time.DateOnly)
+		*param.date = date
+	}
+
+	if filter.ReleasedAfter != "" && filter.ReleasedBefore != "" && after.After(before) {
+		return filter, errors.New("released_after must not be later than released_before")
+	}
+
+	for _, raw := range query["actor_id"] {
+		actorID, err := strconv.Atoi(raw)
+		if err != nil || actorID < 1 {
+			return filter, errors.New("actor_id must be a positive integer")
+		}
+		filter.ActorIDs = append(filter.ActorIDs, actorID)
+	}
+
+	return filter, nil
+}
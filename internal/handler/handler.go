@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	_ "github.com/avealice/filmhub/docs"
+	"github.com/avealice/filmhub/internal/model"
 	"github.com/avealice/filmhub/internal/service"
 
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -18,9 +19,9 @@ func NewHandler(services *service.Service) *Handler {
 }
 
 func (h *Handler) InitRoutes() http.Handler {
-	mux := http.NewServeMux()
+	rt := newRouter()
 
-	mux.HandleFunc("/swagger/", func(w http.ResponseWriter, r *http.Request) {
+	rt.handle("/swagger/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodOptions {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
@@ -34,25 +35,46 @@ func (h *Handler) InitRoutes() http.Handler {
 			httpSwagger.DocExpansion("none"),
 			httpSwagger.DomID("swagger-ui"),
 		).ServeHTTP(w, r)
-	})
+	}))
 
-	authMux := http.NewServeMux()
-	authMux.HandleFunc("/sign-in", h.signIn)
-	authMux.HandleFunc("/sign-up", h.signUp)
+	rt.handle("POST /auth/sign-in", http.HandlerFunc(h.signIn))
+	rt.handle("POST /auth/sign-up", http.HandlerFunc(h.signUp))
+	rt.handle("POST /auth/refresh", http.HandlerFunc(h.refresh))
+	rt.handle("POST /auth/logout", h.authenticated(h.logout))
+	rt.handle("POST /auth/forgot-password", http.HandlerFunc(h.forgotPassword))
+	rt.handle("POST /auth/reset-password", http.HandlerFunc(h.resetPassword))
+	rt.handle("GET /auth/oidc/login", http.HandlerFunc(h.oidcLogin))
+	rt.handle("GET /auth/oidc/callback", http.HandlerFunc(h.oidcCallback))
+	rt.handle("GET /.well-known/jwks.json", http.HandlerFunc(h.jwks))
 
-	mux.Handle("/auth/", http.StripPrefix("/auth", authMux))
+	rt.handle("GET /api/movies", h.authenticated(h.getAllMovies))
+	rt.handle("POST /api/movie", h.permitted(model.PermissionMovieWrite, h.createMovie))
+	rt.handle("GET /api/movie/search", h.authenticated(h.searchMovie))
+	rt.handle("GET /api/movie/{id}", h.authenticated(h.getMovie))
+	rt.handle("PUT /api/movie/{id}", h.permitted(model.PermissionMovieWrite, h.updateMovie))
+	rt.handle("PATCH /api/movie/{id}", h.permitted(model.PermissionMovieWrite, h.patchMovie))
+	rt.handle("DELETE /api/movie/{id}", h.permitted(model.PermissionMovieDelete, h.deleteMovie))
+	rt.handle("POST /api/movie/{id}/actors/{actorId}", h.permitted(model.PermissionMovieWrite, h.addMovieActor))
+	rt.handle("DELETE /api/movie/{id}/actors/{actorId}", h.permitted(model.PermissionMovieWrite, h.removeMovieActor))
 
-	apiMux := http.NewServeMux()
-	apiMux.Handle("/movies", h.userIdentity(http.HandlerFunc(h.getAllMovies)))
-	apiMux.Handle("/movie/", h.userIdentity(http.HandlerFunc(h.movieHandle)))
-	apiMux.Handle("/movie", h.userIdentity(http.HandlerFunc(h.createMovie)))
-	apiMux.Handle("/movie/search", h.userIdentity(http.HandlerFunc(h.searchMovie)))
+	rt.handle("GET /api/actors", h.authenticated(h.getAllActors))
+	rt.handle("GET /api/actors/search", h.authenticated(h.searchActors))
+	rt.handle("POST /api/actor", h.permitted(model.PermissionActorWrite, h.CreateActor))
+	rt.handle("GET /api/actor/{id}", h.authenticated(h.getActor))
+	rt.handle("PUT /api/actor/{id}", h.permitted(model.PermissionActorWrite, h.updateActor))
+	rt.handle("DELETE /api/actor/{id}", h.permitted(model.PermissionActorDelete, h.deleteActor))
 
-	apiMux.Handle("/actors", h.userIdentity(http.HandlerFunc(h.getAllActors)))
-	apiMux.Handle("/actor", h.userIdentity(http.HandlerFunc(h.CreateActor)))
-	apiMux.Handle("/actor/", h.userIdentity(http.HandlerFunc(h.actorHandle)))
+	rt.handle("GET /api/me", h.authenticated(h.getMe))
+	rt.handle("PATCH /api/me", h.authenticated(h.updateMe))
+	rt.handle("POST /api/me/password", h.authenticated(h.changePassword))
 
-	mux.Handle("/api/", http.StripPrefix("/api", apiMux))
+	rt.handle("GET /api/admin/users", h.permitted(model.PermissionUserManage, h.getAllUsers))
+	rt.handle("GET /api/admin/users/{id}", h.permitted(model.PermissionUserManage, h.getUser))
+	rt.handle("PATCH /api/admin/users/{id}", h.permitted(model.PermissionUserManage, h.updateUser))
+	rt.handle("DELETE /api/admin/users/{id}", h.permitted(model.PermissionUserManage, h.deleteUser))
+	rt.handle("GET /api/admin/api-keys", h.permitted(model.PermissionUserManage, h.getAllAPIKeys))
+	rt.handle("POST /api/admin/api-keys", h.permitted(model.PermissionUserManage, h.createAPIKey))
+	rt.handle("DELETE /api/admin/api-keys/{id}", h.permitted(model.PermissionUserManage, h.revokeAPIKey))
 
-	return mux
+	return rt
 }
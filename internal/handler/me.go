@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+// getMe возвращает учетную запись текущего пользователя.
+//
+// @Summary Текущий пользователь
+// @Description Возвращает имя, роль, время регистрации и настройки пользователя, от имени которого выполняется запрос.
+// @Description Для ключа API возвращается учетная запись его создателя.
+// @Tags /api/me
+// @Produce json
+// @Success 200 {object} model.Profile
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/me [get]
+// @Security ApiKeyAuth
+func (h *Handler) getMe(w http.ResponseWriter, r *http.Request) {
+	identity, err := getIdentity(r)
+	if err != nil {
+		newErrorResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	profile, err := h.services.Profile.GetProfile(identity.UserID)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to process profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// updateMe изменяет настройки текущего пользователя.
+//
+// @Summary Изменить настройки
+// @Description Изменяет настройки текущего пользователя: сортировку списка фильмов по умолчанию (movie_sort, в формате параметра sort_by)
+// @Description и предпочитаемый язык (locale, например ru или en-US). Поля, которые не указаны, не изменяются, пустая строка возвращает значение по умолчанию.
+// @Description Недоступно для ключей API.
+// @Tags /api/me
+// @Accept json
+// @Produce json
+// @Param preferences body model.UpdatePreferencesInput true "Изменения настроек"
+// @Success 200 {object} model.Profile
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Запрос аутентифицирован ключом API"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/me [patch]
+// @Security ApiKeyAuth
+func (h *Handler) updateMe(w http.ResponseWriter, r *http.Request) {
+	identity, err := getIdentity(r)
+	if err != nil {
+		newErrorResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if identity.APIKeyID != 0 {
+		newErrorResponse(w, http.StatusForbidden, "API keys cannot change preferences")
+		return
+	}
+
+	var input model.UpdatePreferencesInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	profile, err := h.services.Profile.UpdatePreferences(identity.UserID, input)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to process profile")
+		return
+	}
+
+	logrus.WithField("user_id", identity.UserID).Info("Preferences updated successfully")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"` // Текущий пароль
+	NewPassword     string `json:"new_password"`     // Новый пароль, соответствующий парольной политике
+}
+
+// changePassword изменяет пароль текущего пользователя.
+//
+// @Summary Смена пароля
+// @Description Изменяет пароль текущего пользователя после проверки текущего пароля. Все сеансы пользователя завершаются,
+// @Description а в ответе возвращается новая пара токенов, чтобы текущий клиент остался в системе.
+// @Description Неверный текущий пароль считается неудачной попыткой входа. Недоступно для ключей API.
+// @Tags /api/me
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordInput true "Текущий и новый пароль"
+// @Success 200 {object} TokenResponse "Новая пара токенов"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос, неверный текущий пароль"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Запрос аутентифицирован ключом API"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, новый пароль не соответствует требованиям"
+// @Failure 429 {object} ErrorResponse "Слишком много неудачных попыток, время ожидания в секундах указано в заголовке Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/me/password [post]
+// @Security ApiKeyAuth
+func (h *Handler) changePassword(w http.ResponseWriter, r *http.Request) {
+	identity, err := getIdentity(r)
+	if err != nil {
+		newErrorResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if identity.APIKeyID != 0 {
+		newErrorResponse(w, http.StatusForbidden, "API keys cannot change passwords")
+		return
+	}
+
+	var input ChangePasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	tokens, err := h.services.Authorization.ChangePassword(identity.UserID, input.CurrentPassword, input.NewPassword, clientIP(r))
+	if err != nil {
+		var throttled *service.ThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			newThrottledErrorResponse(w, throttled)
+		default:
+			newServiceErrorResponse(w, err, "Failed to change password")
+		}
+		return
+	}
+
+	logrus.WithField("user_id", identity.UserID).Info("Password changed successfully")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTokenResponse(tokens))
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+
+	mock_service "github.com/avealice/filmhub/internal/service/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_changePassword(t *testing.T) {
+	verr := &model.ValidationError{}
+	verr.Add("new_password", "must contain a digit")
+
+	tests := []struct {
+		name               string
+		body               string
+		setup              func(m *mock_service.MockAuthorization)
+		expectedCode       int
+		expectedRetryAfter string
+	}{
+		{
+			name: "success",
+			body: `{"current_password":"Old12345","new_password":"New12345"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ChangePassword(1, "Old12345", "New12345", "192.0.2.1").
+					Return(model.Tokens{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "incorrect password",
+			body: `{"current_password":"wrong","new_password":"New12345"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ChangePassword(1, "wrong", "New12345", "192.0.2.1").Return(model.Tokens{}, service.ErrIncorrectPassword)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "weak password",
+			body: `{"current_password":"Old12345","new_password":"NewPassword"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ChangePassword(1, "Old12345", "NewPassword", "192.0.2.1").Return(model.Tokens{}, verr)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "throttled",
+			body: `{"current_password":"wrong","new_password":"New12345"}`,
+			setup: func(m *mock_service.MockAuthorization) {
+				m.EXPECT().ChangePassword(1, "wrong", "New12345", "192.0.2.1").
+					Return(model.Tokens{}, &service.ThrottledError{RetryAfter: 30 * time.Second})
+			},
+			expectedCode:       http.StatusTooManyRequests,
+			expectedRetryAfter: "30",
+		},
+		{
+			name:         "invalid body",
+			body:         `{`,
+			setup:        func(m *mock_service.MockAuthorization) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mock_service.NewMockAuthorization(ctrl)
+			tt.setup(mockAuthService)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mockAuthService,
+				},
+			}
+
+			req := withIdentity(httptest.NewRequest("POST", "/api/me/password", strings.NewReader(tt.body)), "user")
+			w := httptest.NewRecorder()
+
+			handler.changePassword(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if retryAfter := w.Header().Get("Retry-After"); retryAfter != tt.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.expectedRetryAfter, retryAfter)
+			}
+
+			if tt.expectedCode == http.StatusOK {
+				var response TokenResponse
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Fatalf("Error decoding response body: %v", err)
+				}
+				if response.Token != "access" || response.RefreshToken != "refresh" {
+					t.Errorf("Unexpected tokens %+v", response)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_changePassword_APIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mock_service.NewMockAuthorization(ctrl),
+		},
+	}
+
+	identity := model.Identity{UserID: 1, Role: "admin", APIKeyID: 7}
+	req := httptest.NewRequest("POST", "/api/me/password", strings.NewReader(`{"current_password":"a","new_password":"b"}`))
+	req = req.WithContext(context.WithValue(req.Context(), identityCtx, identity))
+	w := httptest.NewRecorder()
+
+	handler.changePassword(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestHandler_meRoutes(t *testing.T) {
+	verr := &model.ValidationError{}
+	verr.Add("locale", "must be a language tag such as ru or en-US")
+
+	profile := model.Profile{
+		ID:          1,
+		Username:    "alice",
+		Role:        "user",
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Preferences: model.Preferences{MovieSort: "title", Locale: "ru"},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		setup        func(m *mock_service.MockProfile)
+		expectedCode int
+	}{
+		{
+			name:   "get",
+			method: http.MethodGet,
+			setup: func(m *mock_service.MockProfile) {
+				m.EXPECT().GetProfile(1).Return(profile, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "update",
+			method: http.MethodPatch,
+			body:   `{"movie_sort":"title","locale":"ru"}`,
+			setup: func(m *mock_service.MockProfile) {
+				movieSort, locale := "title", "ru"
+				m.EXPECT().UpdatePreferences(1, model.UpdatePreferencesInput{MovieSort: &movieSort, Locale: &locale}).Return(profile, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "invalid preferences",
+			method: http.MethodPatch,
+			body:   `{"locale":"english"}`,
+			setup: func(m *mock_service.MockProfile) {
+				m.EXPECT().UpdatePreferences(1, gomock.Any()).Return(model.Profile{}, verr)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "unknown field",
+			method:       http.MethodPatch,
+			body:         `{"role":"admin"}`,
+			setup:        func(m *mock_service.MockProfile) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "method not allowed",
+			method:       http.MethodDelete,
+			setup:        func(m *mock_service.MockProfile) {},
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockProfileService := mock_service.NewMockProfile(ctrl)
+			tt.setup(mockProfileService)
+
+			handler := &Handler{
+				services: &service.Service{
+					Profile: mockProfileService,
+				},
+			}
+
+			req := httptest.NewRequest(tt.method, "/api/me", strings.NewReader(tt.body))
+			req = withRouteIdentity(ctrl, handler, req, "user")
+			w := httptest.NewRecorder()
+
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if tt.expectedCode == http.StatusOK {
+				var response model.Profile
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Fatalf("Error decoding response body: %v", err)
+				}
+				if response.Username != profile.Username || !response.CreatedAt.Equal(profile.CreatedAt) || response.Preferences != profile.Preferences {
+					t.Errorf("Expected profile %+v, got %+v", profile, response)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_updateMe_APIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := &Handler{
+		services: &service.Service{
+			Profile: mock_service.NewMockProfile(ctrl),
+		},
+	}
+
+	identity := model.Identity{UserID: 1, Role: "admin", APIKeyID: 7}
+	req := httptest.NewRequest(http.MethodPatch, "/api/me", strings.NewReader(`{"locale":"ru"}`))
+	req = req.WithContext(context.WithValue(req.Context(), identityCtx, identity))
+	w := httptest.NewRecorder()
+
+	handler.updateMe(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
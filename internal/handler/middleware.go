@@ -5,70 +5,100 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+
+	"github.com/avealice/filmhub/internal/model"
 )
 
 const (
 	authorizationHeader = "Authorization"
+	apiKeyHeader        = "X-API-Key"
+	apiKeyScheme        = "ApiKey"
 	userRoleCtx         = "role"
 	userIDCtx           = "user_id"
+	identityCtx         = "identity"
 )
 
 type Middleware func(http.HandlerFunc) http.HandlerFunc
 
 // userIdentity проверяет наличие и валидность токена аутентификации в заголовке запроса.
-// Если токен корректен, устанавливает роль и id пользователя в контекст запроса.
+// Запрос аутентифицируется токеном доступа в заголовке Authorization (Bearer) либо ключом API
+// в заголовке X-API-Key или Authorization (ApiKey). Если токен или ключ корректен,
+// устанавливает роль и id пользователя в контекст запроса.
 // @Summary Проверка аутентификации пользователя
 // @Description Middleware для проверки аутентификации пользователя и установки его роли и id в контекст запроса
 // @Tags Authentication
 // @Security ApiKeyAuth
 func (h *Handler) userIdentity(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		header := r.Header.Get(authorizationHeader)
-		if header == "" {
-			http.Error(w, "empty auth header", http.StatusUnauthorized)
-			return
-		}
+		var identity model.Identity
+		var err error
 
-		var token string
-		headerParts := strings.Split(header, " ")
-		if len(headerParts) > 2 || len(headerParts) == 0 {
-			http.Error(w, "invalid auth header", http.StatusUnauthorized)
-			return
-		} else if len(headerParts) == 2 {
-			token = headerParts[1]
+		if apiKey := r.Header.Get(apiKeyHeader); apiKey != "" {
+			identity, err = h.services.APIKey.ParseAPIKey(apiKey)
 		} else {
-			token = headerParts[0]
-		}
+			header := r.Header.Get(authorizationHeader)
+			if header == "" {
+				newErrorResponse(w, http.StatusUnauthorized, "empty auth header")
+				return
+			}
+
+			var scheme, token string
+			headerParts := strings.Split(header, " ")
+			if len(headerParts) > 2 || len(headerParts) == 0 {
+				newErrorResponse(w, http.StatusUnauthorized, "invalid auth header")
+				return
+			} else if len(headerParts) == 2 {
+				scheme, token = headerParts[0], headerParts[1]
+			} else {
+				token = headerParts[0]
+			}
 
-		user_id, role, err := h.services.Authorization.ParseToken(token)
+			if strings.EqualFold(scheme, apiKeyScheme) {
+				identity, err = h.services.APIKey.ParseAPIKey(token)
+			} else {
+				identity, err = h.services.Authorization.ParseToken(token)
+			}
+		}
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+			newServiceErrorResponse(w, err, "Failed to authenticate")
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), userRoleCtx, role)
-		ctx = context.WithValue(ctx, userIDCtx, user_id)
+		ctx := context.WithValue(r.Context(), userRoleCtx, identity.Role)
+		ctx = context.WithValue(ctx, userIDCtx, identity.UserID)
+		ctx = context.WithValue(ctx, identityCtx, identity)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// getUserRole извлекает роль пользователя из контекста запроса.
-// Если роль отсутствует или имеет неверный тип, возвращает ошибку.
-// @Summary Извлечение роли пользователя
-// @Description Извлекает роль пользователя из контекста запроса
-// @Tags Authentication
-func getUserRole(r *http.Request) (string, error) {
-	role := r.Context().Value(userRoleCtx)
-	if role == nil {
-		return "", errors.New("user role not found")
-	}
+// requirePermission пропускает запрос к next, только если роли пользователя выдано разрешение permission.
+// Должен вызываться после userIdentity, иначе запрос отклоняется.
+func (h *Handler) requirePermission(permission string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := getIdentity(r)
+		if err != nil {
+			newErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
 
-	roleStr, ok := role.(string)
-	if !ok {
-		return "", errors.New("user role is invalid type")
-	}
+		if !identity.HasPermission(permission) {
+			newErrorResponse(w, http.StatusForbidden, "permission "+permission+" is required")
+			return
+		}
 
-	return roleStr, nil
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated пропускает к обработчику только аутентифицированные запросы.
+func (h *Handler) authenticated(next http.HandlerFunc) http.Handler {
+	return h.userIdentity(next)
+}
+
+// permitted пропускает к обработчику только аутентифицированные запросы пользователей,
+// роли которых выдано разрешение permission.
+func (h *Handler) permitted(permission string, next http.HandlerFunc) http.Handler {
+	return h.userIdentity(h.requirePermission(permission, next))
 }
 
 // getUserID извлекает идентификатор пользователя из контекста запроса.
@@ -89,3 +119,13 @@ func getUserID(r *http.Request) (int, error) {
 
 	return userIDInt, nil
 }
+
+// getIdentity извлекает данные токена доступа, которым аутентифицирован запрос.
+func getIdentity(r *http.Request) (model.Identity, error) {
+	identity, ok := r.Context().Value(identityCtx).(model.Identity)
+	if !ok {
+		return model.Identity{}, errors.New("identity not found")
+	}
+
+	return identity, nil
+}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/avealice/filmhub/internal/model"
 	"github.com/avealice/filmhub/internal/service"
 
 	mock_service "github.com/avealice/filmhub/internal/service/mocks"
@@ -13,12 +15,33 @@ import (
 	"github.com/golang/mock/gomock"
 )
 
+// adminPermissions содержит все разрешения, выдаваемые роли admin.
+var adminPermissions = []string{
+	model.PermissionMovieWrite,
+	model.PermissionMovieDelete,
+	model.PermissionActorWrite,
+	model.PermissionActorDelete,
+	model.PermissionUserManage,
+}
+
+// withIdentity возвращает запрос, аутентифицированный пользователем с ролью role и разрешениями permissions,
+// как после прохождения userIdentity.
+func withIdentity(req *http.Request, role string, permissions ...string) *http.Request {
+	identity := model.Identity{UserID: 1, Role: role, Permissions: permissions, TokenID: "jti"}
+
+	ctx := context.WithValue(req.Context(), userRoleCtx, identity.Role)
+	ctx = context.WithValue(ctx, userIDCtx, identity.UserID)
+	ctx = context.WithValue(ctx, identityCtx, identity)
+
+	return req.WithContext(ctx)
+}
+
 func TestHandler_userIdentity_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockAuthService := mock_service.NewMockAuthorization(ctrl)
-	mockAuthService.EXPECT().ParseToken(gomock.Any()).Return(1, "user", nil)
+	mockAuthService.EXPECT().ParseToken(gomock.Any()).Return(model.Identity{UserID: 1, Role: "user", TokenID: "jti"}, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -72,7 +95,10 @@ func TestHandler_userIdentity_AuthHeaderMissing(t *testing.T) {
 	if w.Code != http.StatusUnauthorized {
 		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
 	}
+
+	assertProblem(t, w, "unauthorized", "empty auth header")
 }
+
 func TestHandler_userIdentity_InvalidAuthHeader(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -80,7 +106,7 @@ func TestHandler_userIdentity_InvalidAuthHeader(t *testing.T) {
 	mockAuthService := mock_service.NewMockAuthorization(ctrl)
 
 	// Настраиваем ожидаемое поведение мок-сервиса
-	mockAuthService.EXPECT().ParseToken("invalid_token").Return(1, "", errors.New("invalid_token"))
+	mockAuthService.EXPECT().ParseToken("invalid_token").Return(model.Identity{}, service.ErrInvalidToken)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -102,6 +128,120 @@ func TestHandler_userIdentity_InvalidAuthHeader(t *testing.T) {
 	if w.Code != http.StatusUnauthorized {
 		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
 	}
+
+	assertProblem(t, w, "invalid_token", service.ErrInvalidToken.Error())
+}
+
+func TestHandler_userIdentity_ServiceFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_service.NewMockAuthorization(ctrl)
+	mockAuthService.EXPECT().ParseToken("token").Return(model.Identity{}, errors.New("pq: connection refused"))
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mockAuthService,
+		},
+	}
+
+	identityHandler := handler.userIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called if the token cannot be checked")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(authorizationHeader, "Bearer token")
+	w := httptest.NewRecorder()
+
+	identityHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	assertProblem(t, w, "internal_error", "Failed to authenticate")
+}
+
+func TestHandler_userIdentity_APIKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "X-API-Key header", header: apiKeyHeader, value: "fh_key"},
+		{name: "Authorization header", header: authorizationHeader, value: "ApiKey fh_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAPIKeyService := mock_service.NewMockAPIKey(ctrl)
+			mockAPIKeyService.EXPECT().ParseAPIKey("fh_key").Return(model.Identity{
+				UserID:      1,
+				Role:        "admin",
+				Permissions: []string{model.PermissionMovieWrite},
+				APIKeyID:    3,
+			}, nil)
+
+			handler := &Handler{
+				services: &service.Service{
+					Authorization: mock_service.NewMockAuthorization(ctrl),
+					APIKey:        mockAPIKeyService,
+				},
+			}
+
+			identityHandler := handler.userIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				identity, err := getIdentity(r)
+				if err != nil || identity.APIKeyID != 3 {
+					t.Errorf("Expected API key identity in context, got %+v, %v", identity, err)
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+
+			identityHandler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_userIdentity_InvalidAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_service.NewMockAPIKey(ctrl)
+	mockAPIKeyService.EXPECT().ParseAPIKey("fh_revoked").Return(model.Identity{}, service.ErrInvalidAPIKey)
+
+	handler := &Handler{
+		services: &service.Service{
+			APIKey: mockAPIKeyService,
+		},
+	}
+
+	identityHandler := handler.userIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called if API key is invalid")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(apiKeyHeader, "fh_revoked")
+	w := httptest.NewRecorder()
+
+	identityHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+	assertProblem(t, w, "invalid_api_key", service.ErrInvalidAPIKey.Error())
 }
 
 func TestHandler_userIdentity_AdminRole(t *testing.T) {
@@ -109,7 +249,7 @@ func TestHandler_userIdentity_AdminRole(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockAuthService := mock_service.NewMockAuthorization(ctrl)
-	mockAuthService.EXPECT().ParseToken(gomock.Any()).Return(1, "admin", nil)
+	mockAuthService.EXPECT().ParseToken(gomock.Any()).Return(model.Identity{UserID: 1, Role: "admin", TokenID: "jti"}, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -137,3 +277,60 @@ func TestHandler_userIdentity_AdminRole(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 }
+
+func TestHandler_requirePermission_EditorRole(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		expectedCode int
+	}{
+		{name: "update allowed", method: http.MethodPut, expectedCode: http.StatusOK},
+		{name: "delete forbidden", method: http.MethodDelete, expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &Handler{}
+
+			var called bool
+			next := handler.requirePermission(model.PermissionMovieWrite, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}))
+			if tt.method == http.MethodDelete {
+				next = handler.requirePermission(model.PermissionMovieDelete, next)
+			}
+
+			req := httptest.NewRequest(tt.method, "/api/movie/1", nil)
+			req = withIdentity(req, "editor", model.PermissionMovieWrite, model.PermissionActorWrite)
+			w := httptest.NewRecorder()
+
+			next.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if called != (tt.expectedCode == http.StatusOK) {
+				t.Errorf("Expected handler to be called: %t, got %t", tt.expectedCode == http.StatusOK, called)
+			}
+		})
+	}
+}
+
+func TestHandler_requirePermission_NoIdentity(t *testing.T) {
+	handler := &Handler{}
+
+	next := handler.requirePermission(model.PermissionMovieWrite, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called without identity")
+	}))
+
+	req := httptest.NewRequest("POST", "/api/movie", nil)
+	w := httptest.NewRecorder()
+
+	next.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
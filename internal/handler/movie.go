@@ -2,9 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 
 	"github.com/avealice/filmhub/internal/model"
 	"github.com/sirupsen/logrus"
@@ -12,38 +12,60 @@ import (
 
 // getAllMovies возвращает список всех фильмов.
 // @Summary Получить все фильмы
-// @Description Получает список всех фильмов с возможностью сортировки.
+// @Description Получает список всех фильмов с возможностью фильтрации, сортировки и постраничного вывода.
+// @Description Все указанные фильтры применяются одновременно.
 // @Tags /api/movies
 // @Produce json
-// @Param sort_by query string false "Критерий сортировки (title, rating, release_date)"
-// @Param sort_order query string false "Порядок сортировки (asc, desc)"
-// @Success 200 {array} model.Movie "Список фильмов"
-// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Param title query string false "Фрагмент названия фильма"
+// @Param min_rating query int false "Минимальный рейтинг включительно (0-10)"
+// @Param max_rating query int false "Максимальный рейтинг включительно (0-10)"
+// @Param released_after query string false "Самая ранняя дата выхода включительно (YYYY-MM-DD)"
+// @Param released_before query string false "Самая поздняя дата выхода включительно (YYYY-MM-DD)"
+// @Param actor_id query []int false "Идентификатор актера, можно указать несколько раз: фильмы, в которых снялся хотя бы один из актеров" collectionFormat(multi)
+// @Param sort_by query string false "Критерии сортировки через запятую (title, rating, release_date), префикс - задает порядок по убыванию, например rating,-release_date. По умолчанию используется сортировка из настроек пользователя (movie_sort)"
+// @Param sort_order query string false "Порядок сортировки полей без префикса (asc, desc)"
+// @Param limit query int false "Количество фильмов на странице (1-100, по умолчанию 20)"
+// @Param offset query int false "Количество пропускаемых фильмов"
+// @Param after query string false "Курсор следующей страницы из поля next_cursor"
+// @Success 200 {object} model.MoviesPage "Страница списка фильмов"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные, для параметров сортировки в поле allowed перечислены допустимые значения"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/movies [get]
 // @Security ApiKeyAuth
 func (h *Handler) getAllMovies(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	filter, err := parseMovieFilter(r)
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	sortBy := r.URL.Query().Get("sort_by")
-	sortOrder := r.URL.Query().Get("sort_order")
+	sort, err := h.parseUserMovieSort(r)
+	if err != nil {
+		if errors.As(err, new(*model.SortError)) {
+			newSortErrorResponse(w, err)
+			return
+		}
 
-	if sortBy == "" {
-		sortBy = "rating"
+		newServiceErrorResponse(w, err, "Failed to get movies")
+		return
 	}
 
-	if sortOrder == "" {
-		sortOrder = "desc"
+	page, err := parsePagination(r)
+	if err != nil {
+		newBadRequestResponse(w, err)
+		return
 	}
 
-	movies, err := h.services.Movie.GetAllMovies(sortBy, sortOrder)
+	movies, err := h.services.Movie.GetAllMovies(filter, sort, page)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "Failed to get movies")
+		if errors.As(err, new(*model.SortError)) {
+			newSortErrorResponse(w, err)
+			return
+		}
+
+		newServiceErrorResponse(w, err, "Failed to get movies")
 		return
 	}
 
@@ -51,7 +73,9 @@ func (h *Handler) getAllMovies(w http.ResponseWriter, r *http.Request) {
 
 	logrus.WithFields(logrus.Fields{
 		"user_id": userID,
-		"count":   len(movies),
+		"sort":    sort.String(),
+		"count":   len(movies.Movies),
+		"total":   movies.Total,
 	}).Info("Movies successfully fetched")
 
 	w.Header().Set("Content-Type", "application/json")
@@ -65,47 +89,41 @@ func (h *Handler) getAllMovies(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param movie body model.InputMovie true "Данные нового фильма"
-// @Success 201 "Фильм создан успешно"
+// @Success 201 {object} model.MovieWithActors "Созданный фильм"
+// @Header 201 {string} Location "Адрес созданного фильма, например /api/movie/1"
+// @Header 201 {string} ETag "Версия созданного фильма"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 409 {object} ErrorResponse "Такой фильм уже существует"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/movie [post]
 // @Security ApiKeyAuth
 func (h *Handler) createMovie(w http.ResponseWriter, r *http.Request) {
-	role, err := getUserRole(r)
-	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "failed to get user role")
-		return
-	}
-
-	if role != "admin" {
-		newErrorResponse(w, http.StatusForbidden, "only admin can create movies")
-		return
-	}
-
 	var input model.InputMovie
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = h.services.Movie.CreateMovie(input)
+	movie, err := h.services.Movie.CreateMovie(input)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(w, err, "Failed to create movie")
 		return
 	}
 
 	userID, _ := getUserID(r)
 
 	logEntry := logrus.WithFields(logrus.Fields{
-		"user_id": userID,
+		"user_id":  userID,
+		"movie_id": movie.ID,
 	})
 
 	logEntry.Info("Movie created successfully")
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("movie created successfully"))
+	w.Header().Set("ETag", etag(movie.Version))
+	newCreatedResponse(w, fmt.Sprintf("/api/movie/%d", movie.ID), movie)
 }
 
 // deleteMovie удаляет фильм по его идентификатору.
@@ -113,43 +131,32 @@ func (h *Handler) createMovie(w http.ResponseWriter, r *http.Request) {
 // @Description Удаляет фильм по его идентификатору.
 // @Tags /api/movie/{id}
 // @Param id path int true "Идентификатор фильма"
-// @Success 200 "Фильм удален успешно"
+// @Param If-Match header string false "ETag фильма, полученный при его чтении: изменение выполняется, только если фильм с тех пор не изменился"
+// @Success 200 {object} MessageResponse "Фильм удален успешно"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 412 {object} ErrorResponse "Фильм изменился или был удален после чтения, ETag не соответствует заголовку If-Match"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/movie/{id} [delete]
 // @Security ApiKeyAuth
 func (h *Handler) deleteMovie(w http.ResponseWriter, r *http.Request) {
-	role, err := getUserRole(r)
+	movieID, err := pathInt(r, "id")
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "failed to get user role")
-		return
-	}
-
-	if role != "admin" {
-		newErrorResponse(w, http.StatusForbidden, "only admin can delete movies")
-		return
-	}
-
-	path := strings.TrimSuffix(r.URL.Path, "/")
-	parts := strings.Split(path, "/")
-
-	if len(parts) != 3 || parts[1] != "movie" {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		newErrorResponse(w, http.StatusBadRequest, "Invalid movie ID")
 		return
 	}
 
-	movieID, err := strconv.Atoi(parts[2])
+	ifMatch, err := ifMatchVersions(r)
 	if err != nil {
-		newErrorResponse(w, http.StatusBadRequest, "Invalid movie ID")
+		newServiceErrorResponse(w, err, "Failed to delete movie by ID")
 		return
 	}
 
-	err = h.services.Movie.DeleteByID(movieID)
+	err = h.services.Movie.DeleteByID(movieID, ifMatch)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "Failed to delete movie by ID")
+		newServiceErrorResponse(w, err, "Failed to delete movie by ID")
 		return
 	}
 
@@ -161,49 +168,91 @@ func (h *Handler) deleteMovie(w http.ResponseWriter, r *http.Request) {
 	})
 	logEntry.Info("Deleting movie")
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("movie deleted successfully"))
+	newMessageResponse(w, http.StatusOK, "movie deleted successfully")
 }
 
+// Режимы поиска фильмов по актеру.
+const (
+	searchModeSubstring = "substring"
+	searchModeFuzzy     = "fuzzy"
+)
+
 // searchMovie выполняет поиск фильмов по указанным критериям.
 // @Summary Поиск фильмов
-// @Description Выполняет поиск фильмов по указанным критериям (название или актер).
+// @Description Выполняет поиск фильмов по указанным критериям (полнотекстовый запрос, название или актер).
+// @Description Полнотекстовый поиск по параметру q ищет по названию и описанию фильма и упорядочивает результаты по релевантности.
+// @Description Слова в кавычках ищутся как фраза, слово с * на конце - как префикс. Каждый результат такого поиска
+// @Description дополнительно содержит поля rank (релевантность) и snippet (фрагмент описания, экранированный для HTML, совпадения выделены тегом mark).
 // @Tags /api/movie/search
 // @Produce json
+// @Param q query string false "Полнотекстовый запрос, например termin* или фраза в кавычках"
 // @Param title query string false "Название фильма для поиска"
 // @Param actor query string false "Имя актера для поиска"
+// @Param mode query string false "Режим поиска по актеру: substring (по подстроке, по умолчанию) или fuzzy (с учетом опечаток, результаты упорядочены по степени сходства имени)"
+// @Param sort_by query string false "Критерии сортировки через запятую (title, rating, release_date), префикс - задает порядок по убыванию, например rating,-release_date"
+// @Param sort_order query string false "Порядок сортировки полей без префикса (asc, desc)"
+// @Param limit query int false "Количество результатов полнотекстового поиска (1-100, по умолчанию 20)"
+// @Param offset query int false "Количество пропускаемых результатов полнотекстового поиска"
 // @Success 200 {array} model.MovieWithActors "Список фильмов, удовлетворяющих критериям поиска"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/movie/search [get]
 // @Security ApiKeyAuth
 func (h *Handler) searchMovie(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	actor := r.URL.Query().Get("actor")
 	title := r.URL.Query().Get("title")
+	q := r.URL.Query().Get("q")
+
+	criteria := 0
+	for _, value := range []string{actor, title, q} {
+		if value != "" {
+			criteria++
+		}
+	}
 
-	if (title == "" && actor == "") || (title != "" && actor != "") {
+	if criteria != 1 {
 		newErrorResponse(w, http.StatusBadRequest, "Invalid search request")
 		return
 	}
 
+	if q != "" {
+		h.searchMovieFullText(w, r, q)
+		return
+	}
+
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", searchModeSubstring:
+	case searchModeFuzzy:
+		if actor == "" {
+			newErrorResponse(w, http.StatusBadRequest, "fuzzy mode is supported only for actor search")
+			return
+		}
+
+		h.searchMovieByActorFuzzy(w, r, actor)
+		return
+	default:
+		newErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid mode value %q, allowed values: %s, %s", mode, searchModeSubstring, searchModeFuzzy))
+		return
+	}
+
+	sort, err := parseMovieSort(r)
+	if err != nil {
+		newSortErrorResponse(w, err)
+		return
+	}
+
 	var movies []model.MovieWithActors
-	var err error
 
 	if title != "" {
-		movies, err = h.services.Movie.GetMoviesByTitle(title)
+		movies, err = h.services.Movie.GetMoviesByTitle(title, sort)
 	} else if actor != "" {
-		movies, err = h.services.Movie.GetMoviesByActor(actor)
+		movies, err = h.services.Movie.GetMoviesByActor(actor, sort)
 	}
 
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(w, err, "Failed to search movies")
 		return
 	}
 
@@ -221,70 +270,272 @@ func (h *Handler) searchMovie(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(movies)
 }
 
-// updateMovie обновляет информацию о фильме.
-// @Summary Обновить информацию о фильме
-// @Description Обновляет информацию о фильме.
+// searchMovieFullText выполняет полнотекстовый поиск фильмов по названию и описанию.
+func (h *Handler) searchMovieFullText(w http.ResponseWriter, r *http.Request, q string) {
+	page, err := parsePagination(r)
+	if err != nil {
+		newBadRequestResponse(w, err)
+		return
+	}
+
+	if page.After != nil {
+		newErrorResponse(w, http.StatusBadRequest, "after is not supported by full-text search")
+		return
+	}
+
+	hits, err := h.services.Movie.SearchMovies(q, page.Limit, page.Offset)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to search movies")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":          userID,
+		"q":                q,
+		"num_movies_found": len(hits),
+	}).Info("Movies full-text search successful")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hits)
+}
+
+// searchMovieByActorFuzzy ищет фильмы по имени актера с учетом опечаток.
+// Сортировка не применяется: фильмы упорядочены по степени сходства имени актера с запросом.
+func (h *Handler) searchMovieByActorFuzzy(w http.ResponseWriter, r *http.Request, actor string) {
+	movies, err := h.services.Movie.GetMoviesByActorFuzzy(actor)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to search movies")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":          userID,
+		"actor":            actor,
+		"num_movies_found": len(movies),
+	}).Info("Movies fuzzy search successful")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movies)
+}
+
+// updateMovie заменяет фильм целиком.
+// @Summary Заменить фильм
+// @Description Заменяет все поля фильма и его список актеров данными из запроса. Поля, которые не указаны, получают пустые значения,
+// @Description а актеры, которых нет в списке actors, удаляются из фильма. Актеры из списка, которых еще нет, создаются.
 // @Tags /api/movie/{id}
 // @Accept json
 // @Produce json
 // @Param id path int true "Идентификатор фильма"
 // @Param movie body model.InputMovie true "Новые данные о фильме"
-// @Success 200 "Фильм обновлен успешно"
+// @Param If-Match header string false "ETag фильма, полученный при его чтении: изменение выполняется, только если фильм с тех пор не изменился"
+// @Success 200 {object} model.MovieWithActors "Обновленный фильм"
+// @Header 200 {string} ETag "Версия обновленного фильма"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Фильм не найден"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 412 {object} ErrorResponse "Фильм изменился после чтения, ETag не соответствует заголовку If-Match"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/movie/{id} [put]
 // @Security ApiKeyAuth
 func (h *Handler) updateMovie(w http.ResponseWriter, r *http.Request) {
-	role, err := getUserRole(r)
+	movieID, err := pathInt(r, "id")
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, "failed to get user role")
+		newErrorResponse(w, http.StatusBadRequest, "Invalid movie ID")
 		return
 	}
 
-	if role != "admin" {
-		newErrorResponse(w, http.StatusForbidden, "only admin can update movies")
+	var input model.InputMovie
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	path := strings.TrimSuffix(r.URL.Path, "/")
-	parts := strings.Split(path, "/")
+	ifMatch, err := ifMatchVersions(r)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to update movie")
+		return
+	}
 
-	if len(parts) != 3 || parts[1] != "movie" {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	movie, err := h.services.UpdateMovie(movieID, input, ifMatch)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to update movie")
 		return
 	}
 
-	movieID, err := strconv.Atoi(parts[2])
-	if err != nil || movieID < 0 {
+	userID, _ := getUserID(r)
+
+	logEntry := logrus.WithFields(logrus.Fields{
+		"movie_id":   movieID,
+		"updated_by": userID,
+	})
+	logEntry.Info("Movie updated successfully")
+
+	w.Header().Set("ETag", etag(movie.Version))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie)
+}
+
+// patchMovie изменяет отдельные поля фильма.
+// @Summary Изменить фильм
+// @Description Изменяет фильм по документу JSON Merge Patch (RFC 7396): поля, которые не указаны, не изменяются,
+// @Description а поле со значением null удаляется - описание очищается, рейтинг становится равным 0, а название и дату выхода удалить нельзя.
+// @Description Список актеров изменяется эндпоинтами /api/movie/{id}/actors/{actorId}.
+// @Tags /api/movie/{id}
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор фильма"
+// @Param movie body model.PatchMovieInput true "Изменения фильма"
+// @Param If-Match header string false "ETag фильма, полученный при его чтении: изменение выполняется, только если фильм с тех пор не изменился"
+// @Success 200 {object} model.MovieWithActors "Обновленный фильм"
+// @Header 200 {string} ETag "Версия обновленного фильма"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Фильм не найден"
+// @Failure 412 {object} ErrorResponse "Фильм изменился после чтения, ETag не соответствует заголовку If-Match"
+// @Failure 415 {object} ErrorResponse "Тело запроса не является документом JSON Merge Patch"
+// @Failure 422 {object} ErrorResponse "Данные не прошли проверку, в поле errors перечислены некорректные поля"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/movie/{id} [patch]
+// @Security ApiKeyAuth
+func (h *Handler) patchMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, err := pathInt(r, "id")
+	if err != nil {
 		newErrorResponse(w, http.StatusBadRequest, "Invalid movie ID")
 		return
 	}
 
-	var input model.InputMovie
+	if !isMergePatch(r) {
+		w.Header().Set("Accept-Patch", mergePatchContentType)
+		newErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be "+mergePatchContentType)
+		return
+	}
+
+	var input model.PatchMovieInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = h.services.UpdateMovie(movieID, input)
+	ifMatch, err := ifMatchVersions(r)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(w, err, "Failed to update movie")
+		return
+	}
+
+	movie, err := h.services.PatchMovie(movieID, input, ifMatch)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to update movie")
 		return
 	}
 
 	userID, _ := getUserID(r)
 
-	logEntry := logrus.WithFields(logrus.Fields{
+	logrus.WithFields(logrus.Fields{
 		"movie_id":   movieID,
 		"updated_by": userID,
-	})
-	logEntry.Info("Movie updated successfully")
+	}).Info("Movie patched successfully")
+
+	w.Header().Set("ETag", etag(movie.Version))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie)
+}
+
+// addMovieActor добавляет актера в фильм.
+// @Summary Добавить актера в фильм
+// @Description Добавляет существующего актера в список актеров фильма. Повторное добавление не изменяет фильм.
+// @Tags /api/movie/{id}
+// @Param id path int true "Идентификатор фильма"
+// @Param actorId path int true "Идентификатор актера"
+// @Success 204 "Актер добавлен в фильм"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Фильм или актер не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/movie/{id}/actors/{actorId} [post]
+// @Security ApiKeyAuth
+func (h *Handler) addMovieActor(w http.ResponseWriter, r *http.Request) {
+	movieID, actorID, ok := movieActorIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Movie.AddActor(movieID, actorID); err != nil {
+		newServiceErrorResponse(w, err, "Failed to add actor to movie")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"movie_id":   movieID,
+		"actor_id":   actorID,
+		"updated_by": userID,
+	}).Info("Actor added to movie")
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("movie updated successfully"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeMovieActor удаляет актера из фильма.
+// @Summary Удалить актера из фильма
+// @Description Удаляет актера из списка актеров фильма. Сам актер не удаляется.
+// @Tags /api/movie/{id}
+// @Param id path int true "Идентификатор фильма"
+// @Param actorId path int true "Идентификатор актера"
+// @Success 204 "Актер удален из фильма"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Актера нет в фильме"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/movie/{id}/actors/{actorId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) removeMovieActor(w http.ResponseWriter, r *http.Request) {
+	movieID, actorID, ok := movieActorIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Movie.RemoveActor(movieID, actorID); err != nil {
+		newServiceErrorResponse(w, err, "Failed to remove actor from movie")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"movie_id":   movieID,
+		"actor_id":   actorID,
+		"updated_by": userID,
+	}).Info("Actor removed from movie")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// movieActorIDs извлекает из пути идентификаторы фильма и актера. Если идентификатор некорректен,
+// отправляет клиенту ответ 400 и возвращает ok, равное false.
+func movieActorIDs(w http.ResponseWriter, r *http.Request) (movieID, actorID int, ok bool) {
+	movieID, err := pathInt(r, "id")
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid movie ID")
+		return 0, 0, false
+	}
+
+	actorID, err = pathInt(r, "actorId")
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid actor ID")
+		return 0, 0, false
+	}
+
+	return movieID, actorID, true
 }
 
 // getMovie возвращает информацию о фильме по его идентификатору.
@@ -293,24 +544,20 @@ func (h *Handler) updateMovie(w http.ResponseWriter, r *http.Request) {
 // @Tags /api/movie/{id}
 // @Produce json
 // @Param id path int true "Идентификатор фильма"
+// @Param If-None-Match header string false "ETag имеющейся у клиента копии фильма"
 // @Success 200 {object} model.MovieWithActors "Информация о фильме"
+// @Header 200 {string} ETag "Версия фильма"
+// @Success 304 "Фильм не изменился, у клиента актуальная копия"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 404 {object} ErrorResponse "Фильм не найден"
 // @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
-// @Failure 403 {object} ErrorResponse "Некорректная роль"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
 // @Failure 405 {object} ErrorResponse "Некорректный метод"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/movie/{id} [get]
 // @Security ApiKeyAuth
 func (h *Handler) getMovie(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimSuffix(r.URL.Path, "/")
-	parts := strings.Split(path, "/")
-
-	if len(parts) != 3 || parts[1] != "movie" {
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	movieID, err := strconv.Atoi(parts[2])
+	movieID, err := pathInt(r, "id")
 	if err != nil {
 		newErrorResponse(w, http.StatusBadRequest, "Invalid movie ID")
 		return
@@ -318,7 +565,7 @@ func (h *Handler) getMovie(w http.ResponseWriter, r *http.Request) {
 
 	movie, err := h.services.Movie.GetMovieByID(movieID)
 	if err != nil {
-		newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(w, err, "Failed to get movie")
 		return
 	}
 
@@ -329,6 +576,10 @@ func (h *Handler) getMovie(w http.ResponseWriter, r *http.Request) {
 	})
 	logEntry.Info("Getting movie information")
 
+	if conditionalGet(w, r, movie.Version) {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 
 	err = json.NewEncoder(w).Encode(movie)
@@ -337,18 +588,3 @@ func (h *Handler) getMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
-
-func (h *Handler) movieHandle(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		h.createMovie(w, r)
-	case http.MethodDelete:
-		h.deleteMovie(w, r)
-	case http.MethodPut:
-		h.updateMovie(w, r)
-	case http.MethodGet:
-		h.getMovie(w, r)
-	default:
-		newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
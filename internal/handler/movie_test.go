@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -37,7 +36,9 @@ func TestHandler_getAllMovies_NoSorting(t *testing.T) {
 		{ID: 2, Title: "Movie 2", Description: "Description 2", ReleaseDate: "2022-01-02", Rating: 79},
 	}
 
-	mockMovieService.EXPECT().GetAllMovies("rating", "desc").Return(expectedMovies, nil)
+	expectedSort := model.Sort{{Field: "rating", Desc: true}}
+	expectedPage := model.Pagination{Limit: defaultPageLimit}
+	mockMovieService.EXPECT().GetAllMovies(model.MovieFilter{}, expectedSort, expectedPage).Return(model.MoviesPage{Movies: expectedMovies, Total: 2}, nil)
 
 	handler.getAllMovies(w, req)
 
@@ -45,12 +46,18 @@ func TestHandler_getAllMovies_NoSorting(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 
-	var responseMovies []model.Movie
-	err := json.NewDecoder(w.Body).Decode(&responseMovies)
+	var response model.MoviesPage
+	err := json.NewDecoder(w.Body).Decode(&response)
 	if err != nil {
 		t.Errorf("Error decoding response body: %v", err)
 	}
 
+	if response.Total != 2 {
+		t.Errorf("Expected total %d, got %d", 2, response.Total)
+	}
+
+	responseMovies := response.Movies
+
 	if len(responseMovies) != len(expectedMovies) {
 		t.Errorf("Expected %d movies, got %d", len(expectedMovies), len(responseMovies))
 	}
@@ -76,7 +83,7 @@ func TestHandler_getAllMovies_ServiceError(t *testing.T) {
 	req := httptest.NewRequest("GET", "/api/movies", nil)
 	w := httptest.NewRecorder()
 
-	mockMovieService.EXPECT().GetAllMovies("rating", "desc").Return(nil, errors.New("service error"))
+	mockMovieService.EXPECT().GetAllMovies(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.MoviesPage{}, errors.New("service error"))
 
 	handler.getAllMovies(w, req)
 
@@ -84,14 +91,234 @@ func TestHandler_getAllMovies_ServiceError(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedError := "Failed to get movies"
-	var response map[string]interface{}
-	err := json.NewDecoder(w.Body).Decode(&response)
-	if err != nil {
+	assertProblem(t, w, "internal_error", "Failed to get movies")
+}
+
+func TestHandler_getAllMovies_MultiKeySorting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movies?sort_by=rating,-release_date", nil)
+	w := httptest.NewRecorder()
+
+	expectedSort := model.Sort{{Field: "rating"}, {Field: "release_date", Desc: true}}
+	mockMovieService.EXPECT().GetAllMovies(gomock.Any(), expectedSort, gomock.Any()).Return(model.MoviesPage{}, nil)
+
+	handler.getAllMovies(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestHandler_getAllMovies_InvalidSorting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	tests := map[string][]string{
+		"sort_by=rating,,title":                 model.MovieSortFields,
+		"sort_by=id%3BDROP%20TABLE%20movie":     model.MovieSortFields,
+		"sort_by=name":                          model.MovieSortFields,
+		"sort_by=title&sort_order=up":           model.SortOrders,
+		"sort_by=title&sort_order=desc%2C%20id": model.SortOrders,
+	}
+
+	for query, allowed := range tests {
+		req := httptest.NewRequest("GET", "/api/movies?"+query, nil)
+		w := httptest.NewRecorder()
+
+		handler.getAllMovies(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, w.Code)
+		}
+
+		var response ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Errorf("%s: error decoding response body: %v", query, err)
+		}
+
+		if strings.Join(response.Allowed, ",") != strings.Join(allowed, ",") {
+			t.Errorf("%s: expected allowed values %v, got %v", query, allowed, response.Allowed)
+		}
+	}
+}
+
+func TestHandler_getAllMovies_Filtering(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movies?title=star&min_rating=0&max_rating=8&released_after=1990-1-1&released_before=2005-12-31&actor_id=3&actor_id=7", nil)
+	w := httptest.NewRecorder()
+
+	minRating, maxRating := 0, 8
+	expectedFilter := model.MovieFilter{
+		Title:          "star",
+		MinRating:      &minRating,
+		MaxRating:      &maxRating,
+		ReleasedAfter:  "1990-01-01",
+		ReleasedBefore: "2005-12-31",
+		ActorIDs:       []int{3, 7},
+	}
+	mockMovieService.EXPECT().GetAllMovies(expectedFilter, gomock.Any(), gomock.Any()).Return(model.MoviesPage{}, nil)
+
+	handler.getAllMovies(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestHandler_getAllMovies_InvalidFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	for _, query := range []string{
+		"min_rating=high",
+		"max_rating=11",
+		"min_rating=8&max_rating=5",
+		"released_after=yesterday",
+		"released_after=2010-01-01&released_before=2000-01-01",
+		"actor_id=1&actor_id=x",
+	} {
+		req := httptest.NewRequest("GET", "/api/movies?"+query, nil)
+		w := httptest.NewRecorder()
+
+		handler.getAllMovies(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestHandler_getAllMovies_InvalidCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The cursor of another sort is rejected by the repository, a malformed one already while parsing.
+	cursor := model.Cursor{Sort: "title", Values: []string{"Matrix"}, ID: 3}
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().GetAllMovies(gomock.Any(), gomock.Any(), model.Pagination{Limit: defaultPageLimit, After: &cursor}).
+		Return(model.MoviesPage{}, model.ErrInvalidCursor)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	for _, after := range []string{cursor.Encode(), "not_a_cursor"} {
+		req := httptest.NewRequest("GET", "/api/movies?sort_by=rating&after="+after, nil)
+		w := httptest.NewRecorder()
+
+		handler.getAllMovies(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", after, http.StatusBadRequest, w.Code)
+		}
+		assertProblem(t, w, "invalid_cursor", model.ErrInvalidCursor.Error())
+	}
+}
+
+func TestHandler_getAllMovies_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	cursor := model.Cursor{Values: []string{"8"}, ID: 12}
+
+	req := httptest.NewRequest("GET", "/api/movies?limit=1&after="+cursor.Encode(), nil)
+	w := httptest.NewRecorder()
+
+	nextCursor := model.Cursor{Values: []string{"7"}, ID: 3}.Encode()
+	expectedPage := model.Pagination{Limit: 1, After: &cursor}
+	mockMovieService.EXPECT().GetAllMovies(gomock.Any(), gomock.Any(), expectedPage).Return(model.MoviesPage{
+		Movies:     []model.MovieWithActors{{ID: 3, Title: "Movie 3", Rating: 7}},
+		NextCursor: nextCursor,
+		Total:      5,
+	}, nil)
+
+	handler.getAllMovies(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response model.MoviesPage
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
 		t.Errorf("Error decoding response body: %v", err)
 	}
-	if errorMessage, ok := response["message"]; !ok || errorMessage != expectedError {
-		t.Errorf("Expected error message %q, got %q", expectedError, errorMessage)
+
+	if response.NextCursor != nextCursor {
+		t.Errorf("Expected next cursor %q, got %q", nextCursor, response.NextCursor)
+	}
+
+	if response.Total != 5 || len(response.Movies) != 1 {
+		t.Errorf("Expected 1 movie of 5, got %d of %d", len(response.Movies), response.Total)
+	}
+}
+
+func TestHandler_getAllMovies_InvalidPagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	for _, query := range []string{"limit=0", "limit=abc", "limit=1000", "offset=-1", "after=not_a_cursor", "offset=2&after=" + model.Cursor{ID: 1}.Encode()} {
+		req := httptest.NewRequest("GET", "/api/movies?"+query, nil)
+		w := httptest.NewRecorder()
+
+		handler.getAllMovies(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, w.Code)
+		}
 	}
 }
 
@@ -100,7 +327,13 @@ func TestHandler_createMovie(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockMovieService := mock_service.NewMockMovie(ctrl)
-	mockMovieService.EXPECT().CreateMovie(gomock.Any()).Return(nil)
+	created := model.MovieWithActors{
+		ID:          7,
+		Title:       "Test Movie",
+		ReleaseDate: "2003-09-02",
+		Actors:      []model.Actor{{ID: 3, Name: "Actor 1", Gender: "female", BirthDate: "2003-09-02"}},
+	}
+	mockMovieService.EXPECT().CreateMovie(gomock.Any()).Return(created, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -110,8 +343,7 @@ func TestHandler_createMovie(t *testing.T) {
 
 	reqBody := `{"title":"Test Movie", "actors":[{"name":"Actor 1", "gender":"female", "birth_date":"2003-9-2"}]}`
 	req := httptest.NewRequest("POST", "/api/movie", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin") // Устанавливаем роль в контексте
-	req = req.WithContext(ctx)
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.createMovie(w, req)
@@ -120,9 +352,17 @@ func TestHandler_createMovie(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
 	}
 
-	expectedResponse := "movie created successfully"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	if location := w.Header().Get("Location"); location != "/api/movie/7" {
+		t.Errorf("Expected Location %q, got %q", "/api/movie/7", location)
+	}
+
+	var response model.MovieWithActors
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response body: %v", err)
+	}
+
+	if response.ID != created.ID || response.Title != created.Title || len(response.Actors) != 1 {
+		t.Errorf("Expected created movie %+v, got %+v", created, response)
 	}
 }
 
@@ -131,7 +371,7 @@ func TestHandler_createMovie_UnsuccessfulCreation(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockMovieService := mock_service.NewMockMovie(ctrl)
-	mockMovieService.EXPECT().CreateMovie(gomock.Any()).Return(errors.New("failed to create movie"))
+	mockMovieService.EXPECT().CreateMovie(gomock.Any()).Return(model.MovieWithActors{}, errors.New("failed to create movie"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -141,8 +381,7 @@ func TestHandler_createMovie_UnsuccessfulCreation(t *testing.T) {
 
 	reqBody := `{"title":"Test Movie", "actors":[{"name":"Actor 1", "gender":"female", "birth_date":"2003-9-2"}]}`
 	req := httptest.NewRequest("POST", "/api/movie", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin") // Устанавливаем роль в контексте
-	req = req.WithContext(ctx)
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.createMovie(w, req)
@@ -151,15 +390,7 @@ func TestHandler_createMovie_UnsuccessfulCreation(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	var response map[string]string
-	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-		t.Errorf("Failed to unmarshal response body: %v", err)
-	}
-
-	expectedResponse := "failed to create movie"
-	if response["message"] != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, response["message"])
-	}
+	assertProblem(t, w, "internal_error", "Failed to create movie")
 }
 
 func TestHandler_createMovie_ForbiddenRole(t *testing.T) {
@@ -176,17 +407,16 @@ func TestHandler_createMovie_ForbiddenRole(t *testing.T) {
 
 	reqBody := `{"title":"Test Movie", "actors":[{"name":"Actor 1", "gender":"female", "birth_date":"2003-9-2"}]}`
 	req := httptest.NewRequest("POST", "/api/movie", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "user") // Устанавливаем роль пользователя в контексте
-	req = req.WithContext(ctx)
+	req = withRouteIdentity(ctrl, handler, req, "user")
 	w := httptest.NewRecorder()
 
-	handler.createMovie(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusForbidden {
 		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
 	}
 
-	expectedResponse := "only admin can create movies"
+	expectedResponse := "permission movie:write is required"
 	if !strings.Contains(w.Body.String(), expectedResponse) {
 		t.Errorf("Expected response body to contain %q, got %q", expectedResponse, w.Body.String())
 	}
@@ -197,7 +427,7 @@ func TestHandler_deleteMovie(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockMovieService := mock_service.NewMockMovie(ctrl)
-	mockMovieService.EXPECT().DeleteByID(1).Return(nil)
+	mockMovieService.EXPECT().DeleteByID(1, gomock.Nil()).Return(nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -205,9 +435,9 @@ func TestHandler_deleteMovie(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/movie/1", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/movie/1", nil)
+	req.SetPathValue("id", "1")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.deleteMovie(w, req)
@@ -216,7 +446,7 @@ func TestHandler_deleteMovie(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
 	}
 
-	expectedResponse := "movie deleted successfully"
+	expectedResponse := `{"message":"movie deleted successfully"}`
 	if w.Body.String() != expectedResponse {
 		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
 	}
@@ -227,7 +457,7 @@ func TestHandler_deleteMovie_Unsuccessful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockMovieService := mock_service.NewMockMovie(ctrl)
-	mockMovieService.EXPECT().DeleteByID(1).Return(errors.New("Failed to delete movie by ID"))
+	mockMovieService.EXPECT().DeleteByID(1, gomock.Nil()).Return(errors.New("Failed to delete movie by ID"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -235,9 +465,9 @@ func TestHandler_deleteMovie_Unsuccessful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/movie/1", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/movie/1", nil)
+	req.SetPathValue("id", "1")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.deleteMovie(w, req)
@@ -246,11 +476,7 @@ func TestHandler_deleteMovie_Unsuccessful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Failed to delete movie by ID\"}"
-	actualResponse := strings.Trim(w.Body.String(), `"`)
-	if actualResponse != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, actualResponse)
-	}
+	assertProblem(t, w, "internal_error", "Failed to delete movie by ID")
 }
 
 func TestHandler_deleteMovie_NotAdmin(t *testing.T) {
@@ -265,21 +491,17 @@ func TestHandler_deleteMovie_NotAdmin(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/movie/1", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "user")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/movie/1", nil)
+	req = withRouteIdentity(ctrl, handler, req, "user")
 	w := httptest.NewRecorder()
 
-	handler.deleteMovie(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusForbidden {
 		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"only admin can delete movies\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "forbidden", "permission movie:delete is required")
 }
 
 func TestHandler_deleteMovie_InvalidMovieID(t *testing.T) {
@@ -294,21 +516,17 @@ func TestHandler_deleteMovie_InvalidMovieID(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("DELETE", "/movie/invalid_id", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("DELETE", "/api/movie/invalid_id", nil)
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
-	handler.movieHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Invalid movie ID\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "bad_request", "Invalid movie ID")
 }
 
 func TestHandler_updateMovie(t *testing.T) {
@@ -316,7 +534,8 @@ func TestHandler_updateMovie(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockMovieService := mock_service.NewMockMovie(ctrl)
-	mockMovieService.EXPECT().UpdateMovie(gomock.Any(), gomock.Any()).Return(nil)
+	updated := model.MovieWithActors{ID: 1, Title: "Updated Movie", Actors: []model.Actor{{Name: "Actor 1", Gender: "female", BirthDate: "2003-09-02"}}}
+	mockMovieService.EXPECT().UpdateMovie(1, gomock.Any(), gomock.Nil()).Return(updated, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -325,20 +544,22 @@ func TestHandler_updateMovie(t *testing.T) {
 	}
 
 	reqBody := `{"title":"Updated Movie", "actors":[{"name":"Actor 1", "gender":"female", "birth_date":"2003-9-2"}]}`
-	req := httptest.NewRequest("PUT", "/movie/1", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("PUT", "/api/movie/1", strings.NewReader(reqBody))
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
-	handler.movieHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 
-	expectedResponse := "movie updated successfully"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	var movie model.MovieWithActors
+	if err := json.NewDecoder(w.Body).Decode(&movie); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if movie.ID != 1 || len(movie.Actors) != 1 || movie.Actors[0].Name != "Actor 1" {
+		t.Errorf("Expected the updated movie %+v, got %+v", updated, movie)
 	}
 }
 
@@ -347,7 +568,7 @@ func TestHandler_updateMovie_Unsuccessful(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockMovieService := mock_service.NewMockMovie(ctrl)
-	mockMovieService.EXPECT().UpdateMovie(gomock.Any(), gomock.Any()).Return(errors.New("Failed to update movie"))
+	mockMovieService.EXPECT().UpdateMovie(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.MovieWithActors{}, errors.New("Failed to update movie"))
 
 	handler := &Handler{
 		services: &service.Service{
@@ -356,9 +577,9 @@ func TestHandler_updateMovie_Unsuccessful(t *testing.T) {
 	}
 
 	reqBody := `{"title":"Updated Movie", "actors":[{"name":"Actor 1", "gender":"female", "birth_date":"2003-9-2"}]}`
-	req := httptest.NewRequest("PUT", "/movie/1", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("PUT", "/api/movie/1", strings.NewReader(reqBody))
+	req.SetPathValue("id", "1")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.updateMovie(w, req)
@@ -367,12 +588,267 @@ func TestHandler_updateMovie_Unsuccessful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Failed to update movie\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	assertProblem(t, w, "internal_error", "Failed to update movie")
+}
+
+func TestHandler_patchMovie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+
+	title := "Patched Movie"
+	expectedInput := model.PatchMovieInput{Title: &title, Description: new(string)}
+	patched := model.MovieWithActors{ID: 1, Title: title, ReleaseDate: "1999-03-31", Rating: 9}
+	mockMovieService.EXPECT().PatchMovie(1, expectedInput, gomock.Nil()).Return(patched, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	// A null description removes it; the fields that are not mentioned stay unchanged.
+	reqBody := `{"title":"Patched Movie","description":null}`
+	req := httptest.NewRequest("PATCH", "/api/movie/1", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var movie model.MovieWithActors
+	if err := json.NewDecoder(w.Body).Decode(&movie); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if movie.Title != title || movie.Rating != 9 {
+		t.Errorf("Expected the patched movie %+v, got %+v", patched, movie)
+	}
+}
+
+func TestHandler_patchMovie_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		serviceErr   error
+		expectedCode int
+		expectedType string
+	}{
+		{
+			name:         "unknown field",
+			contentType:  "application/merge-patch+json",
+			body:         `{"actors":[]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedType: "bad_request",
+		},
+		{
+			name:         "malformed document",
+			contentType:  "application/json; charset=utf-8",
+			body:         `{"title":`,
+			expectedCode: http.StatusBadRequest,
+			expectedType: "bad_request",
+		},
+		{
+			name:         "unsupported content type",
+			contentType:  "text/plain",
+			body:         `{"title":"Matrix"}`,
+			expectedCode: http.StatusUnsupportedMediaType,
+			expectedType: "unsupported_media_type",
+		},
+		{
+			name:         "invalid field",
+			contentType:  "application/merge-patch+json",
+			body:         `{"title":null}`,
+			serviceErr:   (&model.ValidationError{Fields: []model.FieldError{{Field: "title", Message: "must be 1 to 150 characters long"}}}).Err(),
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedType: "validation_failed",
+		},
+		{
+			name:         "movie not found",
+			contentType:  "application/merge-patch+json",
+			body:         `{"rating":5}`,
+			serviceErr:   service.ErrMovieNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedType: "movie_not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMovieService := mock_service.NewMockMovie(ctrl)
+			if tt.serviceErr != nil {
+				mockMovieService.EXPECT().PatchMovie(1, gomock.Any(), gomock.Any()).Return(model.MovieWithActors{}, tt.serviceErr)
+			}
+
+			handler := &Handler{
+				services: &service.Service{
+					Movie: mockMovieService,
+				},
+			}
+
+			req := httptest.NewRequest("PATCH", "/api/movie/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.SetPathValue("id", "1")
+			req = withIdentity(req, "admin", adminPermissions...)
+			w := httptest.NewRecorder()
+
+			handler.patchMovie(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if problem := decodeProblem(t, w); problem.Code != tt.expectedType {
+				t.Errorf("Expected error code %q, got %q", tt.expectedType, problem.Code)
+			}
+
+			if tt.expectedCode == http.StatusUnsupportedMediaType {
+				if accept := w.Header().Get("Accept-Patch"); accept != "application/merge-patch+json" {
+					t.Errorf("Expected Accept-Patch header %q, got %q", "application/merge-patch+json", accept)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_addMovieActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().AddActor(1, 2).Return(nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/api/movie/1/actors/2", nil)
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected empty response body, got %q", w.Body.String())
+	}
+}
+
+func TestHandler_addMovieActor_ActorNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().AddActor(1, 2).Return(service.ErrActorNotFound)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/api/movie/1/actors/2", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("actorId", "2")
+	w := httptest.NewRecorder()
+
+	handler.addMovieActor(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if problem := decodeProblem(t, w); problem.Code != "actor_not_found" {
+		t.Errorf("Expected error code %q, got %q", "actor_not_found", problem.Code)
+	}
+}
+
+func TestHandler_removeMovieActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().RemoveActor(1, 2).Return(nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/movie/1/actors/2", nil)
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
 	}
 }
 
+func TestHandler_removeMovieActor_NotInCast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().RemoveActor(1, 2).Return(service.ErrMovieActorNotFound)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/movie/1/actors/2", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("actorId", "2")
+	w := httptest.NewRecorder()
+
+	handler.removeMovieActor(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	assertProblem(t, w, "movie_actor_not_found", "actor is not in the cast of the movie")
+}
+
+func TestHandler_removeMovieActor_InvalidActorID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mock_service.NewMockMovie(ctrl),
+		},
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/movie/1/actors/invalid_id", nil)
+	req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	assertProblem(t, w, "bad_request", "Invalid actor ID")
+}
+
 func TestHandler_getMovie_Unsuccessful(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -386,7 +862,8 @@ func TestHandler_getMovie_Unsuccessful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("GET", "/movie/1", nil)
+	req := httptest.NewRequest("GET", "/api/movie/1", nil)
+	req.SetPathValue("id", "1")
 	w := httptest.NewRecorder()
 
 	handler.getMovie(w, req)
@@ -395,10 +872,7 @@ func TestHandler_getMovie_Unsuccessful(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Failed to get movie\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
-	}
+	assertProblem(t, w, "internal_error", "Failed to get movie")
 }
 
 func TestHandler_getMovie_Successful(t *testing.T) {
@@ -419,10 +893,11 @@ func TestHandler_getMovie_Successful(t *testing.T) {
 		},
 	}
 
-	req := httptest.NewRequest("GET", "/movie/1", nil)
+	req := httptest.NewRequest("GET", "/api/movie/1", nil)
+	req = withRouteIdentity(ctrl, handler, req, "user")
 	w := httptest.NewRecorder()
 
-	handler.movieHandle(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
@@ -438,6 +913,91 @@ func TestHandler_getMovie_Successful(t *testing.T) {
 	}
 }
 
+func TestHandler_getMovie_NotModified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().GetMovieByID(1).Return(model.MovieWithActors{ID: 1, Title: "Test Movie", Version: 3}, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movie/1", nil)
+	req.Header.Set("If-None-Match", `"3"`)
+	req = withRouteIdentity(ctrl, handler, req, "user")
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotModified, w.Code)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf("Expected ETag %q, got %q", `"3"`, etag)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected empty response body, got %q", w.Body.String())
+	}
+}
+
+func TestHandler_updateMovie_IfMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		ifMatch      string
+		serviceErr   error
+		expectedCode int
+	}{
+		{name: "current version", ifMatch: `"3"`, expectedCode: http.StatusOK},
+		{name: "modified since read", ifMatch: `"3"`, serviceErr: service.ErrVersionMismatch, expectedCode: http.StatusPreconditionFailed},
+		{name: "foreign tag", ifMatch: `W/"3"`, expectedCode: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMovieService := mock_service.NewMockMovie(ctrl)
+			if tt.ifMatch == `"3"` {
+				mockMovieService.EXPECT().UpdateMovie(1, gomock.Any(), model.Versions{3}).Return(model.MovieWithActors{ID: 1, Version: 4}, tt.serviceErr)
+			}
+
+			handler := &Handler{
+				services: &service.Service{
+					Movie: mockMovieService,
+				},
+			}
+
+			reqBody := `{"title":"Updated Movie","release_date":"1999-3-31"}`
+			req := httptest.NewRequest("PUT", "/api/movie/1", strings.NewReader(reqBody))
+			req.Header.Set("If-Match", tt.ifMatch)
+			req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+			w := httptest.NewRecorder()
+
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if tt.expectedCode == http.StatusOK {
+				if etag := w.Header().Get("ETag"); etag != `"4"` {
+					t.Errorf("Expected ETag of the updated movie %q, got %q", `"4"`, etag)
+				}
+				return
+			}
+
+			if problem := decodeProblem(t, w); problem.Code != "version_mismatch" {
+				t.Errorf("Expected error code %q, got %q", "version_mismatch", problem.Code)
+			}
+		})
+	}
+}
+
 func TestHandler_searchMovie(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -463,7 +1023,7 @@ func TestHandler_searchMovie(t *testing.T) {
 			},
 		},
 	}
-	mockMovieService.EXPECT().GetMoviesByTitle("Movie").Return(expectedMovies, nil)
+	mockMovieService.EXPECT().GetMoviesByTitle("Movie", model.Sort{{Field: "rating", Desc: true}}).Return(expectedMovies, nil)
 
 	handler := &Handler{
 		services: &service.Service{
@@ -489,6 +1049,165 @@ func TestHandler_searchMovie(t *testing.T) {
 	}
 }
 
+func TestHandler_searchMovie_FullText(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	expectedHits := []model.MovieSearchHit{
+		{
+			MovieWithActors: model.MovieWithActors{ID: 4, Title: "Star Wars", Description: "A galaxy far, far away", Actors: []model.Actor{}},
+			Rank:            0.6,
+			Snippet:         "A <mark>galaxy</mark> far, far away",
+		},
+	}
+	mockMovieService.EXPECT().SearchMovies(`"far away" galax*`, 5, 0).Return(expectedHits, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movie/search?limit=5&q=%22far+away%22+galax*", nil)
+	w := httptest.NewRecorder()
+
+	handler.searchMovie(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Error decoding response body: %v", err)
+	}
+
+	if len(response) != 1 || response[0]["title"] != "Star Wars" || response[0]["snippet"] != expectedHits[0].Snippet || response[0]["rank"] != 0.6 {
+		t.Errorf("Expected hits %+v, got %+v", expectedHits, response)
+	}
+}
+
+func TestHandler_searchMovie_FullTextEmptyQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().SearchMovies("***", gomock.Any(), gomock.Any()).Return(nil, service.ErrEmptySearchQuery)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movie/search?q=***", nil)
+	w := httptest.NewRecorder()
+
+	handler.searchMovie(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	assertProblem(t, w, "empty_search_query", service.ErrEmptySearchQuery.Error())
+}
+
+func TestHandler_searchMovie_FuzzyActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	expectedMovies := []model.MovieWithActors{
+		{
+			ID:     1,
+			Title:  "Inception",
+			Actors: []model.Actor{{ID: 3, Name: "Leonardo DiCaprio"}},
+		},
+	}
+	mockMovieService.EXPECT().GetMoviesByActorFuzzy("Di Caprio").Return(expectedMovies, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movie/search?actor=Di+Caprio&mode=fuzzy", nil)
+	w := httptest.NewRecorder()
+
+	handler.searchMovie(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	expectedResponse, err := json.Marshal(expectedMovies)
+	if err != nil {
+		t.Errorf("Error marshaling expected movies: %v", err)
+	}
+	if w.Body.String() != string(expectedResponse)+"\n" {
+		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	}
+}
+
+func TestHandler_searchMovie_FuzzyActorNoMatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().GetMoviesByActorFuzzy("Zzyzx").Return([]model.MovieWithActors{}, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/api/movie/search?actor=Zzyzx&mode=fuzzy", nil)
+	w := httptest.NewRecorder()
+
+	handler.searchMovie(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != "[]\n" {
+		t.Errorf("Expected an empty list, got %q", w.Body.String())
+	}
+}
+
+func TestHandler_searchMovie_InvalidMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "fuzzy title search", query: "title=Inception&mode=fuzzy"},
+		{name: "unknown mode", query: "actor=DiCaprio&mode=exact"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := &Handler{
+				services: &service.Service{
+					Movie: mock_service.NewMockMovie(ctrl),
+				},
+			}
+
+			req := httptest.NewRequest("GET", "/api/movie/search?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.searchMovie(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
 func TestHandler_searchMovie_EmptyParams(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -546,20 +1265,19 @@ func TestHandler_updateMovie_MethodNotAllowed(t *testing.T) {
 	}
 
 	req := httptest.NewRequest("POST", "/api/movie/1", nil)
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
 	w := httptest.NewRecorder()
 
-	handler.updateMovie(w, req)
+	handler.InitRoutes().ServeHTTP(w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Method not allowed\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, PUT, PATCH, DELETE" {
+		t.Errorf("Expected Allow header %q, got %q", "GET, HEAD, PUT, PATCH, DELETE", allow)
 	}
+
+	assertProblem(t, w, "method_not_allowed", "Method not allowed")
 }
 
 func TestHandler_updateMovie_InvalidMovieID(t *testing.T) {
@@ -575,9 +1293,9 @@ func TestHandler_updateMovie_InvalidMovieID(t *testing.T) {
 	}
 
 	reqBody := `{"title":"Updated Movie", "actors":[{"name":"Actor 1", "gender":"female", "birth_date":"2003-9-2"}]}`
-	req := httptest.NewRequest("PUT", "/movie/invalid_id", strings.NewReader(reqBody))
-	ctx := context.WithValue(req.Context(), userRoleCtx, "admin")
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest("PUT", "/api/movie/invalid_id", strings.NewReader(reqBody))
+	req.SetPathValue("id", "invalid_id")
+	req = withIdentity(req, "admin", adminPermissions...)
 	w := httptest.NewRecorder()
 
 	handler.updateMovie(w, req)
@@ -586,8 +1304,67 @@ func TestHandler_updateMovie_InvalidMovieID(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 
-	expectedResponse := "{\"message\":\"Invalid movie ID\"}"
-	if w.Body.String() != expectedResponse {
-		t.Errorf("Expected response body %q, got %q", expectedResponse, w.Body.String())
+	assertProblem(t, w, "bad_request", "Invalid movie ID")
+}
+
+func TestHandler_getAllMovies_PreferredSorting(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		preferences  *model.Preferences
+		expectedSort model.Sort
+	}{
+		{
+			name:         "preferred sort",
+			preferences:  &model.Preferences{MovieSort: "title,-release_date"},
+			expectedSort: model.Sort{{Field: "title"}, {Field: "release_date", Desc: true}},
+		},
+		{
+			name:         "preferred sort with sort_order",
+			query:        "?sort_order=desc",
+			preferences:  &model.Preferences{MovieSort: "title"},
+			expectedSort: model.Sort{{Field: "title", Desc: true}},
+		},
+		{
+			name:         "no preferred sort",
+			preferences:  &model.Preferences{},
+			expectedSort: model.Sort{{Field: "rating", Desc: true}},
+		},
+		{
+			name:         "explicit sort_by",
+			query:        "?sort_by=release_date",
+			expectedSort: model.Sort{{Field: "release_date"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMovieService := mock_service.NewMockMovie(ctrl)
+			mockProfileService := mock_service.NewMockProfile(ctrl)
+
+			handler := &Handler{
+				services: &service.Service{
+					Movie:   mockMovieService,
+					Profile: mockProfileService,
+				},
+			}
+
+			if tt.preferences != nil {
+				mockProfileService.EXPECT().GetPreferences(1).Return(*tt.preferences, nil)
+			}
+			mockMovieService.EXPECT().GetAllMovies(gomock.Any(), tt.expectedSort, gomock.Any()).Return(model.MoviesPage{}, nil)
+
+			req := withIdentity(httptest.NewRequest("GET", "/api/movies"+tt.query, nil), "user")
+			w := httptest.NewRecorder()
+
+			handler.getAllMovies(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+		})
 	}
 }
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/avealice/filmhub/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+// oidcLogin перенаправляет пользователя на страницу входа внешнего поставщика удостоверений.
+//
+// @Summary Вход через поставщика удостоверений
+// @Description Начинает вход по протоколу OpenID Connect (authorization code flow) и перенаправляет пользователя на страницу входа поставщика.
+// @Description После входа поставщик возвращает пользователя на /auth/oidc/callback. Доступно, если поставщик задан в разделе oidc файла конфигурации.
+// @Tags /auth/
+// @Success 302 "Перенаправление на страницу входа поставщика"
+// @Failure 404 {object} ErrorResponse "Вход через поставщика не настроен"
+// @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 502 {object} ErrorResponse "Поставщик удостоверений недоступен"
+// @Router /auth/oidc/login [get]
+func (h *Handler) oidcLogin(w http.ResponseWriter, r *http.Request) {
+	if h.services.OIDC == nil {
+		newErrorResponse(w, http.StatusNotFound, "OIDC sign-in is not configured")
+		return
+	}
+
+	authURL, err := h.services.OIDC.AuthCodeURL()
+	if err != nil {
+		newServiceErrorResponse(w, err, service.ErrOIDCProvider.Error())
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// oidcCallback завершает вход через внешнего поставщика удостоверений.
+//
+// @Summary Завершение входа через поставщика удостоверений
+// @Description Принимает код авторизации от поставщика, проверяет токен ID и выдает токены FilmHub.
+// @Description При первом входе создается пользователь с именем из токена ID, роль пользователя при каждом входе определяется его группами у поставщика.
+// @Tags /auth/
+// @Produce json
+// @Param code query string true "Код авторизации"
+// @Param state query string true "Состояние, выданное при начале входа"
+// @Success 200 {object} TokenResponse "Токен доступа и токен обновления"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос, недействительное или истекшее состояние входа"
+// @Failure 401 {object} ErrorResponse "Поставщик отклонил вход или токен ID недействителен"
+// @Failure 403 {object} ErrorResponse "Учетная запись заблокирована"
+// @Failure 404 {object} ErrorResponse "Вход через поставщика не настроен"
+// @Failure 409 {object} ErrorResponse "Имя пользователя занято локальной учетной записью"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 502 {object} ErrorResponse "Поставщик удостоверений недоступен"
+// @Router /auth/oidc/callback [get]
+func (h *Handler) oidcCallback(w http.ResponseWriter, r *http.Request) {
+	if h.services.OIDC == nil {
+		newErrorResponse(w, http.StatusNotFound, "OIDC sign-in is not configured")
+		return
+	}
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		logrus.WithFields(logrus.Fields{
+			"error":       providerErr,
+			"description": query.Get("error_description"),
+		}).Warn("Identity provider rejected sign-in")
+
+		newServiceErrorResponse(w, fmt.Errorf("%w: %s", service.ErrOIDCAuthentication, providerErr), "Failed to sign in")
+		return
+	}
+
+	code, state := query.Get("code"), query.Get("state")
+	if code == "" || state == "" {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	tokens, err := h.services.OIDC.SignIn(code, state)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to sign in")
+		return
+	}
+
+	logrus.Info("User signed in through identity provider")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTokenResponse(tokens))
+}
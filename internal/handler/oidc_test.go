@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+
+	mock_service "github.com/avealice/filmhub/internal/service/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_oidcLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authURL := "https://idp.example.com/authorize?state=abc"
+
+	mockOIDCService := mock_service.NewMockOIDC(ctrl)
+	mockOIDCService.EXPECT().AuthCodeURL().Return(authURL, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			OIDC: mockOIDCService,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/auth/oidc/login", nil)
+	w := httptest.NewRecorder()
+
+	handler.oidcLogin(w, req)
+
+	if w.Code != http.StatusFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusFound, w.Code)
+	}
+
+	if location := w.Header().Get("Location"); location != authURL {
+		t.Errorf("Expected redirect to %q, got %q", authURL, location)
+	}
+}
+
+func TestHandler_oidc_NotConfigured(t *testing.T) {
+	handler := &Handler{services: &service.Service{}}
+
+	for _, target := range []string{"/auth/oidc/login", "/auth/oidc/callback?code=c&state=s"} {
+		req := httptest.NewRequest("GET", target, nil)
+		w := httptest.NewRecorder()
+
+		handler.InitRoutes().ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusNotFound, w.Code)
+		}
+	}
+}
+
+func TestHandler_oidcCallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		setup        func(m *mock_service.MockOIDC)
+		expectedCode int
+	}{
+		{
+			name:  "success",
+			query: "?code=c&state=s",
+			setup: func(m *mock_service.MockOIDC) {
+				m.EXPECT().SignIn("c", "s").Return(model.Tokens{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "provider error",
+			query:        "?error=access_denied&state=s",
+			setup:        func(m *mock_service.MockOIDC) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "missing code",
+			query:        "?state=s",
+			setup:        func(m *mock_service.MockOIDC) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockOIDCService := mock_service.NewMockOIDC(ctrl)
+			tt.setup(mockOIDCService)
+
+			handler := &Handler{
+				services: &service.Service{
+					OIDC: mockOIDCService,
+				},
+			}
+
+			req := httptest.NewRequest("GET", "/auth/oidc/callback"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.oidcCallback(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_oidcCallback_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "invalid state", err: service.ErrInvalidOIDCState, expectedCode: http.StatusBadRequest, expectedError: "invalid_oidc_state"},
+		{
+			name:          "invalid ID token",
+			err:           fmt.Errorf("%w: ID token nonce does not match", service.ErrOIDCAuthentication),
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "oidc_authentication_failed",
+		},
+		{name: "disabled user", err: service.ErrUserDisabled, expectedCode: http.StatusForbidden, expectedError: "user_disabled"},
+		{name: "username taken", err: service.ErrUsernameTaken, expectedCode: http.StatusConflict, expectedError: "username_taken"},
+		{
+			name:          "provider unavailable",
+			err:           fmt.Errorf("%w: connection refused", service.ErrOIDCProvider),
+			expectedCode:  http.StatusBadGateway,
+			expectedError: "oidc_provider_unavailable",
+		},
+		{name: "internal error", err: errors.New("db error"), expectedCode: http.StatusInternalServerError, expectedError: "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockOIDCService := mock_service.NewMockOIDC(ctrl)
+			mockOIDCService.EXPECT().SignIn("c", "s").Return(model.Tokens{}, tt.err)
+
+			handler := &Handler{
+				services: &service.Service{
+					OIDC: mockOIDCService,
+				},
+			}
+
+			req := httptest.NewRequest("GET", "/auth/oidc/callback?code=c&state=s", nil)
+			w := httptest.NewRecorder()
+
+			handler.oidcCallback(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+			if response := decodeProblem(t, w); response.Code != tt.expectedError {
+				t.Errorf("Expected error code %q, got %q", tt.expectedError, response.Code)
+			}
+			if response := decodeProblem(t, w); response.Code != tt.expectedError {
+				t.Errorf("Expected error code %q, got %q", tt.expectedError, response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination извлекает параметры постраничного вывода limit, offset и after из строки запроса.
+// Если limit не указан, используется значение по умолчанию.
+func parsePagination(r *http.Request) (model.Pagination, error) {
+	query := r.URL.Query()
+	page := model.Pagination{Limit: defaultPageLimit}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxPageLimit {
+			return page, errors.New("limit must be an integer between 1 and " + strconv.Itoa(maxPageLimit))
+		}
+		page.Limit = value
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return page, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = value
+	}
+
+	if after := query.Get("after"); after != "" {
+		if page.Offset != 0 {
+			return page, errors.New("after and offset cannot be used together")
+		}
+
+		cursor, err := model.DecodeCursor(after)
+		if err != nil {
+			return page, err
+		}
+		page.After = cursor
+	}
+
+	return page, nil
+}
@@ -2,16 +2,47 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
+	"strings"
 
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
 	"github.com/sirupsen/logrus"
 )
 
-// ErrorResponse представляет JSON-структуру ответа с сообщением об ошибке.
+// problemContentType — тип содержимого ответов с описанием ошибки (RFC 7807).
+const problemContentType = "application/problem+json"
+
+// mergePatchContentType — тип содержимого документов JSON Merge Patch (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+// Коды ошибок, которые не выводятся из HTTP-статуса ответа.
+const (
+	codeValidationFailed = "validation_failed"
+	codeInvalidSort      = "invalid_sort"
+	codeTooManyAttempts  = "too_many_attempts"
+)
+
+// ErrorResponse представляет описание ошибки в формате RFC 7807 (application/problem+json).
 //
 // @title ErrorResponse
-// @description JSON-структура ответа с сообщением об ошибке.
+// @description Описание ошибки в формате RFC 7807.
 type ErrorResponse struct {
+	Type    string             `json:"type"`              // URI типа ошибки, about:blank означает, что смысл ошибки определяется статусом
+	Title   string             `json:"title"`             // Краткое описание статуса ответа
+	Status  int                `json:"status"`            // HTTP-статус ответа
+	Detail  string             `json:"detail,omitempty"`  // Описание конкретной ошибки
+	Code    string             `json:"code"`              // Стабильный машиночитаемый код ошибки, например movie_not_found
+	Allowed []string           `json:"allowed,omitempty"` // Допустимые значения параметра сортировки
+	Errors  []model.FieldError `json:"errors,omitempty"`  // Ошибки отдельных полей запроса
+
+	err error // Исходная ошибка, которая записывается в журнал и не передается клиенту
+}
+
+// MessageResponse представляет JSON-ответ с сообщением об успешном выполнении запроса.
+type MessageResponse struct {
 	Message string `json:"message"`
 }
 
@@ -21,16 +52,160 @@ type ErrorResponse struct {
 // @Description Создает новый JSON-ответ с заданным статусом кода и сообщением об ошибке, затем отправляет его клиенту.
 // @Tags Error Handling
 func newErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	logrus.Error(message)
+	writeErrorResponse(w, ErrorResponse{Status: statusCode, Detail: message})
+}
+
+// newServiceErrorResponse отвечает на ошибку сервиса статусом, соответствующим ее виду: 422 для service.ErrValidation,
+// 404 для service.ErrNotFound, 409 для service.ErrConflict, 403 для service.ErrForbidden, 412 для
+// service.ErrPreconditionFailed, 401 для service.ErrUnauthorized, 400 для service.ErrBadRequest и 502 для
+// service.ErrBadGateway. На остальные ошибки отвечает 500. В ответах 5xx клиенту передается только сообщение message
+// и код ошибки, а сама ошибка записывается в журнал.
+func newServiceErrorResponse(w http.ResponseWriter, err error, message string) {
+	statusCode := errorStatus(err)
+	errRes := ErrorResponse{Status: statusCode, Detail: err.Error()}
+	if statusCode >= http.StatusInternalServerError {
+		errRes.Detail = message
+		errRes.err = err
+	}
+
+	var sentinel *model.Error
+	var verr *model.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		errRes.Code = codeValidationFailed
+		errRes.Errors = verr.Fields
+	case errors.As(err, &sentinel):
+		errRes.Code = sentinel.Code
+	}
+
+	writeErrorResponse(w, errRes)
+}
+
+// errorStatus возвращает HTTP-статус ответа на ошибку сервиса по ее виду.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrBadGateway):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// newSortErrorResponse отправляет клиенту ответ 400 с описанием ошибки в параметрах сортировки
+// и списком допустимых значений.
+func newSortErrorResponse(w http.ResponseWriter, err error) {
+	errRes := ErrorResponse{Status: http.StatusBadRequest, Detail: err.Error(), Code: codeInvalidSort}
+
+	var sortErr *model.SortError
+	if errors.As(err, &sortErr) {
+		errRes.Allowed = sortErr.Allowed
+	}
+
+	writeErrorResponse(w, errRes)
+}
+
+// newBadRequestResponse отправляет клиенту ответ 400 с описанием ошибки в параметрах запроса. Если ошибка имеет
+// собственный код, например invalid_cursor, он передается клиенту.
+func newBadRequestResponse(w http.ResponseWriter, err error) {
+	errRes := ErrorResponse{Status: http.StatusBadRequest, Detail: err.Error()}
+
+	var sentinel *model.Error
+	if errors.As(err, &sentinel) {
+		errRes.Code = sentinel.Code
+	}
+
+	writeErrorResponse(w, errRes)
+}
+
+// newValidationErrorResponse отправляет клиенту ответ 422 со списком всех некорректных полей запроса.
+func newValidationErrorResponse(w http.ResponseWriter, verr *model.ValidationError) {
+	newServiceErrorResponse(w, verr, verr.Error())
+}
+
+// writeErrorResponse отправляет клиенту описание ошибки. Тип, заголовок и, если он не задан, код ошибки
+// определяются по статусу ответа. Ошибки сервера записываются в журнал с уровнем Error, ошибки клиента —
+// с уровнем Debug.
+func writeErrorResponse(w http.ResponseWriter, errRes ErrorResponse) {
+	logEntry := logrus.WithField("status", errRes.Status)
+	if errRes.err != nil {
+		logEntry = logEntry.WithError(errRes.err)
+	}
+	if errRes.Status >= http.StatusInternalServerError {
+		logEntry.Error(errRes.Detail)
+	} else {
+		logEntry.Debug(errRes.Detail)
+	}
+
+	errRes.Type = "about:blank"
+	errRes.Title = http.StatusText(errRes.Status)
+	if errRes.Code == "" {
+		errRes.Code = statusCode(errRes.Status)
+	}
+
+	writeJSON(w, problemContentType, errRes.Status, errRes)
+}
+
+// statusCode возвращает код ошибки, соответствующий HTTP-статусу, например not_found для 404.
+func statusCode(status int) string {
+	if status == http.StatusInternalServerError {
+		return "internal_error"
+	}
+
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// newMessageResponse отправляет клиенту JSON-ответ с сообщением об успешном выполнении запроса.
+func newMessageResponse(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, "application/json", statusCode, MessageResponse{Message: message})
+}
+
+// newCreatedResponse отправляет клиенту ответ 201 с созданным ресурсом и его адресом в заголовке Location.
+func newCreatedResponse(w http.ResponseWriter, location string, v interface{}) {
+	w.Header().Set("Location", location)
+	writeJSON(w, "application/json", http.StatusCreated, v)
+}
+
+// isMergePatch сообщает, передан ли в теле запроса документ JSON Merge Patch. Помимо application/merge-patch+json
+// принимается application/json, а также запрос без заголовка Content-Type.
+func isMergePatch(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == mergePatchContentType || mediaType == "application/json"
+}
 
-	errRes := ErrorResponse{Message: message}
-	jsonResponse, err := json.Marshal(errRes)
+func writeJSON(w http.ResponseWriter, contentType string, statusCode int, v interface{}) {
+	jsonResponse, err := json.Marshal(v)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Failed to encode response")
+		w.Header().Set("Content-Type", problemContentType)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal_error"}`))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(statusCode)
 	_, err = w.Write(jsonResponse)
 	if err != nil {
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// decodeProblem проверяет, что ответ содержит описание ошибки в формате application/problem+json, и возвращает его.
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+
+	if contentType := w.Header().Get("Content-Type"); contentType != problemContentType {
+		t.Errorf("Expected Content-Type %q, got %q", problemContentType, contentType)
+	}
+
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode error response %q: %v", w.Body.String(), err)
+	}
+
+	if response.Status != w.Code {
+		t.Errorf("Expected status %d in body, got %d", w.Code, response.Status)
+	}
+
+	return response
+}
+
+// assertProblem проверяет код и описание ошибки в ответе.
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, code, detail string) {
+	t.Helper()
+
+	response := decodeProblem(t, w)
+	if response.Code != code {
+		t.Errorf("Expected error code %q, got %q", code, response.Code)
+	}
+	if response.Detail != detail {
+		t.Errorf("Expected error detail %q, got %q", detail, response.Detail)
+	}
+}
+
+func TestNewErrorResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	newErrorResponse(w, http.StatusNotFound, "Not found")
+
+	response := decodeProblem(t, w)
+	expected := ErrorResponse{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "Not found", Code: "not_found"}
+	if fmt.Sprint(response) != fmt.Sprint(expected) {
+		t.Errorf("Expected response %+v, got %+v", expected, response)
+	}
+}
+
+func TestNewServiceErrorResponse(t *testing.T) {
+	verr := &model.ValidationError{}
+	verr.Add("title", "must not be empty")
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   int
+		expectedError  string
+		expectedDetail string
+	}{
+		{
+			name:           "not found",
+			err:            service.ErrMovieNotFound,
+			expectedCode:   http.StatusNotFound,
+			expectedError:  "movie_not_found",
+			expectedDetail: service.ErrMovieNotFound.Error(),
+		},
+		{
+			name:           "wrapped conflict",
+			err:            fmt.Errorf("create actor: %w", service.ErrActorExists),
+			expectedCode:   http.StatusConflict,
+			expectedError:  "actor_exists",
+			expectedDetail: "create actor: " + service.ErrActorExists.Error(),
+		},
+		{
+			name:           "forbidden",
+			err:            service.ErrUserDisabled,
+			expectedCode:   http.StatusForbidden,
+			expectedError:  "user_disabled",
+			expectedDetail: service.ErrUserDisabled.Error(),
+		},
+		{
+			name:           "unauthorized",
+			err:            service.ErrInvalidCredentials,
+			expectedCode:   http.StatusUnauthorized,
+			expectedError:  "invalid_credentials",
+			expectedDetail: service.ErrInvalidCredentials.Error(),
+		},
+		{
+			name:           "bad request",
+			err:            service.ErrEmptySearchQuery,
+			expectedCode:   http.StatusBadRequest,
+			expectedError:  "empty_search_query",
+			expectedDetail: service.ErrEmptySearchQuery.Error(),
+		},
+		{
+			name:           "validation",
+			err:            verr,
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedError:  "validation_failed",
+			expectedDetail: verr.Error(),
+		},
+		{
+			name:           "bad gateway is not disclosed",
+			err:            fmt.Errorf("%w: connection refused", service.ErrOIDCProvider),
+			expectedCode:   http.StatusBadGateway,
+			expectedError:  "oidc_provider_unavailable",
+			expectedDetail: "Failed to process request",
+		},
+		{
+			name:           "unexpected error is not disclosed",
+			err:            errors.New("pq: connection refused"),
+			expectedCode:   http.StatusInternalServerError,
+			expectedError:  "internal_error",
+			expectedDetail: "Failed to process request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			newServiceErrorResponse(w, tt.err, "Failed to process request")
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			assertProblem(t, w, tt.expectedError, tt.expectedDetail)
+		})
+	}
+}
+
+func TestNewServiceErrorResponse_FieldErrors(t *testing.T) {
+	verr := &model.ValidationError{}
+	verr.Add("title", "must not be empty")
+	verr.Add("rating", "must be between 0 and 10")
+	w := httptest.NewRecorder()
+
+	newServiceErrorResponse(w, verr, "Failed to process request")
+
+	response := decodeProblem(t, w)
+	if len(response.Errors) != 2 || response.Errors[0].Field != "title" || response.Errors[1].Field != "rating" {
+		t.Errorf("Expected errors for title and rating, got %+v", response.Errors)
+	}
+}
+
+func TestNewMessageResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	newMessageResponse(w, http.StatusOK, "movie deleted successfully")
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected Content-Type %q, got %q", "application/json", contentType)
+	}
+	if body := w.Body.String(); body != `{"message":"movie deleted successfully"}` {
+		t.Errorf("Expected response body %q, got %q", `{"message":"movie deleted successfully"}`, body)
+	}
+}
+
+func TestWriteErrorResponse_LogLevel(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.DebugLevel)
+
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		level logrus.Level
+	}{
+		{
+			name:  "client error",
+			write: func(w http.ResponseWriter) { newErrorResponse(w, http.StatusNotFound, "Not found") },
+			level: logrus.DebugLevel,
+		},
+		{
+			name: "service error",
+			write: func(w http.ResponseWriter) {
+				newServiceErrorResponse(w, service.ErrMovieNotFound, "Failed to get movie")
+			},
+			level: logrus.DebugLevel,
+		},
+		{
+			name: "internal error",
+			write: func(w http.ResponseWriter) {
+				newServiceErrorResponse(w, errors.New("connection refused"), "Failed to get movie")
+			},
+			level: logrus.ErrorLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook.Reset()
+
+			tt.write(httptest.NewRecorder())
+
+			if len(hook.Entries) != 1 {
+				t.Fatalf("Expected a single log entry, got %d", len(hook.Entries))
+			}
+			if entry := hook.LastEntry(); entry.Level != tt.level {
+				t.Errorf("Expected level %s, got %s", tt.level, entry.Level)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// routeMethods перечисляет методы, которые проверяются при формировании заголовка Allow.
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// router направляет запросы по маршрутам, объявленным шаблонами ServeMux вида "МЕТОД /путь/{параметр}".
+// В отличие от ServeMux, на запросы к несуществующему маршруту и с неподдерживаемым методом
+// отвечает в формате JSON: 404 и 405 с заголовком Allow соответственно.
+type router struct {
+	mux *http.ServeMux
+}
+
+func newRouter() *router {
+	return &router{mux: http.NewServeMux()}
+}
+
+// handle регистрирует обработчик маршрута, pattern задается в формате шаблонов ServeMux.
+func (rt *router) handle(pattern string, handler http.Handler) {
+	rt.mux.Handle(pattern, handler)
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := rt.mux.Handler(r); pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := rt.allowedMethods(r)
+	if len(allowed) == 0 {
+		newErrorResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	newErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// allowedMethods возвращает методы, для которых путь запроса соответствует какому-либо маршруту.
+func (rt *router) allowedMethods(r *http.Request) []string {
+	var allowed []string
+	for _, method := range routeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+
+		if _, pattern := rt.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
+}
+
+// pathInt извлекает из пути запроса положительное целое число, заданное параметром name шаблона маршрута.
+func pathInt(r *http.Request, name string) (int, error) {
+	value, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("invalid path parameter %s: %q", name, r.PathValue(name))
+	}
+
+	return value, nil
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+
+	mock_service "github.com/avealice/filmhub/internal/service/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+// routeToken — токен доступа, который принимает сервис авторизации, подставленный withRouteIdentity.
+const routeToken = "route-token"
+
+// withRouteIdentity возвращает запрос с токеном доступа пользователя с ролью role и разрешениями permissions,
+// чтобы его можно было отправить через InitRoutes. Сервис авторизации обработчика заменяется моком, принимающим этот токен.
+func withRouteIdentity(ctrl *gomock.Controller, h *Handler, req *http.Request, role string, permissions ...string) *http.Request {
+	identity := model.Identity{UserID: 1, Role: role, Permissions: permissions, TokenID: "jti"}
+
+	mockAuthService := mock_service.NewMockAuthorization(ctrl)
+	mockAuthService.EXPECT().ParseToken(routeToken).Return(identity, nil).AnyTimes()
+	h.services.Authorization = mockAuthService
+
+	req.Header.Set(authorizationHeader, "Bearer "+routeToken)
+	return req
+}
+
+func TestHandler_InitRoutes_NotFoundAndMethodNotAllowed(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		expectedCode  int
+		expectedAllow string
+	}{
+		{
+			name:         "unknown path",
+			method:       http.MethodGet,
+			path:         "/api/unknown",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "extra path segment",
+			method:       http.MethodGet,
+			path:         "/api/movie/1/actors",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing id",
+			method:       http.MethodDelete,
+			path:         "/api/movie/",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:          "movie by id",
+			method:        http.MethodPost,
+			path:          "/api/movie/1",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, HEAD, PUT, PATCH, DELETE",
+		},
+		{
+			name:          "movie collection",
+			method:        http.MethodDelete,
+			path:          "/api/movie",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "POST",
+		},
+		{
+			name:          "movie search also matches movie by id",
+			method:        http.MethodPost,
+			path:          "/api/movie/search",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, HEAD, PUT, PATCH, DELETE",
+		},
+		{
+			name:          "profile",
+			method:        http.MethodPut,
+			path:          "/api/me",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, HEAD, PATCH",
+		},
+		{
+			name:          "api keys",
+			method:        http.MethodPut,
+			path:          "/api/admin/api-keys",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, HEAD, POST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &Handler{services: &service.Service{}}
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			if allow := w.Header().Get("Allow"); allow != tt.expectedAllow {
+				t.Errorf("Expected Allow header %q, got %q", tt.expectedAllow, allow)
+			}
+
+			decodeProblem(t, w)
+		})
+	}
+}
+
+func TestHandler_InitRoutes_SearchDoesNotCollideWithID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMovieService := mock_service.NewMockMovie(ctrl)
+	mockMovieService.EXPECT().GetMoviesByTitle("Matrix", gomock.Any()).Return([]model.MovieWithActors{}, nil)
+	mockMovieService.EXPECT().GetMovieByID(7).Return(model.MovieWithActors{}, nil)
+
+	handler := &Handler{
+		services: &service.Service{
+			Movie: mockMovieService,
+		},
+	}
+	routes := handler.InitRoutes()
+
+	for _, path := range []string{"/api/movie/search?title=Matrix", "/api/movie/7"} {
+		req := withRouteIdentity(ctrl, handler, httptest.NewRequest(http.MethodGet, path, nil), "user")
+		w := httptest.NewRecorder()
+
+		routes.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status code %d, got %d", path, http.StatusOK, w.Code)
+		}
+	}
+}
+
+func TestPathInt(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+		wantErr  bool
+	}{
+		{value: "42", expected: 42},
+		{value: "0", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", tt.value)
+
+		got, err := pathInt(req, "id")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("pathInt(%q): expected error %t, got %v", tt.value, tt.wantErr, err)
+		}
+		if got != tt.expected {
+			t.Errorf("pathInt(%q): expected %d, got %d", tt.value, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+// parseSort извлекает параметры сортировки sort_by и sort_order из строки запроса
+// и проверяет, что поля сортировки входят в список allowed.
+// Если sort_by не указан, используются defaultSortBy и, если не указан sort_order, defaultSortOrder.
+func parseSort(r *http.Request, allowed []string, defaultSortBy, defaultSortOrder string) (model.Sort, error) {
+	sortBy := r.URL.Query().Get("sort_by")
+	sortOrder := r.URL.Query().Get("sort_order")
+
+	if sortBy == "" {
+		sortBy = defaultSortBy
+
+		if sortOrder == "" {
+			sortOrder = defaultSortOrder
+		}
+	}
+
+	return model.ParseSort(sortBy, sortOrder, allowed)
+}
+
+// parseMovieSort извлекает параметры сортировки фильмов. По умолчанию фильмы сортируются
+// по рейтингу в порядке убывания.
+func parseMovieSort(r *http.Request) (model.Sort, error) {
+	return parseSort(r, model.MovieSortFields, "rating", "desc")
+}
+
+// parseUserMovieSort извлекает параметры сортировки списка фильмов. Если sort_by не указан,
+// используется сортировка из настроек пользователя, выполняющего запрос, а если она не задана -
+// сортировка по рейтингу в порядке убывания.
+func (h *Handler) parseUserMovieSort(r *http.Request) (model.Sort, error) {
+	if r.URL.Query().Get("sort_by") != "" {
+		return parseMovieSort(r)
+	}
+
+	userID, err := getUserID(r)
+	if err != nil {
+		return parseMovieSort(r)
+	}
+
+	prefs, err := h.services.Profile.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefs.MovieSort == "" {
+		return parseMovieSort(r)
+	}
+
+	return parseSort(r, model.MovieSortFields, prefs.MovieSort, "asc")
+}
+
+// parseActorSort извлекает параметры сортировки актеров. По умолчанию актеры сортируются
+// по имени в порядке возрастания.
+func parseActorSort(r *http.Request) (model.Sort, error) {
+	return parseSort(r, model.ActorSortFields, "name", "asc")
+}
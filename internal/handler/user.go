@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+// getAllUsers возвращает список пользователей.
+//
+// @Summary Получить всех пользователей
+// @Description Возвращает список пользователей с постраничным выводом. Требуется разрешение user:manage.
+// @Tags /api/admin/users
+// @Produce json
+// @Param limit query int false "Количество пользователей на странице (1-100, по умолчанию 20)"
+// @Param offset query int false "Количество пропускаемых пользователей"
+// @Success 200 {object} model.UsersPage
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 405 {object} ErrorResponse "Некорректный метод"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/users [get]
+// @Security ApiKeyAuth
+func (h *Handler) getAllUsers(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		newBadRequestResponse(w, err)
+		return
+	}
+
+	if page.After != nil {
+		newErrorResponse(w, http.StatusBadRequest, "after is not supported by the user list")
+		return
+	}
+
+	users, err := h.services.User.GetAllUsers(page.Limit, page.Offset)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to get users")
+		return
+	}
+
+	userID, _ := getUserID(r)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id": userID,
+		"count":   len(users.Users),
+		"total":   users.Total,
+	}).Info("Users successfully fetched")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// getUser возвращает информацию о пользователе.
+//
+// @Summary Получить пользователя
+// @Description Возвращает информацию о пользователе по его идентификатору. Требуется разрешение user:manage.
+// @Tags /api/admin/users/{id}
+// @Produce json
+// @Param id path int true "Идентификатор пользователя"
+// @Success 200 {object} model.UserAccount
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.services.User.GetUser(userID)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to process user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// updateUser изменяет роль, пароль пользователя или блокирует его.
+//
+// @Summary Изменить пользователя
+// @Description Изменяет роль пользователя, блокирует или разблокирует его или задает новый пароль. Поля, которые не указаны, не изменяются.
+// @Description Заблокированный пользователь не может войти в систему, а его уже выданные токены перестают действовать.
+// @Description Блокировка и смена пароля отзывают все токены обновления пользователя. Требуется разрешение user:manage.
+// @Tags /api/admin/users/{id}
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор пользователя"
+// @Param user body model.UpdateUserInput true "Изменения пользователя"
+// @Success 200 {object} model.UserAccount
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 409 {object} ErrorResponse "Нельзя заблокировать или понизить в правах самого себя"
+// @Failure 422 {object} ErrorResponse "Неизвестная роль"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id} [patch]
+// @Security ApiKeyAuth
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input model.UpdateUserInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	currentUserID, _ := getUserID(r)
+
+	user, err := h.services.User.UpdateUser(currentUserID, userID, input)
+	if err != nil {
+		newServiceErrorResponse(w, err, "Failed to process user")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":        currentUserID,
+		"target_user_id": userID,
+		"role":           user.Role,
+		"disabled":       user.Disabled,
+	}).Info("User updated successfully")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// deleteUser удаляет пользователя.
+//
+// @Summary Удалить пользователя
+// @Description Удаляет пользователя вместе с его токенами обновления. Требуется разрешение user:manage.
+// @Tags /api/admin/users/{id}
+// @Param id path int true "Идентификатор пользователя"
+// @Success 204 "Пользователь удален"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или данные"
+// @Failure 401 {object} ErrorResponse "Пустой заголовок авторизации"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 409 {object} ErrorResponse "Нельзя удалить самого себя"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		newErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	currentUserID, _ := getUserID(r)
+
+	if err := h.services.User.DeleteUser(currentUserID, userID); err != nil {
+		newServiceErrorResponse(w, err, "Failed to process user")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":        currentUserID,
+		"target_user_id": userID,
+	}).Info("User deleted successfully")
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/service"
+
+	mock_service "github.com/avealice/filmhub/internal/service/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_getAllUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mock_service.NewMockUser(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			User: mockUserService,
+		},
+	}
+
+	mockUserService.EXPECT().GetAllUsers(2, 4).Return(model.UsersPage{
+		Users: []model.UserAccount{{ID: 5, Username: "alice", Role: "user"}},
+		Total: 5,
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/admin/users?limit=2&offset=4", nil)
+	req = withIdentity(req, "admin", adminPermissions...)
+	w := httptest.NewRecorder()
+
+	handler.getAllUsers(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response model.UsersPage
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Error decoding response body: %v", err)
+	}
+
+	if response.Total != 5 || len(response.Users) != 1 || response.Users[0].Username != "alice" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestHandler_userRoutes(t *testing.T) {
+	disabled := true
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mockBehavior func(s *mock_service.MockUser)
+		expectedCode int
+	}{
+		{
+			name:   "get",
+			method: http.MethodGet,
+			path:   "/api/admin/users/5",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetUser(5).Return(model.UserAccount{ID: 5, Username: "alice", Role: "user"}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "get not found",
+			method: http.MethodGet,
+			path:   "/api/admin/users/5",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetUser(5).Return(model.UserAccount{}, service.ErrUserNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "disable",
+			method: http.MethodPatch,
+			path:   "/api/admin/users/5",
+			body:   `{"disabled": true}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().UpdateUser(1, 5, model.UpdateUserInput{Disabled: &disabled}).
+					Return(model.UserAccount{ID: 5, Username: "alice", Role: "user", Disabled: true}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "unknown role",
+			method: http.MethodPatch,
+			path:   "/api/admin/users/5",
+			body:   `{"role": "root"}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().UpdateUser(1, 5, gomock.Any()).Return(model.UserAccount{}, service.ErrUnknownRole)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "disable yourself",
+			method: http.MethodPatch,
+			path:   "/api/admin/users/1",
+			body:   `{"disabled": true}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().UpdateUser(1, 1, gomock.Any()).Return(model.UserAccount{}, service.ErrSelfModification)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			path:   "/api/admin/users/5",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().DeleteUser(1, 5).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "invalid id",
+			method:       http.MethodGet,
+			path:         "/api/admin/users/abc",
+			mockBehavior: func(s *mock_service.MockUser) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			path:         "/api/admin/users/5",
+			mockBehavior: func(s *mock_service.MockUser) {},
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserService := mock_service.NewMockUser(ctrl)
+			tt.mockBehavior(mockUserService)
+
+			handler := &Handler{
+				services: &service.Service{
+					User: mockUserService,
+				},
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = withRouteIdentity(ctrl, handler, req, "admin", adminPermissions...)
+			w := httptest.NewRecorder()
+
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_userRoutes_Forbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := &Handler{
+		services: &service.Service{
+			User: mock_service.NewMockUser(ctrl),
+		},
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/admin/users/5", nil)
+	req = withRouteIdentity(ctrl, handler, req, "editor", model.PermissionMovieWrite, model.PermissionActorWrite)
+	w := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,326 @@
+// Package migrate applies versioned SQL migrations to the database.
+//
+// Applied versions are recorded in the schema_migrations table. Every migration runs
+// in its own transaction, and a Postgres advisory lock prevents several instances
+// of the application from migrating the same database at once.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const migrationsTable = "schema_migrations"
+
+// lockID is the key of the advisory lock held while migrations are applied.
+const lockID = 7246193520
+
+var fileNameRe = regexp.MustCompile(`^(\d+)_(.+)_(up|down)\.sql$`)
+
+// Migration is a single schema change together with the statement that reverts it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // Nil if the migration is pending
+}
+
+// Load reads migrations from the root of fsys. Files that do not follow the
+// NNNNNN_name_up.sql / NNNNNN_name_down.sql scheme are ignored.
+// Migrations are returned sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileNameRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// New creates a migrator for the migrations found in fsys.
+func New(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverts the last n applied migrations.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return errors.New("number of migrations to revert must be positive")
+	}
+
+	return m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+
+			if err := m.revert(ctx, conn, m.migrations[i]); err != nil {
+				return err
+			}
+			n--
+		}
+
+		return nil
+	})
+}
+
+// Goto migrates the database to the given version: every migration up to and including
+// it is applied, every later one is reverted. Version 0 reverts all migrations.
+func (m *Migrator) Goto(ctx context.Context, version int) error {
+	if version < 0 {
+		return errors.New("version must not be negative")
+	}
+
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status returns the state of every known migration, sorted by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]Status, 0, len(m.migrations))
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+// Session-level advisory locks belong to a connection, so the lock, the migrations
+// and the unlock must all go through the same one.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`, migrationsTable)
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("creating %s table: %w", migrationsTable, err)
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT version, applied_at FROM %s", migrationsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", migrationsTable)
+		_, err := tx.ExecContext(ctx, query, migration.Version, migration.Name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf("DELETE FROM %s WHERE version = $1", migrationsTable)
+		_, err := tx.ExecContext(ctx, query, migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+func inTx(ctx context.Context, conn *sqlx.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
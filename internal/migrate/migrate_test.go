@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/avealice/filmhub/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_index_up.sql":   {Data: []byte("CREATE INDEX i ON t (c);")},
+		"000002_add_index_down.sql": {Data: []byte("DROP INDEX i;")},
+		"000001_init_up.sql":        {Data: []byte("CREATE TABLE t (c INT);")},
+		"000001_init_down.sql":      {Data: []byte("DROP TABLE t;")},
+		"migrations.go":             {Data: []byte("package migrations")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE t (c INT);", Down: "DROP TABLE t;"},
+		{Version: 2, Name: "add_index", Up: "CREATE INDEX i ON t (c);", Down: "DROP INDEX i;"},
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d migrations, got %d", len(expected), len(got))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected migration %+v, got %+v", expected[i], got[i])
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "missing down file",
+			fsys: fstest.MapFS{
+				"000001_init_up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+			},
+		},
+		{
+			name: "names differ",
+			fsys: fstest.MapFS{
+				"000001_init_up.sql":    {Data: []byte("CREATE TABLE t (c INT);")},
+				"000001_setup_down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+		},
+		{
+			name: "zero version",
+			fsys: fstest.MapFS{
+				"000000_init_up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+				"000000_init_down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, m := range got {
+		if m.Version != i+1 {
+			t.Errorf("Expected migration versions to be sequential, got %d at position %d", m.Version, i)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type ActorWithMovies struct {
 	Gender    string  `json:"gender" db:"gender"`         // Valid values: "male", "female", "other".
 	BirthDate string  `json:"birth_date" db:"birth_date"` // Format: "YYYY-M-D".
 	Movies    []Movie `json:"movies"`                     // Movies associated with the actor
+	Version   int     `json:"-" db:"version"`             // Version of the actor, sent in the ETag header
 }
 
 type InputActor struct {
@@ -23,3 +24,9 @@ type InputActor struct {
 	BirthDate string  `json:"birth_date" db:"birth_date"` // Format: "YYYY-M-D".
 	Movies    []Movie `json:"movies"`                     // Movies associated with the actor
 }
+
+// ActorSearchHit represents an actor found by fuzzy name search.
+type ActorSearchHit struct {
+	ActorWithMovies
+	Similarity float64 `json:"similarity"` // Word similarity of the name to the search query, from 0 to 1
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// APIKey represents a long-lived key that authenticates services instead of a user's access token.
+// The key itself is shown only once on creation; only its hash is stored.
+type APIKey struct {
+	ID          int        `json:"id" db:"id"`                               // Unique identifier of the key
+	Name        string     `json:"name" db:"name"`                           // Human readable name of the key
+	Prefix      string     `json:"prefix" db:"prefix"`                       // First characters of the key that help to recognize it
+	UserID      int        `json:"user_id" db:"user_id"`                     // Administrator who created the key and on whose behalf it acts
+	Permissions []string   `json:"permissions" db:"-"`                       // Permissions the key is scoped to
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`     // Expiration time of the key, nil if the key does not expire
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"` // Time the key was last used to authenticate a request
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`               // Creation time of the key
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`     // Revocation time of the key, nil if the key is active
+}
+
+// APIKeyInput represents the data of a new API key.
+type APIKeyInput struct {
+	Name        string     `json:"name"`                 // Human readable name of the key
+	Permissions []string   `json:"permissions"`          // Permissions the key is scoped to, a subset of the creator's permissions
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Expiration time of the key, omit for a key that does not expire
+}
+
+// CreatedAPIKey represents a newly created API key together with its secret value.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"` // The key itself, shown only once
+}
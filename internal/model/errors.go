@@ -0,0 +1,42 @@
+package model
+
+import "errors"
+
+// Kinds of errors. Errors reported by the repositories and services wrap one of them, so the
+// kind of an error can be checked with errors.Is without knowing every particular error.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+
+	// ErrUnauthorized is reported when the credentials supplied by the client are wrong, expired or revoked.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrBadRequest is reported when the request is rejected as a whole rather than field by field.
+	ErrBadRequest = errors.New("bad request")
+	// ErrBadGateway is reported when a service the application depends on, such as an identity provider, fails.
+	ErrBadGateway = errors.New("bad gateway")
+
+	// ErrPreconditionFailed is reported when a conditional write finds the resource changed since the client read it.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a sentinel error of a particular kind with a stable machine-readable code.
+type Error struct {
+	Kind    error  // One of the kinds of errors above, for example ErrNotFound
+	Code    string // Stable code of the error, for example user_not_found
+	Message string // Human-readable description of the error
+}
+
+// NewError returns a sentinel error of the kind with the code and message.
+func NewError(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
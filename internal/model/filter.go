@@ -0,0 +1,11 @@
+package model
+
+// MovieFilter narrows down the movie list. Empty fields do not restrict the list.
+type MovieFilter struct {
+	Title          string // Fragment of the movie title, case-insensitive
+	MinRating      *int   // Lowest rating, inclusive
+	MaxRating      *int   // Highest rating, inclusive
+	ReleasedAfter  string // Earliest release date, inclusive. Format: "YYYY-MM-DD".
+	ReleasedBefore string // Latest release date, inclusive. Format: "YYYY-MM-DD".
+	ActorIDs       []int  // Movies featuring at least one of the actors
+}
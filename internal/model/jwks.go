@@ -0,0 +1,19 @@
+package model
+
+// JSONWebKey represents a public key in the JWK format (RFC 7517).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`           // Key type: RSA, EC or OKP
+	Use       string `json:"use"`           // Intended use of the key, always sig
+	KeyID     string `json:"kid"`           // Identifier matching the kid header of tokens
+	Algorithm string `json:"alg,omitempty"` // Signing algorithm: RS256, ES256 or EdDSA
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA public exponent
+	Curve     string `json:"crv,omitempty"` // Curve of an EC key (P-256) or an OKP key (Ed25519)
+	X         string `json:"x,omitempty"`   // Ed25519 public key or x coordinate of an EC public key
+	Y         string `json:"y,omitempty"`   // y coordinate of an EC public key
+}
+
+// JSONWebKeySet represents a set of public keys that verify access tokens.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"` // Public keys
+}
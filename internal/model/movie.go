@@ -1,5 +1,11 @@
 package model
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
 // Movie represents a movie in the system.
 type Movie struct {
 	ID          int    `json:"-" db:"id"`                      // Unique identifier for the movie
@@ -17,6 +23,7 @@ type MovieWithActors struct {
 	ReleaseDate string  `json:"release_date" db:"release_date"` // Format: "YYYY-M-D".
 	Rating      int     `json:"rating" db:"rating"`             // Rating of the movie
 	Actors      []Actor `json:"actors"`                         // Actors associated with the movie
+	Version     int     `json:"-" db:"version"`                 // Version of the movie, sent in the ETag header
 }
 
 type InputMovie struct {
@@ -27,8 +34,63 @@ type InputMovie struct {
 	Actors      []Actor `json:"actors"`                         // Actors associated with the movie
 }
 
+// PatchMovieInput represents a JSON Merge Patch (RFC 7396) of a movie. Fields that are absent from the patch are nil
+// and left unchanged. A field set to null is removed, that is reset to its zero value: this clears the description
+// and sets the rating to 0, while removing the title or the release date makes the movie invalid.
+// The cast of the movie is not part of the patch, actors are added and removed one by one.
+type PatchMovieInput struct {
+	Title       *string `json:"title,omitempty"`        // New title of the movie
+	Description *string `json:"description,omitempty"`  // New description of the movie
+	ReleaseDate *string `json:"release_date,omitempty"` // New release date, format: "YYYY-M-D".
+	Rating      *int    `json:"rating,omitempty"`       // New rating of the movie
+}
+
+// UnmarshalJSON decodes a merge patch, telling fields set to null from absent ones. Unknown fields are rejected.
+func (p *PatchMovieInput) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for name, value := range fields {
+		var target interface{}
+		switch name {
+		case "title":
+			p.Title = new(string)
+			target = p.Title
+		case "description":
+			p.Description = new(string)
+			target = p.Description
+		case "release_date":
+			p.ReleaseDate = new(string)
+			target = p.ReleaseDate
+		case "rating":
+			p.Rating = new(int)
+			target = p.Rating
+		default:
+			return fmt.Errorf("json: unknown field %q", name)
+		}
+
+		if bytes.Equal(value, []byte("null")) {
+			continue
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
 // MovieActor represents a relationship between a movie and an actor in the system.
 type MovieActor struct {
 	MovieID int `json:"movie_id" db:"movie_id"` // ID of the movie
 	ActorID int `json:"actor_id" db:"actor_id"` // ID of the actor
 }
+
+// MovieSearchHit represents a movie found by full-text search.
+type MovieSearchHit struct {
+	MovieWithActors
+	Rank    float64 `json:"rank"`    // Relevance of the movie to the search query
+	Snippet string  `json:"snippet"` // HTML-escaped fragment of the description with matches wrapped in <mark> tags
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// ErrInvalidCursor is reported for a cursor that is malformed or was issued for another sort.
+var ErrInvalidCursor = NewError(ErrBadRequest, "invalid_cursor", "invalid cursor")
+
+// Pagination describes which slice of a list is requested.
+type Pagination struct {
+	Limit  int     // Maximum number of items in the page
+	Offset int     // Number of items to skip
+	After  *Cursor // Keyset cursor, the page starts right after it
+}
+
+// Cursor points at the last item of a page for keyset pagination.
+type Cursor struct {
+	Sort   string   `json:"s"`  // Sort of the list the cursor was issued for, in the format of Sort.String
+	Values []string `json:"v"`  // Values of the sort keys of the last item
+	ID     int      `json:"id"` // Identifier of the last item, used as a tie-breaker
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor previously returned by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// MoviesPage represents a page of the movie list.
+type MoviesPage struct {
+	Movies     []MovieWithActors `json:"movies"`                // Movies on the page
+	NextCursor string            `json:"next_cursor,omitempty"` // Cursor for the next page, empty on the last one
+	Total      int               `json:"total"`                 // Total number of movies
+}
+
+// ActorsPage represents a page of the actor list.
+type ActorsPage struct {
+	Actors     []ActorWithMovies `json:"actors"`                // Actors on the page
+	NextCursor string            `json:"next_cursor,omitempty"` // Cursor for the next page, empty on the last one
+	Total      int               `json:"total"`                 // Total number of actors
+}
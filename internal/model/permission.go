@@ -0,0 +1,17 @@
+package model
+
+import "slices"
+
+// Permissions that can be granted to roles.
+const (
+	PermissionMovieWrite  = "movie:write"  // Create and update movies
+	PermissionMovieDelete = "movie:delete" // Delete movies
+	PermissionActorWrite  = "actor:write"  // Create and update actors
+	PermissionActorDelete = "actor:delete" // Delete actors
+	PermissionUserManage  = "user:manage"  // Manage user accounts and roles
+)
+
+// HasPermission reports whether the identity has been granted the permission.
+func (i Identity) HasPermission(permission string) bool {
+	return slices.Contains(i.Permissions, permission)
+}
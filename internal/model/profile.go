@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Preferences represents the settings a user chooses for their own account.
+// Empty fields are not set and the defaults of the API apply.
+type Preferences struct {
+	MovieSort string `json:"movie_sort" db:"movie_sort"` // Default sort of the movie list in the format of sort_by, e.g. "-rating,title"
+	Locale    string `json:"locale" db:"locale"`         // Preferred language as a BCP 47 tag, e.g. "ru" or "en-US"
+}
+
+// Profile represents the account of the current user.
+type Profile struct {
+	ID          int         `json:"id" db:"id"`                 // Unique identifier for the user
+	Username    string      `json:"username" db:"username"`     // Username of the user
+	Role        string      `json:"role" db:"role"`             // Role of the user
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"` // Registration time of the user
+	Preferences Preferences `json:"preferences" db:"-"`         // Settings of the user
+}
+
+// UpdatePreferencesInput represents changes of the preferences of the current user.
+// Fields that are nil are left unchanged, an empty string resets the field to the default.
+type UpdatePreferencesInput struct {
+	MovieSort *string `json:"movie_sort,omitempty"` // New default sort of the movie list
+	Locale    *string `json:"locale,omitempty"`     // New preferred language
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Fields that movie and actor lists can be sorted by.
+var (
+	MovieSortFields = []string{"title", "rating", "release_date"}
+	ActorSortFields = []string{"name", "gender", "birth_date"}
+)
+
+// SortOrders are the accepted values of the default sort order.
+var SortOrders = []string{"asc", "desc"}
+
+// SortField represents a single key of a list ordering.
+type SortField struct {
+	Field string // Name of the field to sort by
+	Desc  bool   // Whether the field is sorted in descending order
+}
+
+// Sort represents a list ordering, the first field has the highest priority.
+type Sort []SortField
+
+// SortError reports a sort parameter value that is not in the list of allowed values.
+type SortError struct {
+	Param   string   // Name of the invalid parameter
+	Value   string   // Rejected value
+	Allowed []string // Values accepted by the parameter
+}
+
+func (e *SortError) Error() string {
+	return fmt.Sprintf("invalid %s value %q, allowed values: %s", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// ParseSort parses a comma-separated list of fields, e.g. "rating,-release_date".
+// A field prefixed with "-" is sorted in descending order, a field prefixed with "+"
+// in ascending order. Fields without a prefix are sorted in defaultOrder ("asc" or "desc").
+// Every field must be one of allowed, otherwise a *SortError is returned.
+func ParseSort(sortBy, defaultOrder string, allowed []string) (Sort, error) {
+	var desc bool
+	switch strings.ToLower(defaultOrder) {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return nil, &SortError{Param: "sort_order", Value: defaultOrder, Allowed: SortOrders}
+	}
+
+	var sort Sort
+	for _, field := range strings.Split(sortBy, ",") {
+		field = strings.TrimSpace(field)
+
+		f := SortField{Field: field, Desc: desc}
+		if strings.HasPrefix(field, "-") {
+			f = SortField{Field: field[1:], Desc: true}
+		} else if strings.HasPrefix(field, "+") {
+			f = SortField{Field: field[1:], Desc: false}
+		}
+
+		sort = append(sort, f)
+	}
+
+	if err := sort.Validate(allowed); err != nil {
+		return nil, err
+	}
+
+	return sort, nil
+}
+
+// Validate checks that the sort is not empty and uses only allowed fields.
+func (s Sort) Validate(allowed []string) error {
+	if len(s) == 0 {
+		return &SortError{Param: "sort_by", Allowed: allowed}
+	}
+
+	for _, f := range s {
+		if !slices.Contains(allowed, f.Field) {
+			return &SortError{Param: "sort_by", Value: f.Field, Allowed: allowed}
+		}
+	}
+
+	return nil
+}
+
+// String returns the sort in the format accepted by ParseSort.
+func (s Sort) String() string {
+	fields := make([]string, len(s))
+	for i, f := range s {
+		if f.Desc {
+			fields[i] = "-" + f.Field
+		} else {
+			fields[i] = f.Field
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Identity represents the authenticated user of a request, taken from a verified access token
+// or API key.
+type Identity struct {
+	UserID      int       // Identifier of the user
+	Role        string    // Role of the user
+	Permissions []string  // Permissions granted to the role, narrowed to the scopes of an API key
+	TokenID     string    // Unique identifier (jti) of the access token, empty for an API key
+	APIKeyID    int       // Identifier of the API key, zero for an access token
+	ExpiresAt   time.Time // Expiration time of the access token or API key, zero if it does not expire
+}
+
+// Tokens represents a pair of tokens issued on sign-in or refresh.
+type Tokens struct {
+	AccessToken  string // Short-lived token that authorizes API requests
+	RefreshToken string // Long-lived single-use token that obtains a new pair
+	ExpiresIn    int    // Lifetime of the access token in seconds
+}
+
+// RefreshToken represents a stored refresh token. Only a hash of the token itself is stored.
+type RefreshToken struct {
+	ID        int       `db:"id"`         // Unique identifier of the token
+	TokenHash string    `db:"token_hash"` // SHA-256 hash of the token
+	UserID    int       `db:"user_id"`    // Owner of the token
+	FamilyID  string    `db:"family_id"`  // Identifier shared by all tokens rotated from the same sign-in
+	ExpiresAt time.Time `db:"expires_at"` // Expiration time of the token
+}
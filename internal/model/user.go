@@ -1,9 +1,37 @@
 package model
 
+import "time"
+
 // User represents a user in the system.
 type User struct {
 	ID       int    `json:"-" db:"id"`                   // Unique identifier for the user
 	Username string `json:"username" db:"username"`      // Username of the user
 	Password string `json:"password" db:"password_hash"` // Password hash of the user
 	Role     string `json:"-" db:"role"`                 // Role of the user
+	Disabled bool   `json:"-" db:"disabled"`             // Whether the user is not allowed to sign in
+
+	SessionsRevokedAt *time.Time `json:"-" db:"sessions_revoked_at"` // Access tokens issued before this time are rejected
+}
+
+// UserAccount represents a user account as seen by administrators.
+type UserAccount struct {
+	ID        int       `json:"id" db:"id"`                 // Unique identifier for the user
+	Username  string    `json:"username" db:"username"`     // Username of the user
+	Role      string    `json:"role" db:"role"`             // Role of the user
+	Disabled  bool      `json:"disabled" db:"disabled"`     // Whether the user is not allowed to sign in
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // Registration time of the user
+}
+
+// UpdateUserInput represents changes of a user account made by an administrator.
+// Fields that are nil are left unchanged.
+type UpdateUserInput struct {
+	Role     *string `json:"role,omitempty"`     // New role of the user
+	Disabled *bool   `json:"disabled,omitempty"` // Whether the user is not allowed to sign in
+	Password *string `json:"password,omitempty"` // New password of the user
+}
+
+// UsersPage represents a page of the user list.
+type UsersPage struct {
+	Users []UserAccount `json:"users"` // Users on the page
+	Total int           `json:"total"` // Total number of users
 }
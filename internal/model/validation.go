@@ -0,0 +1,42 @@
+package model
+
+import "strings"
+
+// FieldError describes why the value of a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`   // Name of the field as it appears in the request
+	Message string `json:"message"` // Reason the value was rejected
+}
+
+// ValidationError reports all invalid fields of an input at once.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return "invalid input: " + strings.Join(messages, "; ")
+}
+
+// Is reports ValidationError as an error of the ErrValidation kind.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Add records an invalid field.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns the error if any field has been recorded, and nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
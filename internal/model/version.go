@@ -0,0 +1,7 @@
+package model
+
+// Versions is the precondition of a conditional write: the write is applied only if the current version
+// of the resource is one of the listed versions. Empty Versions match any version, that is the write is
+// unconditional. The version of a movie or an actor is incremented whenever its representation changes,
+// including changes of the linked actors or movies that the representation embeds.
+type Versions []int
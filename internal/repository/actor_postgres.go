@@ -2,13 +2,18 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/avealice/filmhub/internal/model"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrActorNotFound = model.NewError(model.ErrNotFound, "actor_not_found", "actor not found")
+	ErrActorExists   = model.NewError(model.ErrConflict, "actor_exists", "actor with the same name, gender, and birth date already exists")
 )
 
 type ActorPostgres struct {
@@ -21,177 +26,265 @@ func NewActorPostgres(db *sqlx.DB) *ActorPostgres {
 	}
 }
 
-func (r *ActorPostgres) CreateActor(actor model.InputActor) (int, error) {
-	var existingActorID int
-	query := fmt.Sprintf("SELECT id FROM %s WHERE name = $1 AND gender = $2 AND birth_date = $3", actorsTable)
-	err := r.db.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&existingActorID)
-	if err != nil && err != sql.ErrNoRows {
-		return 0, err
-	}
-
-	if err == nil {
-		return 0, errors.New("actor with the same name, gender, and birth date already exists")
-	}
-
-	insertQuery := fmt.Sprintf("INSERT INTO %s (name, gender, birth_date) VALUES ($1, $2, $3) RETURNING id", actorsTable)
-	var insertedID int
-	err = r.db.QueryRow(insertQuery, actor.Name, actor.Gender, actor.BirthDate).Scan(&insertedID)
-	if err != nil {
-		return 0, err
-	}
+// CreateActor creates an actor together with the movies listed in it and returns the created actor.
+func (r *ActorPostgres) CreateActor(actor model.InputActor) (model.ActorWithMovies, error) {
+	var created model.ActorWithMovies
 
-	for _, movie := range actor.Movies {
-		var existingMovieID int
-		query := fmt.Sprintf("SELECT id FROM %s WHERE title = $1 AND description = $2 AND rating = $3 AND release_date = $4", moviesTable)
-		err := r.db.QueryRow(query, movie.Title, movie.Description, movie.Rating, movie.ReleaseDate).Scan(&existingMovieID)
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		var existingActorID int
+		query := fmt.Sprintf("SELECT id FROM %s WHERE name = $1 AND gender = $2 AND birth_date = $3", actorsTable)
+		err := tx.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&existingActorID)
 		if err != nil && err != sql.ErrNoRows {
-			return 0, err
+			return err
 		}
 
 		if err == nil {
-			continue
+			return ErrActorExists
 		}
 
-		movieQuery := fmt.Sprintf("INSERT INTO %s (title, description, rating, release_date) VALUES ($1, $2, $3, $4) RETURNING id", moviesTable)
-		var movieID int
-		err = r.db.QueryRow(movieQuery, movie.Title, movie.Description, movie.Rating, movie.ReleaseDate).Scan(&movieID)
+		insertQuery := fmt.Sprintf("INSERT INTO %s (name, gender, birth_date) VALUES ($1, $2, $3) RETURNING id", actorsTable)
+		var insertedID int
+		err = tx.QueryRow(insertQuery, actor.Name, actor.Gender, actor.BirthDate).Scan(&insertedID)
 		if err != nil {
-			return 0, err
+			return err
 		}
 
-		query = fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2)", movieActorTable)
-		_, err = r.db.Exec(query, movieID, insertedID)
-		if err != nil {
-			return 0, err
+		for _, movie := range actor.Movies {
+			if err := linkMovie(tx, insertedID, movie); err != nil {
+				return err
+			}
 		}
+
+		created, err = getActor(tx, insertedID)
+		return err
+	})
+	if err != nil {
+		return model.ActorWithMovies{}, err
 	}
 
-	return insertedID, nil
+	return created, nil
 }
 
-func (r *ActorPostgres) GetAllActors() ([]model.ActorWithMovies, error) {
-	var actorsWithMovies []model.ActorWithMovies
+func (r *ActorPostgres) GetAllActors(sort model.Sort, page model.Pagination) (model.ActorsPage, error) {
+	result := model.ActorsPage{Actors: []model.ActorWithMovies{}}
+
+	keys, err := sortKeys(sort, actorSortColumns)
+	if err != nil {
+		return result, err
+	}
+
+	var args queryArgs
+	var conditions []string
+
+	if page.After != nil {
+		condition, err := keysetCondition("a", sort, keys, page.After, &args)
+		if err != nil {
+			return result, err
+		}
+		conditions = append(conditions, condition)
+	}
 
 	query := fmt.Sprintf(`
-		SELECT a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') AS actor_birth_date,
+		SELECT a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') AS actor_birth_date, a.cursor_values,
 			   m.id AS movie_id, m.title AS movie_title, m.description AS movie_description,
 			   TO_CHAR(m.release_date, 'YYYY-MM-DD') AS movie_release_date, m.rating AS movie_rating
-		FROM %s a
+		FROM (
+			SELECT a.*, %s AS cursor_values
+			FROM %s a
+			%s
+			ORDER BY %s
+			LIMIT %s OFFSET %s
+		) a
 		LEFT JOIN %s ma ON a.id = ma.actor_id
 		LEFT JOIN %s m ON ma.movie_id = m.id
-	`, actorsTable, movieActorTable, moviesTable)
+		ORDER BY %s, m.id
+	`, cursorColumns("a", keys), actorsTable, whereClause(conditions), orderByClause("a", keys),
+		args.add(page.Limit+1), args.add(page.Offset),
+		movieActorTable, moviesTable, orderByClause("a", keys))
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 	defer rows.Close()
 
-	actorMap := make(map[int]model.ActorWithMovies)
+	actors := newActorCollector()
+	var cursors [][]string
 
 	for rows.Next() {
-		var actorID int
-		var actor model.Actor
-		var movie model.Movie
-		var actorBirthDateStr, releaseDateStr string
-
-		rows.Scan(&actorID, &actor.Name, &actor.Gender, &actorBirthDateStr,
-			&movie.ID, &movie.Title, &movie.Description, &releaseDateStr, &movie.Rating)
-
-		actorWithMovies, ok := actorMap[actorID]
-		if !ok {
-			actorWithMovies = model.ActorWithMovies{
-				ID:        actorID,
-				Name:      actor.Name,
-				Gender:    actor.Gender,
-				BirthDate: actorBirthDateStr,
-				Movies:    []model.Movie{},
-			}
-		}
+		var actor model.ActorWithMovies
+		var cursorValues []string
+		var movie nullableMovie
 
-		if movie.ID != 0 {
-			movie.ReleaseDate = releaseDateStr
-			actorWithMovies.Movies = append(actorWithMovies.Movies, movie)
+		err := rows.Scan(&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate, pq.Array(&cursorValues),
+			&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseDate, &movie.Rating)
+		if err != nil {
+			return result, err
 		}
 
-		actorMap[actorID] = actorWithMovies
+		if i := actors.add(actor, movie); i == len(cursors) {
+			cursors = append(cursors, cursorValues)
+		}
 	}
 
-	for _, actorWithMovies := range actorMap {
-		actorsWithMovies = append(actorsWithMovies, actorWithMovies)
+	if err := rows.Err(); err != nil {
+		return result, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	result.Actors = actors.actors
+	if len(result.Actors) > page.Limit {
+		result.Actors = result.Actors[:page.Limit]
+		last := result.Actors[page.Limit-1]
+		result.NextCursor = model.Cursor{Sort: sort.String(), Values: cursors[page.Limit-1], ID: last.ID}.Encode()
 	}
 
-	if len(actorsWithMovies) == 0 {
-		return nil, errors.New("movies not found")
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s a", actorsTable)
+	if err := r.db.Get(&result.Total, countQuery); err != nil {
+		return result, err
 	}
 
-	return actorsWithMovies, nil
+	return result, nil
 }
 
-func (r *ActorPostgres) Delete(actorID int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", actorsTable)
-	_, err := r.db.Exec(query, actorID)
+func (r *ActorPostgres) SearchActors(name string, threshold float64, limit int) ([]model.ActorSearchHit, error) {
+	query := fmt.Sprintf(`
+		WITH hits AS MATERIALIZED (
+			SELECT a.id, word_similarity($1, a.name) AS similarity
+			FROM %s a
+			WHERE $1 <%% a.name
+			ORDER BY similarity DESC, a.id
+			LIMIT $2
+		)
+		SELECT a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD'), h.similarity,
+			   m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating
+		FROM hits h
+		JOIN %s a ON a.id = h.id
+		LEFT JOIN %s ma ON a.id = ma.actor_id
+		LEFT JOIN %s m ON ma.movie_id = m.id
+		ORDER BY h.similarity DESC, a.id, m.id
+	`, actorsTable, actorsTable, movieActorTable, moviesTable)
+
+	hits := []model.ActorSearchHit{}
+
+	err := withSimilarityThreshold(r.db, threshold, func(tx *sqlx.Tx) error {
+		rows, err := tx.Query(query, name, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		actors := newActorCollector()
+
+		for rows.Next() {
+			var actor model.ActorWithMovies
+			var hit model.ActorSearchHit
+			var movie nullableMovie
+
+			err := rows.Scan(&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate, &hit.Similarity,
+				&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseDate, &movie.Rating)
+			if err != nil {
+				return err
+			}
+
+			if i := actors.add(actor, movie); i == len(hits) {
+				hits = append(hits, hit)
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i := range hits {
+			hits[i].ActorWithMovies = actors.actors[i]
+		}
+
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+
+	return hits, nil
+}
+
+// Delete deletes the actor if their version matches ifMatch. Deleting an actor that does not exist is not an error,
+// unless ifMatch is set: a missing actor has no version to match, so the precondition fails.
+func (r *ActorPostgres) Delete(actorID int, ifMatch model.Versions) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := touchFilmography(tx, actorID); err != nil {
+			return err
+		}
+
+		args := queryArgs{actorID}
+		query := fmt.Sprintf("DELETE FROM %s WHERE id = $1%s", actorsTable, versionCondition(ifMatch, &args))
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 && len(ifMatch) > 0 {
+			return ErrVersionMismatch
+		}
+
+		return nil
+	})
 }
 
 func (r *ActorPostgres) Get(actorID int) (model.ActorWithMovies, error) {
-	var actor model.ActorWithMovies
+	return getActor(r.db, actorID)
+}
 
+// getActor reads an actor with their movies through q, which is either the database or a transaction.
+func getActor(q sqlx.Queryer, actorID int) (model.ActorWithMovies, error) {
 	query := fmt.Sprintf(`
-        SELECT a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD'), m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating
+        SELECT a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD'), a.version, m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating
         FROM %s a
         LEFT JOIN %s ma ON a.id = ma.actor_id
         LEFT JOIN %s m ON ma.movie_id = m.id
         WHERE a.id = $1
+        ORDER BY m.id
     `, actorsTable, movieActorTable, moviesTable)
 
-	rows, err := r.db.Query(query, actorID)
+	rows, err := q.Query(query, actorID)
 	if err != nil {
-		return actor, err
+		return model.ActorWithMovies{}, err
 	}
 	defer rows.Close()
 
-	var actorFound bool
+	actors := newActorCollector()
 
 	for rows.Next() {
-		actorFound = true
-		var movie model.Movie
-		var movieID int
-		var actorBirthDateStr string
-		var releaseDateStr string
-
-		err := rows.Scan(&actor.ID, &actor.Name, &actor.Gender, &actorBirthDateStr, &movieID, &movie.Title, &movie.Description, &releaseDateStr, &movie.Rating)
-		actor.BirthDate = actorBirthDateStr
+		var actor model.ActorWithMovies
+		var movie nullableMovie
+
+		err := rows.Scan(&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate, &actor.Version,
+			&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseDate, &movie.Rating)
 		if err != nil {
-			continue
+			return model.ActorWithMovies{}, err
 		}
 
-		movie.ID = movieID
-		movie.ReleaseDate = releaseDateStr
-		actor.Movies = append(actor.Movies, movie)
+		actors.add(actor, movie)
 	}
 
-	if !actorFound {
-		return actor, errors.New("actor not found")
+	if err := rows.Err(); err != nil {
+		return model.ActorWithMovies{}, err
 	}
 
-	return actor, nil
-}
-
-func (r *ActorPostgres) Update(actorID int, data model.InputActor) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
+	if len(actors.actors) == 0 {
+		return model.ActorWithMovies{}, ErrActorNotFound
 	}
-	defer tx.Rollback()
 
+	return actors.actors[0], nil
+}
+
+// Update changes the fields of the actor that are not empty in data, if their version matches ifMatch, and returns
+// the updated actor.
+func (r *ActorPostgres) Update(actorID int, data model.InputActor, ifMatch model.Versions) (model.ActorWithMovies, error) {
 	var query strings.Builder
 	var params []interface{}
 
@@ -219,56 +312,92 @@ func (r *ActorPostgres) Update(actorID int, data model.InputActor) error {
 		paramIndex++
 	}
 
-	if query.Len() > len("UPDATE "+actorsTable+" SET ") {
-		queryString := query.String()
-		query.Reset()
-		query.WriteString(queryString[:len(queryString)-2])
-		query.WriteString(fmt.Sprintf(" WHERE id = $%d", paramIndex))
-		params = append(params, actorID)
-	}
+	query.WriteString(fmt.Sprintf("version = version + 1 WHERE id = $%d", paramIndex))
+	params = append(params, actorID)
 
-	_, err = tx.Exec(query.String(), params...)
-	if err != nil {
-		return err
-	}
+	args := queryArgs(params)
+	query.WriteString(versionCondition(ifMatch, &args))
+
+	var updated model.ActorWithMovies
 
-	if len(data.Movies) == 0 {
-		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE actor_id = $1", movieActorTable)
-		_, err = tx.Exec(deleteQuery, actorID)
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query.String(), args...)
 		if err != nil {
 			return err
 		}
-	}
 
-	for _, movie := range data.Movies {
-		var existingMovieID int
-		query := fmt.Sprintf("SELECT id FROM %s WHERE title = $1 AND description = $2 AND release_date = $3 AND rating = $4", moviesTable)
-		err := r.db.QueryRow(query, movie.Title, movie.Description, movie.ReleaseDate, movie.Rating).Scan(&existingMovieID)
-		if err != nil && err != sql.ErrNoRows {
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
 			return err
 		}
-
-		if err == nil {
-			continue
+		if rowsAffected == 0 {
+			return notFoundOrModified(tx, actorsTable, actorID, ErrActorNotFound)
 		}
 
-		movieQuery := fmt.Sprintf("INSERT INTO %s (title, description, release_date, rating) VALUES ($1, $2, $3, $4) RETURNING id", moviesTable)
-		err = r.db.QueryRow(movieQuery, movie.Title, movie.Description, movie.ReleaseDate, movie.Rating).Scan(&movie.ID)
-		if err != nil {
+		if err := touchFilmography(tx, actorID); err != nil {
 			return err
 		}
 
-		movieActorQuery := fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2)", movieActorTable)
-		_, err = r.db.Exec(movieActorQuery, movie.ID, actorID)
-		if err != nil {
+		if len(data.Movies) == 0 {
+			deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE actor_id = $1", movieActorTable)
+			_, err = tx.Exec(deleteQuery, actorID)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, movie := range data.Movies {
+			if err := linkMovie(tx, actorID, movie); err != nil {
+				return err
+			}
+		}
+
+		updated, err = getActor(tx, actorID)
+		return err
+	})
+	if err != nil {
+		return model.ActorWithMovies{}, err
+	}
+
+	return updated, nil
+}
+
+// linkMovie adds the movie to the filmography of the actor, creating the movie if it does not exist yet.
+// The movie is matched by all of its fields, the same way as when it is created.
+func linkMovie(tx *sqlx.Tx, actorID int, movie model.Movie) error {
+	var movieID int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE title = $1 AND description = $2 AND release_date = $3 AND rating = $4", moviesTable)
+	err := tx.QueryRow(query, movie.Title, movie.Description, movie.ReleaseDate, movie.Rating).Scan(&movieID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == sql.ErrNoRows {
+		query := fmt.Sprintf("INSERT INTO %s (title, description, release_date, rating) VALUES ($1, $2, $3, $4) RETURNING id", moviesTable)
+		if err := tx.QueryRow(query, movie.Title, movie.Description, movie.ReleaseDate, movie.Rating).Scan(&movieID); err != nil {
 			return err
 		}
 	}
 
-	err = tx.Commit()
+	query = fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", movieActorTable)
+	res, err := tx.Exec(query, movieID, actorID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// The caller creates or updates the actor itself, so only the version of the movie is incremented here.
+	if linked, err := res.RowsAffected(); err != nil || linked == 0 {
+		return err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id = $1", moviesTable)
+	_, err = tx.Exec(query, movieID)
+	return err
+}
+
+// touchFilmography increments the versions of the movies of the actor, whose representations embed them.
+func touchFilmography(tx *sqlx.Tx, actorID int) error {
+	query := fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id IN (SELECT movie_id FROM %s WHERE actor_id = $1)", moviesTable, movieActorTable)
+	_, err := tx.Exec(query, actorID)
+	return err
 }
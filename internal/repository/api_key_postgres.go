@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var ErrAPIKeyNotFound = model.NewError(model.ErrNotFound, "api_key_not_found", "api key not found")
+
+// apiKeyUsageInterval limits how often the last usage time of a key is written,
+// so that a busy key does not update its row on every request.
+const apiKeyUsageInterval = "1 minute"
+
+const apiKeyColumns = "id, name, prefix, user_id, permissions, expires_at, last_used_at, created_at, revoked_at"
+
+type APIKeyPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyPostgres(db *sqlx.DB) *APIKeyPostgres {
+	return &APIKeyPostgres{db: db}
+}
+
+// apiKeyRow is an api_keys row; permissions are scanned from a Postgres array.
+type apiKeyRow struct {
+	model.APIKey
+	Permissions pq.StringArray `db:"permissions"`
+}
+
+func (row apiKeyRow) toModel() model.APIKey {
+	key := row.APIKey
+	key.Permissions = []string(row.Permissions)
+	if key.Permissions == nil {
+		key.Permissions = []string{}
+	}
+
+	return key
+}
+
+func (r *APIKeyPostgres) CreateAPIKey(key model.APIKey, keyHash string) (model.APIKey, error) {
+	var row apiKeyRow
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name, prefix, key_hash, user_id, permissions, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING %s`, apiKeysTable, apiKeyColumns)
+
+	err := r.db.Get(&row, query, key.Name, key.Prefix, keyHash, key.UserID, pq.Array(key.Permissions), key.ExpiresAt)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *APIKeyPostgres) GetAllAPIKeys() ([]model.APIKey, error) {
+	var rows []apiKeyRow
+	query := fmt.Sprintf("SELECT %s FROM %s ORDER BY id", apiKeyColumns, apiKeysTable)
+
+	if err := r.db.Select(&rows, query); err != nil {
+		return nil, err
+	}
+
+	keys := make([]model.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.toModel())
+	}
+
+	return keys, nil
+}
+
+// GetActiveAPIKey returns the key with the given hash if it is neither revoked nor expired.
+func (r *APIKeyPostgres) GetActiveAPIKey(keyHash string) (model.APIKey, error) {
+	var row apiKeyRow
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE key_hash=$1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())`,
+		apiKeyColumns, apiKeysTable)
+
+	err := r.db.Get(&row, query, keyHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.APIKey{}, ErrAPIKeyNotFound
+		}
+		return model.APIKey{}, err
+	}
+
+	return row.toModel(), nil
+}
+
+// TouchAPIKey records that the key has just been used.
+func (r *APIKeyPostgres) TouchAPIKey(keyID int) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET last_used_at=now()
+		WHERE id=$1 AND (last_used_at IS NULL OR last_used_at < now() - interval '%s')`,
+		apiKeysTable, apiKeyUsageInterval)
+
+	_, err := r.db.Exec(query, keyID)
+	return err
+}
+
+func (r *APIKeyPostgres) RevokeAPIKey(keyID int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at=now() WHERE id=$1 AND revoked_at IS NULL", apiKeysTable)
+
+	res, err := r.db.Exec(query, keyID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -4,10 +4,22 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/avealice/filmhub/internal/model"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound         = model.NewError(model.ErrNotFound, "user_not_found", "user not found")
+	ErrUnknownRole          = model.NewError(model.ErrValidation, "unknown_role", "unknown role")
+	ErrUsernameTaken        = model.NewError(model.ErrConflict, "username_taken", "username is already taken")
+	ErrResetTokenNotFound   = errors.New("password reset token not found")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenReused   = errors.New("refresh token has already been used")
 )
 
 type AuthPostgres struct {
@@ -27,23 +39,397 @@ func (r *AuthPostgres) CreateUser(user model.User) (int, error) {
 
 	row := r.db.QueryRow(query, user.Username, user.Password, user.Role)
 	if err := row.Scan(&id); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return -1, ErrUsernameTaken
+		}
 		return -1, err
 	}
 
 	return id, nil
 }
 
-func (r *AuthPostgres) GetUser(username, password string) (model.User, error) {
+func (r *AuthPostgres) GetUser(username string) (model.User, error) {
+	var user model.User
+	query := fmt.Sprintf("SELECT id, username, password_hash, role, disabled, sessions_revoked_at FROM %s WHERE username=$1", usersTable)
+
+	err := r.db.Get(&user, query, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *AuthPostgres) UpdatePasswordHash(userID int, passwordHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash=$1 WHERE id=$2", usersTable)
+
+	_, err := r.db.Exec(query, passwordHash, userID)
+	return err
+}
+
+func (r *AuthPostgres) GetUserByID(userID int) (model.User, error) {
 	var user model.User
-	query := fmt.Sprintf("SELECT id, role FROM %s WHERE username=$1 AND password_hash=$2", usersTable)
+	query := fmt.Sprintf("SELECT id, username, password_hash, role, disabled, sessions_revoked_at FROM %s WHERE id=$1", usersTable)
 
-	err := r.db.Get(&user, query, username, password)
+	err := r.db.Get(&user, query, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return model.User{}, errors.New("user not found")
+			return model.User{}, ErrUserNotFound
 		}
 		return model.User{}, err
 	}
 
 	return user, nil
 }
+
+// GetUserByIdentity returns the user linked to the subject of an external identity provider.
+func (r *AuthPostgres) GetUserByIdentity(issuer, subject string) (model.User, error) {
+	var user model.User
+	query := fmt.Sprintf(`
+		SELECT u.id, u.username, u.password_hash, u.role, u.disabled, u.sessions_revoked_at
+		FROM %s u
+		JOIN %s i ON i.user_id = u.id
+		WHERE i.issuer=$1 AND i.subject=$2`, usersTable, userIdentitiesTable)
+
+	err := r.db.Get(&user, query, issuer, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// CreateUserWithIdentity registers a user with the given role and links it to the subject
+// of an external identity provider. The user has no password and can only sign in through the provider.
+func (r *AuthPostgres) CreateUserWithIdentity(user model.User, issuer, subject string) (int, error) {
+	var id int
+
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		query := fmt.Sprintf("INSERT INTO %s (username, password_hash, role) VALUES ($1, '', $2) RETURNING id", usersTable)
+		if err := tx.Get(&id, query, user.Username, user.Role); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) {
+				switch pqErr.Code {
+				case "23505":
+					return ErrUsernameTaken
+				case "23503":
+					return ErrUnknownRole
+				}
+			}
+			return err
+		}
+
+		query = fmt.Sprintf("INSERT INTO %s (user_id, issuer, subject) VALUES ($1, $2, $3)", userIdentitiesTable)
+		_, err := tx.Exec(query, id, issuer, subject)
+		return err
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (r *AuthPostgres) CreateRefreshToken(token model.RefreshToken) error {
+	query := fmt.Sprintf("INSERT INTO %s (token_hash, user_id, family_id, expires_at) VALUES ($1, $2, $3, $4)", refreshTokensTable)
+
+	_, err := r.db.Exec(query, token.TokenHash, token.UserID, token.FamilyID, token.ExpiresAt)
+	return err
+}
+
+// RotateRefreshToken exchanges the refresh token with the given hash for next, which inherits
+// its user and family. A token can be exchanged only once: presenting a rotated or revoked
+// token again means it has leaked, so the whole family is revoked and ErrRefreshTokenReused is returned.
+func (r *AuthPostgres) RotateRefreshToken(tokenHash string, next model.RefreshToken) (model.RefreshToken, error) {
+	reused := false
+
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		var current struct {
+			model.RefreshToken
+			RotatedAt sql.NullTime `db:"rotated_at"`
+			RevokedAt sql.NullTime `db:"revoked_at"`
+		}
+
+		query := fmt.Sprintf(`SELECT id, token_hash, user_id, family_id, expires_at, rotated_at, revoked_at
+			FROM %s WHERE token_hash=$1 FOR UPDATE`, refreshTokensTable)
+		if err := tx.Get(&current, query, tokenHash); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrRefreshTokenNotFound
+			}
+			return err
+		}
+
+		if current.RotatedAt.Valid || current.RevokedAt.Valid {
+			// The family is revoked in the same transaction, which is committed before ErrRefreshTokenReused is returned.
+			reused = true
+			query := fmt.Sprintf("UPDATE %s SET revoked_at=now() WHERE family_id=$1 AND revoked_at IS NULL", refreshTokensTable)
+			_, err := tx.Exec(query, current.FamilyID)
+			return err
+		}
+
+		if !current.ExpiresAt.After(time.Now()) {
+			return ErrRefreshTokenNotFound
+		}
+
+		query = fmt.Sprintf("UPDATE %s SET rotated_at=now() WHERE id=$1", refreshTokensTable)
+		if _, err := tx.Exec(query, current.ID); err != nil {
+			return err
+		}
+
+		next.UserID = current.UserID
+		next.FamilyID = current.FamilyID
+
+		query = fmt.Sprintf("INSERT INTO %s (token_hash, user_id, family_id, expires_at) VALUES ($1, $2, $3, $4) RETURNING id", refreshTokensTable)
+		return tx.QueryRow(query, next.TokenHash, next.UserID, next.FamilyID, next.ExpiresAt).Scan(&next.ID)
+	})
+	if err != nil {
+		return model.RefreshToken{}, err
+	}
+	if reused {
+		return model.RefreshToken{}, ErrRefreshTokenReused
+	}
+
+	return next, nil
+}
+
+// RevokeRefreshTokenFamily revokes the refresh token with the given hash together with
+// all tokens of its family. It returns ErrRefreshTokenNotFound if the token does not
+// belong to the user.
+func (r *AuthPostgres) RevokeRefreshTokenFamily(userID int, tokenHash string) error {
+	query := fmt.Sprintf(`UPDATE %[1]s SET revoked_at=now()
+		WHERE family_id=(SELECT family_id FROM %[1]s WHERE token_hash=$1 AND user_id=$2) AND revoked_at IS NULL`, refreshTokensTable)
+
+	res, err := r.db.Exec(query, tokenHash, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRefreshTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeAccessToken puts the access token identifier on the denylist until the token expires.
+// Entries of tokens that have already expired are removed at the same time.
+func (r *AuthPostgres) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		query := fmt.Sprintf("DELETE FROM %s WHERE expires_at < now()", revokedAccessTokensTable)
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+
+		query = fmt.Sprintf("INSERT INTO %s (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING", revokedAccessTokensTable)
+		_, err := tx.Exec(query, tokenID, expiresAt)
+		return err
+	})
+}
+
+func (r *AuthPostgres) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	var revoked bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE jti=$1)", revokedAccessTokensTable)
+
+	err := r.db.Get(&revoked, query, tokenID)
+	return revoked, err
+}
+
+func (r *AuthPostgres) GetRolePermissions(role string) ([]string, error) {
+	permissions := []string{}
+	query := fmt.Sprintf("SELECT permission FROM %s WHERE role=$1 ORDER BY permission", rolePermissionsTable)
+
+	if err := r.db.Select(&permissions, query, role); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (r *AuthPostgres) GetAllUsers(limit, offset int) (model.UsersPage, error) {
+	page := model.UsersPage{Users: []model.UserAccount{}}
+
+	query := fmt.Sprintf("SELECT id, username, role, disabled, created_at FROM %s ORDER BY id LIMIT $1 OFFSET $2", usersTable)
+	if err := r.db.Select(&page.Users, query, limit, offset); err != nil {
+		return model.UsersPage{}, err
+	}
+
+	query = fmt.Sprintf("SELECT COUNT(*) FROM %s", usersTable)
+	if err := r.db.Get(&page.Total, query); err != nil {
+		return model.UsersPage{}, err
+	}
+
+	return page, nil
+}
+
+func (r *AuthPostgres) GetUserAccount(userID int) (model.UserAccount, error) {
+	var user model.UserAccount
+	query := fmt.Sprintf("SELECT id, username, role, disabled, created_at FROM %s WHERE id=$1", usersTable)
+
+	err := r.db.Get(&user, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.UserAccount{}, ErrUserNotFound
+		}
+		return model.UserAccount{}, err
+	}
+
+	return user, nil
+}
+
+// UpdateUser applies the non-nil fields of input to the user. input.Password must already
+// be hashed. Disabling the user or changing the password revokes all refresh tokens of the user.
+func (r *AuthPostgres) UpdateUser(userID int, input model.UpdateUserInput) error {
+	var args queryArgs
+	var set []string
+
+	if input.Role != nil {
+		set = append(set, "role="+args.add(*input.Role))
+	}
+	if input.Disabled != nil {
+		set = append(set, "disabled="+args.add(*input.Disabled))
+	}
+	if input.Password != nil {
+		// Access tokens issued under the old password are rejected, like after a self-service reset.
+		set = append(set, "password_hash="+args.add(*input.Password), "sessions_revoked_at=now()")
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id=%s", usersTable, strings.Join(set, ", "), args.add(userID))
+
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+				return ErrUnknownRole
+			}
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		if (input.Disabled != nil && *input.Disabled) || input.Password != nil {
+			query := fmt.Sprintf("UPDATE %s SET revoked_at=now() WHERE user_id=$1 AND revoked_at IS NULL", refreshTokensTable)
+			if _, err := tx.Exec(query, userID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *AuthPostgres) DeleteUser(userID int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", usersTable)
+
+	res, err := r.db.Exec(query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password hash of the user and revokes all of their sessions:
+// refresh tokens are revoked and access tokens issued before revokedAt stop being accepted.
+func (r *AuthPostgres) ChangePassword(userID int, passwordHash string, revokedAt time.Time) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		return changePassword(tx, userID, passwordHash, revokedAt)
+	})
+}
+
+func (r *AuthPostgres) CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time) error {
+	query := fmt.Sprintf("INSERT INTO %s (token_hash, user_id, expires_at) VALUES ($1, $2, $3)", passwordResetTokensTable)
+
+	_, err := r.db.Exec(query, tokenHash, userID, expiresAt)
+	return err
+}
+
+// GetPasswordResetTokenUser returns the user of an unused and unexpired reset token.
+func (r *AuthPostgres) GetPasswordResetTokenUser(tokenHash string) (int, error) {
+	var userID int
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE token_hash=$1 AND used_at IS NULL AND expires_at > now()", passwordResetTokensTable)
+
+	err := r.db.Get(&userID, query, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrResetTokenNotFound
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// ResetPassword redeems a reset token: the token and every other outstanding reset token
+// of the user are used up, and the password is changed as by ChangePassword.
+func (r *AuthPostgres) ResetPassword(tokenHash, passwordHash string, revokedAt time.Time) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		var userID int
+		query := fmt.Sprintf(`UPDATE %s SET used_at=now()
+			WHERE token_hash=$1 AND used_at IS NULL AND expires_at > now()
+			RETURNING user_id`, passwordResetTokensTable)
+		if err := tx.Get(&userID, query, tokenHash); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrResetTokenNotFound
+			}
+			return err
+		}
+
+		query = fmt.Sprintf("UPDATE %s SET used_at=now() WHERE user_id=$1 AND used_at IS NULL", passwordResetTokensTable)
+		if _, err := tx.Exec(query, userID); err != nil {
+			return err
+		}
+
+		return changePassword(tx, userID, passwordHash, revokedAt)
+	})
+}
+
+func changePassword(tx *sqlx.Tx, userID int, passwordHash string, revokedAt time.Time) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash=$1, sessions_revoked_at=$2 WHERE id=$3", usersTable)
+	res, err := tx.Exec(query, passwordHash, revokedAt, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET revoked_at=now() WHERE user_id=$1 AND revoked_at IS NULL", refreshTokensTable)
+	_, err = tx.Exec(query, userID)
+	return err
+}
@@ -2,13 +2,22 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
+	"html"
+	"slices"
 	"strings"
 
 	"github.com/avealice/filmhub/internal/model"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrMovieNotFound = model.NewError(model.ErrNotFound, "movie_not_found", "movie not found")
+	ErrMovieExists   = model.NewError(model.ErrConflict, "movie_exists", "movie with the same title, description, rating, and release date already exists")
+
+	ErrMovieActorNotFound = model.NewError(model.ErrNotFound, "movie_actor_not_found", "actor is not in the cast of the movie")
 )
 
 type MoviePostgres struct {
@@ -21,358 +30,600 @@ func NewMoviePostgres(db *sqlx.DB) *MoviePostgres {
 	}
 }
 
-func (r *MoviePostgres) GetAllMovies(sortBy, sortOrder string) ([]model.MovieWithActors, error) {
-	var moviesWithActors []model.MovieWithActors
+func (r *MoviePostgres) GetAllMovies(filter model.MovieFilter, sort model.Sort, page model.Pagination) (model.MoviesPage, error) {
+	result := model.MoviesPage{Movies: []model.MovieWithActors{}}
+
+	keys, err := sortKeys(sort, movieSortColumns)
+	if err != nil {
+		return result, err
+	}
+
+	var args queryArgs
+	conditions := movieFilterConditions("m", filter, &args)
+
+	if page.After != nil {
+		condition, err := keysetCondition("m", sort, keys, page.After, &args)
+		if err != nil {
+			return result, err
+		}
+		conditions = append(conditions, condition)
+	}
 
 	query := fmt.Sprintf(`
-		SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating,
+		SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating, m.cursor_values,
 			   a.id AS actor_id, a.name AS actor_name, a.gender AS actor_gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') AS actor_birth_date
-		FROM %s m
+		FROM (
+			SELECT m.*, %s AS cursor_values
+			FROM %s m
+			%s
+			ORDER BY %s
+			LIMIT %s OFFSET %s
+		) m
 		LEFT JOIN %s ma ON m.id = ma.movie_id
 		LEFT JOIN %s a ON ma.actor_id = a.id
-		ORDER BY %s %s
-	`, moviesTable, movieActorTable, actorsTable, sortBy, sortOrder)
+		ORDER BY %s, a.id
+	`, cursorColumns("m", keys), moviesTable, whereClause(conditions), orderByClause("m", keys),
+		args.add(page.Limit+1), args.add(page.Offset),
+		movieActorTable, actorsTable, orderByClause("m", keys))
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 	defer rows.Close()
 
-	movieMap := make(map[int]model.MovieWithActors)
+	movies := newMovieCollector()
+	var cursors [][]string
 
 	for rows.Next() {
-		var movieID int
-		var movie model.Movie
-		var actor model.Actor
-		var releaseDateStr string
-		var actorBirthDateStr string
-
-		rows.Scan(&movieID, &movie.Title, &movie.Description, &releaseDateStr, &movie.Rating,
-			&actor.ID, &actor.Name, &actor.Gender, &actorBirthDateStr)
-
-		movieWithActors, ok := movieMap[movieID]
-		if !ok {
-			movieWithActors = model.MovieWithActors{
-				ID:          movieID,
-				Title:       movie.Title,
-				Description: movie.Description,
-				ReleaseDate: releaseDateStr,
-				Rating:      movie.Rating,
-				Actors:      []model.Actor{},
-			}
-		}
+		var movie model.MovieWithActors
+		var description sql.NullString
+		var cursorValues []string
+		var actor nullableActor
 
-		if actor.ID != 0 {
-			actor.BirthDate = actorBirthDateStr
-			movieWithActors.Actors = append(movieWithActors.Actors, actor)
+		err := rows.Scan(&movie.ID, &movie.Title, &description, &movie.ReleaseDate, &movie.Rating, pq.Array(&cursorValues),
+			&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate)
+		if err != nil {
+			return result, err
 		}
 
-		movieMap[movieID] = movieWithActors
-	}
-
-	for _, movieWithActors := range movieMap {
-		moviesWithActors = append(moviesWithActors, movieWithActors)
+		movie.Description = description.String
+		if i := movies.add(movie, actor); i == len(cursors) {
+			cursors = append(cursors, cursorValues)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return result, err
 	}
 
-	if len(moviesWithActors) == 0 {
-		return nil, errors.New("movies not found")
+	result.Movies = movies.movies
+	if len(result.Movies) > page.Limit {
+		result.Movies = result.Movies[:page.Limit]
+		last := result.Movies[page.Limit-1]
+		result.NextCursor = model.Cursor{Sort: sort.String(), Values: cursors[page.Limit-1], ID: last.ID}.Encode()
 	}
 
-	return moviesWithActors, nil
-}
-
-func (r *MoviePostgres) CreateMovie(movie model.InputMovie) error {
-	var existingMovieID int
-	query := fmt.Sprintf("SELECT id FROM %s WHERE title = $1 AND description = $2 AND rating = $3 AND release_date = $4", moviesTable)
-	err := r.db.QueryRow(query, movie.Title, movie.Description, movie.Rating, movie.ReleaseDate).Scan(&existingMovieID)
-	if err != nil && err != sql.ErrNoRows {
-		return err
+	var countArgs queryArgs
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s m %s", moviesTable, whereClause(movieFilterConditions("m", filter, &countArgs)))
+	if err := r.db.Get(&result.Total, countQuery, countArgs...); err != nil {
+		return result, err
 	}
 
-	if err == nil {
-		return errors.New("movie with the same title, description, rating, and release date already exists")
-	}
+	return result, nil
+}
 
-	movieQuery := fmt.Sprintf("INSERT INTO %s (title, description, rating, release_date) VALUES ($1, $2, $3, $4) RETURNING id", moviesTable)
-	var movieID int
-	err = r.db.QueryRow(movieQuery, movie.Title, movie.Description, movie.Rating, movie.ReleaseDate).Scan(&movieID)
-	if err != nil {
-		return err
-	}
+// CreateMovie creates a movie together with the actors listed in it and returns the created movie.
+func (r *MoviePostgres) CreateMovie(movie model.InputMovie) (model.MovieWithActors, error) {
+	var created model.MovieWithActors
 
-	for _, actor := range movie.Actors {
-		var actorID int
-		query := fmt.Sprintf("SELECT id FROM %s WHERE LOWER(name) = LOWER($1) AND LOWER(gender) = LOWER($2) AND birth_date = $3", actorsTable)
-		err := r.db.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&actorID)
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		var existingMovieID int
+		query := fmt.Sprintf("SELECT id FROM %s WHERE title = $1 AND description = $2 AND rating = $3 AND release_date = $4", moviesTable)
+		err := tx.QueryRow(query, movie.Title, movie.Description, movie.Rating, movie.ReleaseDate).Scan(&existingMovieID)
 		if err != nil && err != sql.ErrNoRows {
 			return err
 		}
 
-		if err == sql.ErrNoRows {
-			query := fmt.Sprintf("INSERT INTO %s (name, gender, birth_date) VALUES ($1, $2, $3) RETURNING id", actorsTable)
-			err := r.db.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&actorID)
-			if err != nil {
-				return err
-			}
+		if err == nil {
+			return ErrMovieExists
 		}
 
-		query = fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2)", movieActorTable)
-		_, err = r.db.Exec(query, movieID, actorID)
+		movieQuery := fmt.Sprintf("INSERT INTO %s (title, description, rating, release_date) VALUES ($1, $2, $3, $4) RETURNING id", moviesTable)
+		var movieID int
+		err = tx.QueryRow(movieQuery, movie.Title, movie.Description, movie.Rating, movie.ReleaseDate).Scan(&movieID)
 		if err != nil {
 			return err
 		}
+
+		for _, actor := range movie.Actors {
+			if err := linkActor(tx, movieID, actor); err != nil {
+				return err
+			}
+		}
+
+		created, err = getMovieByID(tx, movieID)
+		return err
+	})
+	if err != nil {
+		return model.MovieWithActors{}, err
 	}
 
-	return nil
+	return created, nil
 }
 
 func (r *MoviePostgres) GetMovieByID(movieID int) (model.MovieWithActors, error) {
-	var movie model.MovieWithActors
+	return getMovieByID(r.db, movieID)
+}
 
+// getMovieByID reads a movie with its actors through q, which is either the database or a transaction.
+func getMovieByID(q sqlx.Queryer, movieID int) (model.MovieWithActors, error) {
 	query := fmt.Sprintf(`
-        SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating, a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD')
+        SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating, m.version, a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD')
         FROM %s m
         LEFT JOIN %s ma ON m.id = ma.movie_id
         LEFT JOIN %s a ON ma.actor_id = a.id
         WHERE m.id = $1
+        ORDER BY a.id
     `, moviesTable, movieActorTable, actorsTable)
 
-	rows, err := r.db.Query(query, movieID)
+	rows, err := q.Query(query, movieID)
 	if err != nil {
-		return movie, err
+		return model.MovieWithActors{}, err
 	}
 	defer rows.Close()
 
-	var movieFound bool
-	actorsMap := make(map[int]model.Actor)
+	movies := newMovieCollector()
 
 	for rows.Next() {
-		movieFound = true
-		var actor model.Actor
-		var actorID int
-		var birthDateStr string
-		var releaseDateStr string
-
-		err := rows.Scan(&movie.ID, &movie.Title, &movie.Description, &releaseDateStr, &movie.Rating, &actorID, &actor.Name, &actor.Gender, &birthDateStr)
-		movie.ReleaseDate = releaseDateStr
+		var movie model.MovieWithActors
+		var description sql.NullString
+		var actor nullableActor
+
+		err := rows.Scan(&movie.ID, &movie.Title, &description, &movie.ReleaseDate, &movie.Rating, &movie.Version,
+			&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate)
 		if err != nil {
-			continue
+			return model.MovieWithActors{}, err
 		}
 
-		if _, ok := actorsMap[actorID]; !ok {
-			actorsMap[actorID] = actor
-			actor.ID = actorID
-			actor.BirthDate = birthDateStr
-			movie.Actors = append(movie.Actors, actor)
-		}
+		movie.Description = description.String
+		movies.add(movie, actor)
+	}
 
+	if err := rows.Err(); err != nil {
+		return model.MovieWithActors{}, err
 	}
 
-	if !movieFound {
-		return movie, errors.New("movie not found")
+	if len(movies.movies) == 0 {
+		return model.MovieWithActors{}, ErrMovieNotFound
 	}
 
-	return movie, nil
+	return movies.movies[0], nil
 }
 
-func (r *MoviePostgres) DeleteByID(movieID int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", moviesTable)
-	_, err := r.db.Exec(query, movieID)
-	if err != nil {
-		return err
-	}
-	return nil
+// DeleteByID deletes the movie if its version matches ifMatch. Deleting a movie that does not exist is not an error,
+// unless ifMatch is set: a missing movie has no version to match, so the precondition fails.
+func (r *MoviePostgres) DeleteByID(movieID int, ifMatch model.Versions) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := touchCast(tx, movieID); err != nil {
+			return err
+		}
+
+		args := queryArgs{movieID}
+		query := fmt.Sprintf("DELETE FROM %s WHERE id = $1%s", moviesTable, versionCondition(ifMatch, &args))
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 && len(ifMatch) > 0 {
+			return ErrVersionMismatch
+		}
+
+		return nil
+	})
 }
 
-func (r *MoviePostgres) UpdateMovie(movieID int, data model.InputMovie) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+// UpdateMovie replaces all fields and the cast of the movie, if its version matches ifMatch, and returns
+// the updated movie. Actors of the new cast that do not exist yet are created.
+func (r *MoviePostgres) UpdateMovie(movieID int, data model.InputMovie, ifMatch model.Versions) (model.MovieWithActors, error) {
+	var updated model.MovieWithActors
+
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		args := queryArgs{data.Title, data.Description, data.ReleaseDate, data.Rating, movieID}
+		query := fmt.Sprintf("UPDATE %s SET title = $1, description = $2, release_date = $3, rating = $4, version = version + 1 WHERE id = $5%s",
+			moviesTable, versionCondition(ifMatch, &args))
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return notFoundOrModified(tx, moviesTable, movieID, ErrMovieNotFound)
+		}
 
-	var query strings.Builder
-	var params []interface{}
+		// Both the actors leaving the cast and the ones staying in it embed the changed movie.
+		if err := touchCast(tx, movieID); err != nil {
+			return err
+		}
 
-	query.WriteString("UPDATE ")
-	query.WriteString(moviesTable)
-	query.WriteString(" SET ")
+		query = fmt.Sprintf("DELETE FROM %s WHERE movie_id = $1", movieActorTable)
+		if _, err := tx.Exec(query, movieID); err != nil {
+			return err
+		}
 
-	paramIndex := 1
+		for _, actor := range data.Actors {
+			if err := linkActor(tx, movieID, actor); err != nil {
+				return err
+			}
+		}
 
-	if data.Title != "" {
-		query.WriteString(fmt.Sprintf("title = $%d, ", paramIndex))
-		params = append(params, data.Title)
-		paramIndex++
+		updated, err = getMovieByID(tx, movieID)
+		return err
+	})
+	if err != nil {
+		return model.MovieWithActors{}, err
 	}
 
-	if data.Description != "" {
-		query.WriteString(fmt.Sprintf("description = $%d, ", paramIndex))
-		params = append(params, data.Description)
-		paramIndex++
-	}
+	return updated, nil
+}
 
-	if data.ReleaseDate != "" {
-		query.WriteString(fmt.Sprintf("release_date = $%d, ", paramIndex))
-		params = append(params, data.ReleaseDate)
-		paramIndex++
-	}
+// PatchMovie changes the fields of the movie that are set in input, if its version matches ifMatch, and returns
+// the updated movie.
+func (r *MoviePostgres) PatchMovie(movieID int, input model.PatchMovieInput, ifMatch model.Versions) (model.MovieWithActors, error) {
+	var args queryArgs
+	var set []string
 
-	if data.Rating != 0 {
-		query.WriteString(fmt.Sprintf("rating = $%d, ", paramIndex))
-		params = append(params, data.Rating)
-		paramIndex++
+	if input.Title != nil {
+		set = append(set, "title = "+args.add(*input.Title))
 	}
-
-	if query.Len() > len("UPDATE "+moviesTable+" SET ") {
-		queryString := query.String()
-		query.Reset()
-		query.WriteString(queryString[:len(queryString)-2])
-		query.WriteString(fmt.Sprintf(" WHERE id = $%d", paramIndex))
-		params = append(params, movieID)
+	if input.Description != nil {
+		set = append(set, "description = "+args.add(*input.Description))
+	}
+	if input.ReleaseDate != nil {
+		set = append(set, "release_date = "+args.add(*input.ReleaseDate))
+	}
+	if input.Rating != nil {
+		set = append(set, "rating = "+args.add(*input.Rating))
 	}
 
-	_, err = tx.Exec(query.String(), params...)
-	if err != nil {
-		return err
+	if len(set) == 0 {
+		movie, err := r.GetMovieByID(movieID)
+		if err != nil {
+			return model.MovieWithActors{}, err
+		}
+		if len(ifMatch) > 0 && !slices.Contains(ifMatch, movie.Version) {
+			return model.MovieWithActors{}, ErrVersionMismatch
+		}
+		return movie, nil
 	}
+	set = append(set, "version = version + 1")
+
+	var updated model.MovieWithActors
+
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE id = %s", moviesTable, strings.Join(set, ", "), args.add(movieID))
+		query += versionCondition(ifMatch, &args)
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
 
-	if len(data.Actors) == 0 {
-		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE movie_id = $1", movieActorTable)
-		_, err = tx.Exec(deleteQuery, movieID)
+		rowsAffected, err := res.RowsAffected()
 		if err != nil {
 			return err
 		}
+		if rowsAffected == 0 {
+			return notFoundOrModified(tx, moviesTable, movieID, ErrMovieNotFound)
+		}
+
+		if err := touchCast(tx, movieID); err != nil {
+			return err
+		}
+
+		updated, err = getMovieByID(tx, movieID)
+		return err
+	})
+	if err != nil {
+		return model.MovieWithActors{}, err
 	}
 
-	for _, actor := range data.Actors {
-		var existingActorID int
-		query := fmt.Sprintf("SELECT id FROM %s WHERE name = $1 AND gender = $2 AND birth_date = $3", actorsTable)
-		err := r.db.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&existingActorID)
-		if err != nil && err != sql.ErrNoRows {
+	return updated, nil
+}
+
+// AddActor adds the actor to the cast of the movie. Adding an actor who is already in the cast has no effect.
+func (r *MoviePostgres) AddActor(movieID, actorID int) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		var exists bool
+		query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", moviesTable)
+		if err := tx.Get(&exists, query, movieID); err != nil {
 			return err
 		}
+		if !exists {
+			return ErrMovieNotFound
+		}
 
-		if err == nil {
-			continue
+		query = fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", actorsTable)
+		if err := tx.Get(&exists, query, actorID); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrActorNotFound
+		}
+
+		query = fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", movieActorTable)
+		res, err := tx.Exec(query, movieID, actorID)
+		if err != nil {
+			return err
 		}
 
-		actorQuery := fmt.Sprintf("INSERT INTO %s (name, gender, birth_date) VALUES ($1, $2, $3) RETURNING id", actorsTable)
-		err = r.db.QueryRow(actorQuery, actor.Name, actor.Gender, actor.BirthDate).Scan(&actor.ID)
+		return touchLinked(tx, res, movieID, actorID)
+	})
+}
+
+// RemoveActor removes the actor from the cast of the movie.
+// It returns ErrMovieActorNotFound if the actor is not in the cast.
+func (r *MoviePostgres) RemoveActor(movieID, actorID int) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		query := fmt.Sprintf("DELETE FROM %s WHERE movie_id = $1 AND actor_id = $2", movieActorTable)
+		res, err := tx.Exec(query, movieID, actorID)
 		if err != nil {
 			return err
 		}
 
-		movieActorQuery := fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2)", movieActorTable)
-		_, err = r.db.Exec(movieActorQuery, movieID, actor.ID)
+		rowsAffected, err := res.RowsAffected()
 		if err != nil {
 			return err
 		}
+		if rowsAffected == 0 {
+			return ErrMovieActorNotFound
+		}
+
+		return touchLinked(tx, res, movieID, actorID)
+	})
+}
+
+// linkActor adds the actor to the cast of the movie, creating the actor if there is no actor
+// with the same name, gender and birth date yet.
+func linkActor(tx *sqlx.Tx, movieID int, actor model.Actor) error {
+	var actorID int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE LOWER(name) = LOWER($1) AND LOWER(gender) = LOWER($2) AND birth_date = $3", actorsTable)
+	err := tx.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&actorID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == sql.ErrNoRows {
+		query := fmt.Sprintf("INSERT INTO %s (name, gender, birth_date) VALUES ($1, $2, $3) RETURNING id", actorsTable)
+		if err := tx.QueryRow(query, actor.Name, actor.Gender, actor.BirthDate).Scan(&actorID); err != nil {
+			return err
+		}
 	}
 
-	err = tx.Commit()
+	query = fmt.Sprintf("INSERT INTO %s (movie_id, actor_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", movieActorTable)
+	res, err := tx.Exec(query, movieID, actorID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// The caller creates or updates the movie itself, so only the version of the actor is incremented here.
+	if linked, err := res.RowsAffected(); err != nil || linked == 0 {
+		return err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id = $1", actorsTable)
+	_, err = tx.Exec(query, actorID)
+	return err
 }
 
-func (r *MoviePostgres) GetMoviesByTitle(titleFragment string) ([]model.MovieWithActors, error) {
-	var moviesMap = make(map[int]model.MovieWithActors)
-	var movies []model.MovieWithActors
+// touchLinked increments the versions of the movie and the actor after res, the result of adding or removing
+// the link between them, if the link has actually changed: each of them embeds the other in its representation.
+func touchLinked(tx *sqlx.Tx, res sql.Result, movieID, actorID int) error {
+	if changed, err := res.RowsAffected(); err != nil || changed == 0 {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id = $1", moviesTable)
+	if _, err := tx.Exec(query, movieID); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id = $1", actorsTable)
+	_, err := tx.Exec(query, actorID)
+	return err
+}
+
+// touchCast increments the versions of the actors in the cast of the movie, whose representations embed it.
+func touchCast(tx *sqlx.Tx, movieID int) error {
+	query := fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id IN (SELECT actor_id FROM %s WHERE movie_id = $1)", actorsTable, movieActorTable)
+	_, err := tx.Exec(query, movieID)
+	return err
+}
+
+func (r *MoviePostgres) GetMoviesByTitle(titleFragment string, sort model.Sort) ([]model.MovieWithActors, error) {
+	keys, err := sortKeys(sort, movieSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+            SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD') as release_date, m.rating, a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') as birth_date
+            FROM %s m
+            LEFT JOIN %s ma ON m.id = ma.movie_id
+            LEFT JOIN %s a ON ma.actor_id = a.id
+            WHERE m.title ILIKE '%%' || $1 || '%%'
+            ORDER BY %s, a.id
+        `, moviesTable, movieActorTable, actorsTable, orderByClause("m", keys))
+
+	return queryMoviesWithActors(r.db, query, titleFragment)
+}
+
+func (r *MoviePostgres) GetMoviesByActor(actorNameFragment string, sort model.Sort) ([]model.MovieWithActors, error) {
+	keys, err := sortKeys(sort, movieSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+            SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD') as release_date, m.rating, a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') as birth_date
+            FROM %s m
+            LEFT JOIN %s ma ON m.id = ma.movie_id
+            LEFT JOIN %s a ON ma.actor_id = a.id
+            WHERE EXISTS (
+                SELECT 1
+                FROM %s fma
+                JOIN %s fa ON fma.actor_id = fa.id
+                WHERE fma.movie_id = m.id AND fa.name ILIKE '%%' || $1 || '%%'
+            )
+            ORDER BY %s, a.id
+        `, moviesTable, movieActorTable, actorsTable, movieActorTable, actorsTable, orderByClause("m", keys))
+
+	return queryMoviesWithActors(r.db, query, actorNameFragment)
+}
 
-	query := `
+func (r *MoviePostgres) GetMoviesByActorFuzzy(actorName string, threshold float64) ([]model.MovieWithActors, error) {
+	query := fmt.Sprintf(`
             SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD') as release_date, m.rating, a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') as birth_date
-            FROM movie m
-            LEFT JOIN movie_actor ma ON m.id = ma.movie_id
-            LEFT JOIN actor a ON ma.actor_id = a.id
-            WHERE m.title ILIKE '%' || $1 || '%'
-        `
-	rows, err := r.db.Query(query, titleFragment)
+            FROM (
+                SELECT fma.movie_id, MAX(word_similarity($1, fa.name)) AS similarity
+                FROM %s fa
+                JOIN %s fma ON fma.actor_id = fa.id
+                WHERE $1 <%% fa.name
+                GROUP BY fma.movie_id
+            ) s
+            JOIN %s m ON m.id = s.movie_id
+            LEFT JOIN %s ma ON m.id = ma.movie_id
+            LEFT JOIN %s a ON ma.actor_id = a.id
+            ORDER BY s.similarity DESC, m.id, a.id
+        `, actorsTable, movieActorTable, moviesTable, movieActorTable, actorsTable)
+
+	var movies []model.MovieWithActors
+	err := withSimilarityThreshold(r.db, threshold, func(tx *sqlx.Tx) error {
+		var err error
+		movies, err = queryMoviesWithActors(tx, query, actorName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Finding nothing is a normal outcome of a typo-tolerant search, as in SearchActors.
+	return movies, nil
+}
+
+// ts_headline marks the matches with control characters rather than tags, so that the
+// snippet can be HTML-escaped before the markers are turned into <mark> tags.
+const (
+	snippetStartSel = "\x01"
+	snippetStopSel  = "\x02"
+)
+
+var (
+	snippetOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=15`, snippetStartSel, snippetStopSel)
+	snippetMarkup  = strings.NewReplacer(snippetStartSel, "<mark>", snippetStopSel, "</mark>")
+)
+
+// highlightSnippet escapes the text of a ts_headline snippet and wraps its matches in <mark> tags.
+func highlightSnippet(headline string) string {
+	return snippetMarkup.Replace(html.EscapeString(headline))
+}
+
+func (r *MoviePostgres) SearchMovies(tsQuery string, limit, offset int) ([]model.MovieSearchHit, error) {
+	// The marker characters are removed from the text first so that only the matches carry them.
+	query := fmt.Sprintf(`
+            WITH hits AS MATERIALIZED (
+                SELECT m.id, ts_rank(m.search_vector, query) AS rank,
+                       ts_headline('simple', translate(COALESCE(NULLIF(m.description, ''), m.title), $4, ''), query, $5) AS snippet
+                FROM %s m, to_tsquery('simple', $1) query
+                WHERE m.search_vector @@ query
+                ORDER BY rank DESC, m.id
+                LIMIT $2 OFFSET $3
+            )
+            SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD'), m.rating, h.rank, h.snippet,
+                   a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD')
+            FROM hits h
+            JOIN %s m ON m.id = h.id
+            LEFT JOIN %s ma ON m.id = ma.movie_id
+            LEFT JOIN %s a ON ma.actor_id = a.id
+            ORDER BY h.rank DESC, m.id, a.id
+        `, moviesTable, moviesTable, movieActorTable, actorsTable)
+
+	rows, err := r.db.Query(query, tsQuery, limit, offset, snippetStartSel+snippetStopSel, snippetOptions)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	found := false
+	movies := newMovieCollector()
+	hits := []model.MovieSearchHit{}
 
 	for rows.Next() {
 		var movie model.MovieWithActors
-		var actor model.Actor
-		err := rows.Scan(&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseDate, &movie.Rating, &actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate)
+		var description sql.NullString
+		var hit model.MovieSearchHit
+		var actor nullableActor
+
+		err := rows.Scan(&movie.ID, &movie.Title, &description, &movie.ReleaseDate, &movie.Rating, &hit.Rank, &hit.Snippet,
+			&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate)
 		if err != nil {
-			continue
+			return nil, err
 		}
 
-		found = true
-
-		if existingMovie, ok := moviesMap[movie.ID]; ok {
-			existingMovie.Actors = append(existingMovie.Actors, actor)
-			moviesMap[movie.ID] = existingMovie
-		} else {
-			movie.Actors = append(movie.Actors, actor)
-			moviesMap[movie.ID] = movie
+		movie.Description = description.String
+		if i := movies.add(movie, actor); i == len(hits) {
+			hit.Snippet = highlightSnippet(hit.Snippet)
+			hits = append(hits, hit)
 		}
 	}
 
-	if !found {
-		return nil, fmt.Errorf("no movies found with title fragment: %s", titleFragment)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	for _, movie := range moviesMap {
-		movies = append(movies, movie)
+	for i := range hits {
+		hits[i].MovieWithActors = movies.movies[i]
 	}
 
-	return movies, nil
+	return hits, nil
 }
 
-func (r *MoviePostgres) GetMoviesByActor(actorNameFragment string) ([]model.MovieWithActors, error) {
-	var moviesMap = make(map[int]model.MovieWithActors)
-	var movies []model.MovieWithActors
-
-	query := `
-            SELECT m.id, m.title, m.description, TO_CHAR(m.release_date, 'YYYY-MM-DD') as release_date, m.rating, a.id, a.name, a.gender, TO_CHAR(a.birth_date, 'YYYY-MM-DD') as birth_date
-            FROM movie m
-            LEFT JOIN movie_actor ma ON m.id = ma.movie_id
-            LEFT JOIN actor a ON ma.actor_id = a.id
-            WHERE a.name ILIKE '%' || $1 || '%'
-        `
-	rows, err := r.db.Query(query, actorNameFragment)
+// queryMoviesWithActors runs a query returning joined movie and actor columns
+// and groups the rows into movies in the order of the result set.
+func queryMoviesWithActors(q sqlx.Queryer, query string, args ...interface{}) ([]model.MovieWithActors, error) {
+	rows, err := q.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	movies := newMovieCollector()
+
 	for rows.Next() {
 		var movie model.MovieWithActors
-		var actor model.Actor
-		err := rows.Scan(&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseDate, &movie.Rating, &actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate)
-		if err != nil {
-			continue
-		}
+		var description sql.NullString
+		var actor nullableActor
 
-		if existingMovie, ok := moviesMap[movie.ID]; ok {
-			existingMovie.Actors = append(existingMovie.Actors, actor)
-			moviesMap[movie.ID] = existingMovie
-		} else {
-			movie.Actors = append(movie.Actors, actor)
-			moviesMap[movie.ID] = movie
+		err := rows.Scan(&movie.ID, &movie.Title, &description, &movie.ReleaseDate, &movie.Rating,
+			&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	if len(moviesMap) == 0 {
-		return nil, fmt.Errorf("no movies found for actor with name fragment: %s", actorNameFragment)
+		movie.Description = description.String
+		movies.add(movie, actor)
 	}
 
-	for _, movie := range moviesMap {
-		movies = append(movies, movie)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return movies, nil
+	return movies.movies, nil
 }
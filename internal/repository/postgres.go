@@ -2,8 +2,12 @@ package repository
 
 import (
 	"fmt"
+	"strconv"
+
+	"github.com/avealice/filmhub/internal/model"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 const (
@@ -11,8 +15,18 @@ const (
 	moviesTable     = "movie"
 	actorsTable     = "actor"
 	movieActorTable = "movie_actor"
+
+	rolePermissionsTable     = "role_permissions"
+	refreshTokensTable       = "refresh_tokens"
+	revokedAccessTokensTable = "revoked_access_tokens"
+	apiKeysTable             = "api_keys"
+	passwordResetTokensTable = "password_reset_tokens"
+	userPreferencesTable     = "user_preferences"
+	userIdentitiesTable      = "user_identities"
 )
 
+var ErrVersionMismatch = model.NewError(model.ErrPreconditionFailed, "version_mismatch", "resource has been modified since it was read")
+
 type Config struct {
 	Host     string
 	Port     string
@@ -35,3 +49,58 @@ func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// withTx runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise.
+// Every write that takes more than one statement goes through withTx, so that a failure halfway
+// through does not leave partially written data behind.
+func withTx(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withSimilarityThreshold runs fn in a transaction in which the pg_trgm word similarity
+// threshold, used by the <% operator and its index, is set to threshold.
+func withSimilarityThreshold(db *sqlx.DB, threshold float64, fn func(tx *sqlx.Tx) error) error {
+	return withTx(db, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", strconv.FormatFloat(threshold, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+// versionCondition returns the condition of a conditional write on the version column to be appended to its WHERE
+// clause, or an empty string if ifMatch matches any version.
+func versionCondition(ifMatch model.Versions, args *queryArgs) string {
+	if len(ifMatch) == 0 {
+		return ""
+	}
+
+	return " AND version = ANY(" + args.add(pq.Array([]int(ifMatch))) + ")"
+}
+
+// notFoundOrModified explains why a conditional write of the row with the id changed nothing: it returns notFound
+// if there is no such row in the table and ErrVersionMismatch if there is, that is if the version did not match.
+func notFoundOrModified(q sqlx.Queryer, table string, id int, notFound error) error {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", table)
+	if err := sqlx.Get(q, &exists, query, id); err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+
+	return ErrVersionMismatch
+}
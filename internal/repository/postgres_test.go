@@ -0,0 +1,589 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var errFakeQuery = errors.New("fake query failed")
+
+// fakeDB records the statements run through it and whether they ran in a transaction. Statements containing
+// failOn fail, statements containing a key of rows return the row, statements with RETURNING return the id 1
+// and other queries return no rows. Statements containing unaffected change no rows, others change one.
+type fakeDB struct {
+	failOn     string
+	unaffected string
+	rows       map[string][]driver.Value
+	statements []fakeStatement
+	commits    int
+	rollbacks  int
+}
+
+type fakeStatement struct {
+	query string
+	inTx  bool
+}
+
+func (d *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+func (d *fakeDB) Driver() driver.Driver                        { return nil }
+
+// open returns a connection pool backed by the fake database.
+func (d *fakeDB) open() *sqlx.DB {
+	return sqlx.NewDb(sql.OpenDB(d), "postgres")
+}
+
+type fakeConn struct {
+	db   *fakeDB
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.inTx = false
+	c.db.commits++
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.inTx = false
+	c.db.rollbacks++
+	return nil
+}
+
+func (c *fakeConn) run(query string) error {
+	c.db.statements = append(c.db.statements, fakeStatement{query: query, inTx: c.inTx})
+	if c.db.failOn != "" && strings.Contains(query, c.db.failOn) {
+		return errFakeQuery
+	}
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.run(query); err != nil {
+		return nil, err
+	}
+	if c.db.unaffected != "" && strings.Contains(query, c.db.unaffected) {
+		return driver.RowsAffected(0), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.run(query); err != nil {
+		return nil, err
+	}
+
+	for key, row := range c.db.rows {
+		if strings.Contains(query, key) {
+			return &fakeRows{values: [][]driver.Value{row}, columns: len(row)}, nil
+		}
+	}
+	if strings.Contains(query, "RETURNING id") {
+		return &fakeRows{values: [][]driver.Value{{int64(1)}}, columns: 1}, nil
+	}
+	return &fakeRows{columns: 1}, nil
+}
+
+type fakeRows struct {
+	values  [][]driver.Value
+	columns int
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, r.columns) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestWithTx(t *testing.T) {
+	tests := []struct {
+		name              string
+		fnErr             error
+		expectedCommits   int
+		expectedRollbacks int
+	}{
+		{name: "commits on success", expectedCommits: 1},
+		{name: "rolls back on error", fnErr: errFakeQuery, expectedRollbacks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDB{}
+
+			err := withTx(fake.open(), func(tx *sqlx.Tx) error {
+				if _, err := tx.Exec("DELETE FROM movie_actor"); err != nil {
+					return err
+				}
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.fnErr) {
+				t.Errorf("Expected error %v, got %v", tt.fnErr, err)
+			}
+
+			if fake.commits != tt.expectedCommits || fake.rollbacks != tt.expectedRollbacks {
+				t.Errorf("Expected %d commits and %d rollbacks, got %d and %d", tt.expectedCommits, tt.expectedRollbacks, fake.commits, fake.rollbacks)
+			}
+		})
+	}
+}
+
+func TestNestedWrites_RollBackOnFailure(t *testing.T) {
+	actors := []model.Actor{{Name: "Keanu Reeves", Gender: "male", BirthDate: "1964-9-2"}}
+	movies := []model.Movie{{Title: "Matrix", Description: "Neo", ReleaseDate: "1999-3-31", Rating: 9}}
+
+	tests := []struct {
+		name  string
+		write func(db *sqlx.DB) error
+	}{
+		{
+			name: "create movie",
+			write: func(db *sqlx.DB) error {
+				_, err := NewMoviePostgres(db).CreateMovie(model.InputMovie{Title: "Matrix", ReleaseDate: "1999-3-31", Actors: actors})
+				return err
+			},
+		},
+		{
+			name: "update movie",
+			write: func(db *sqlx.DB) error {
+				_, err := NewMoviePostgres(db).UpdateMovie(1, model.InputMovie{Title: "Matrix", Actors: actors}, nil)
+				return err
+			},
+		},
+		{
+			name: "create actor",
+			write: func(db *sqlx.DB) error {
+				_, err := NewActorPostgres(db).CreateActor(model.InputActor{Name: "Keanu Reeves", Gender: "male", BirthDate: "1964-9-2", Movies: movies})
+				return err
+			},
+		},
+		{
+			name: "update actor",
+			write: func(db *sqlx.DB) error {
+				_, err := NewActorPostgres(db).Update(1, model.InputActor{Name: "Keanu Reeves", Movies: movies}, nil)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The link to the nested entity is the last statement, so the parent and the nested entity
+			// have already been written when it fails.
+			fake := &fakeDB{failOn: "INSERT INTO " + movieActorTable}
+
+			err := tt.write(fake.open())
+			if !errors.Is(err, errFakeQuery) {
+				t.Fatalf("Expected error %v, got %v", errFakeQuery, err)
+			}
+
+			if fake.commits != 0 || fake.rollbacks != 1 {
+				t.Errorf("Expected a rollback and no commits, got %d commits and %d rollbacks", fake.commits, fake.rollbacks)
+			}
+
+			if len(fake.statements) < 3 {
+				t.Errorf("Expected the write to fail after several statements, got %d", len(fake.statements))
+			}
+			for _, statement := range fake.statements {
+				if !statement.inTx {
+					t.Errorf("Expected statement %q to run in the transaction", statement.query)
+				}
+			}
+		})
+	}
+}
+
+func TestMoviePostgres_CreateMovie_Commits(t *testing.T) {
+	fake := &fakeDB{rows: map[string][]driver.Value{
+		// The created movie is read back in the same transaction.
+		"LEFT JOIN": {int64(1), "Matrix", "", "1999-03-31", int64(0), int64(1), int64(1), "Keanu Reeves", "male", "1964-09-02"},
+	}}
+
+	movie, err := NewMoviePostgres(fake.open()).CreateMovie(model.InputMovie{
+		Title:       "Matrix",
+		ReleaseDate: "1999-3-31",
+		Actors:      []model.Actor{{Name: "Keanu Reeves", Gender: "male", BirthDate: "1964-9-2"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if movie.ID != 1 || movie.ReleaseDate != "1999-03-31" || len(movie.Actors) != 1 || movie.Actors[0].ID != 1 {
+		t.Errorf("Expected the created movie with its actor, got %+v", movie)
+	}
+
+	if fake.commits != 1 || fake.rollbacks != 0 {
+		t.Errorf("Expected a commit and no rollbacks, got %d commits and %d rollbacks", fake.commits, fake.rollbacks)
+	}
+	for _, statement := range fake.statements {
+		if !statement.inTx {
+			t.Errorf("Expected statement %q to run in the transaction", statement.query)
+		}
+	}
+}
+
+func TestConditionalWrites(t *testing.T) {
+	input := model.InputMovie{Title: "Matrix", ReleaseDate: "1999-3-31"}
+
+	tests := []struct {
+		name        string
+		exists      bool
+		write       func(db *sqlx.DB) error
+		expectedErr error
+	}{
+		{
+			name:   "update of a modified movie",
+			exists: true,
+			write: func(db *sqlx.DB) error {
+				_, err := NewMoviePostgres(db).UpdateMovie(1, input, model.Versions{2})
+				return err
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+		{
+			name: "update of a missing movie",
+			write: func(db *sqlx.DB) error {
+				_, err := NewMoviePostgres(db).UpdateMovie(1, input, model.Versions{2})
+				return err
+			},
+			expectedErr: ErrMovieNotFound,
+		},
+		{
+			name:   "patch of a modified movie",
+			exists: true,
+			write: func(db *sqlx.DB) error {
+				_, err := NewMoviePostgres(db).PatchMovie(1, model.PatchMovieInput{Title: &input.Title}, model.Versions{2})
+				return err
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+		{
+			name:   "deletion of a modified movie",
+			exists: true,
+			write: func(db *sqlx.DB) error {
+				return NewMoviePostgres(db).DeleteByID(1, model.Versions{2})
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+		{
+			name: "deletion of a missing movie",
+			write: func(db *sqlx.DB) error {
+				return NewMoviePostgres(db).DeleteByID(1, model.Versions{2})
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+		{
+			name: "unconditional deletion of a missing movie",
+			write: func(db *sqlx.DB) error {
+				return NewMoviePostgres(db).DeleteByID(1, nil)
+			},
+		},
+		{
+			name:   "update of a modified actor",
+			exists: true,
+			write: func(db *sqlx.DB) error {
+				_, err := NewActorPostgres(db).Update(1, model.InputActor{Name: "Keanu Reeves"}, model.Versions{2})
+				return err
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+		{
+			name: "update of a missing actor",
+			write: func(db *sqlx.DB) error {
+				_, err := NewActorPostgres(db).Update(1, model.InputActor{Name: "Keanu Reeves"}, nil)
+				return err
+			},
+			expectedErr: ErrActorNotFound,
+		},
+		{
+			name:   "deletion of a modified actor",
+			exists: true,
+			write: func(db *sqlx.DB) error {
+				return NewActorPostgres(db).Delete(1, model.Versions{2})
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+		{
+			name: "deletion of a missing actor",
+			write: func(db *sqlx.DB) error {
+				return NewActorPostgres(db).Delete(1, model.Versions{2})
+			},
+			expectedErr: ErrVersionMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The version condition makes the write change no rows, the existence check tells why.
+			fake := &fakeDB{unaffected: "WHERE id = ", rows: map[string][]driver.Value{"SELECT EXISTS": {tt.exists}}}
+
+			err := tt.write(fake.open())
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr != nil && fake.commits != 0 {
+				t.Errorf("Expected a failed write to be rolled back, got %d commits", fake.commits)
+			}
+		})
+	}
+}
+
+func TestAuthPostgres_UpdateUser_PasswordRevokesSessions(t *testing.T) {
+	password := "hash"
+	disabled := false
+
+	tests := []struct {
+		name            string
+		input           model.UpdateUserInput
+		expectedRevoked bool
+	}{
+		{name: "password reset", input: model.UpdateUserInput{Password: &password}, expectedRevoked: true},
+		{name: "enabling the user", input: model.UpdateUserInput{Disabled: &disabled}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDB{}
+
+			if err := NewAuthPostgres(fake.open()).UpdateUser(1, tt.input); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			update := fake.statements[0].query
+			if !strings.HasPrefix(update, "UPDATE "+usersTable) {
+				t.Fatalf("Expected the user to be updated first, got %q", update)
+			}
+			if revoked := strings.Contains(update, "sessions_revoked_at=now()"); revoked != tt.expectedRevoked {
+				t.Errorf("Expected access tokens revoked: %t, got statement %q", tt.expectedRevoked, update)
+			}
+		})
+	}
+}
+
+func TestMoviePostgres_Search_NoMatches(t *testing.T) {
+	sort := model.Sort{{Field: "title"}}
+
+	tests := []struct {
+		name   string
+		search func(r *MoviePostgres) ([]model.MovieWithActors, error)
+	}{
+		{
+			name:   "by title",
+			search: func(r *MoviePostgres) ([]model.MovieWithActors, error) { return r.GetMoviesByTitle("Zzyzx", sort) },
+		},
+		{
+			name:   "by actor",
+			search: func(r *MoviePostgres) ([]model.MovieWithActors, error) { return r.GetMoviesByActor("Zzyzx", sort) },
+		},
+		{
+			name: "by actor, fuzzy",
+			search: func(r *MoviePostgres) ([]model.MovieWithActors, error) {
+				return r.GetMoviesByActorFuzzy("Di Caprio", 0.3)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDB{}
+
+			movies, err := tt.search(NewMoviePostgres(fake.open()))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if movies == nil || len(movies) != 0 {
+				t.Errorf("Expected an empty list, got %#v", movies)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition_InvalidCursor(t *testing.T) {
+	sort := model.Sort{{Field: "rating", Desc: true}}
+	keys, err := sortKeys(sort, movieSortColumns)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		cursor      model.Cursor
+		expectedErr error
+	}{
+		{name: "cursor of the sort", cursor: model.Cursor{Sort: "-rating", Values: []string{"8"}, ID: 3}},
+		{name: "cursor of another sort", cursor: model.Cursor{Sort: "title", Values: []string{"Matrix"}, ID: 3}, expectedErr: model.ErrInvalidCursor},
+		{name: "cursor without sort", cursor: model.Cursor{Values: []string{"8"}, ID: 3}, expectedErr: model.ErrInvalidCursor},
+		{name: "missing values", cursor: model.Cursor{Sort: "-rating", ID: 3}, expectedErr: model.ErrInvalidCursor},
+		{name: "tampered value", cursor: model.Cursor{Sort: "-rating", Values: []string{"eight"}, ID: 3}, expectedErr: model.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args queryArgs
+			if _, err := keysetCondition("m", sort, keys, &tt.cursor, &args); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidCursorValue(t *testing.T) {
+	tests := []struct {
+		column string
+		value  string
+		valid  bool
+	}{
+		{column: "title", value: "Matrix'; DROP TABLE movie; --", valid: true},
+		{column: "rating", value: "8", valid: true},
+		{column: "rating", value: "8.5"},
+		{column: "release_date", value: "1999-03-31", valid: true},
+		{column: "birth_date", value: "31.03.1999"},
+	}
+
+	for _, tt := range tests {
+		if valid := validCursorValue(tt.column, tt.value); valid != tt.valid {
+			t.Errorf("%s %q: expected valid=%t, got %t", tt.column, tt.value, tt.valid, valid)
+		}
+	}
+}
+
+func TestMoviePostgres_SearchMovies_EscapesSnippet(t *testing.T) {
+	// The description is user input, only the highlighted matches may become markup.
+	headline := "<img src=x onerror=alert(1)> \x01Matrix\x02 & <mark>Neo</mark>"
+	fake := &fakeDB{rows: map[string][]driver.Value{
+		"ts_headline": {int64(1), "Matrix", "", "1999-03-31", int64(8), 0.5, headline, nil, nil, nil, nil},
+	}}
+
+	hits, err := NewMoviePostgres(fake.open()).SearchMovies("'matrix'", 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "&lt;img src=x onerror=alert(1)&gt; <mark>Matrix</mark> &amp; &lt;mark&gt;Neo&lt;/mark&gt;"
+	if len(hits) != 1 || hits[0].Snippet != expected {
+		t.Errorf("Expected snippet %q, got %+v", expected, hits)
+	}
+}
+
+func TestGetMovieByID_NullColumns(t *testing.T) {
+	// A movie without a description and without actors.
+	fake := &fakeDB{rows: map[string][]driver.Value{
+		"LEFT JOIN": {int64(1), "Matrix", nil, "1999-03-31", int64(8), int64(3), nil, nil, nil, nil},
+	}}
+
+	movie, err := NewMoviePostgres(fake.open()).GetMovieByID(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if movie.ReleaseDate != "1999-03-31" || movie.Rating != 8 || movie.Version != 3 {
+		t.Errorf("Expected all columns after the description to be read, got %+v", movie)
+	}
+	if movie.Actors == nil || len(movie.Actors) != 0 {
+		t.Errorf("Expected an empty cast, got %#v", movie.Actors)
+	}
+}
+
+func TestGetActor_NullColumns(t *testing.T) {
+	// An actor without movies.
+	fake := &fakeDB{rows: map[string][]driver.Value{
+		"LEFT JOIN": {int64(1), "Keanu Reeves", "male", "1964-09-02", int64(3), nil, nil, nil, nil, nil},
+	}}
+
+	actor, err := NewActorPostgres(fake.open()).Get(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if actor.BirthDate != "1964-09-02" || actor.Version != 3 {
+		t.Errorf("Expected all columns of the actor to be read, got %+v", actor)
+	}
+	if actor.Movies == nil || len(actor.Movies) != 0 {
+		t.Errorf("Expected no movies, got %#v", actor.Movies)
+	}
+}
+
+func TestGetMovieByID_ScanError(t *testing.T) {
+	fake := &fakeDB{rows: map[string][]driver.Value{
+		"LEFT JOIN": {int64(1), "Matrix", nil, "1999-03-31", "not a rating", int64(3), nil, nil, nil, nil},
+	}}
+
+	if _, err := NewMoviePostgres(fake.open()).GetMovieByID(1); err == nil {
+		t.Error("Expected the scan error to be returned")
+	}
+}
+
+func TestActorWrites_LinkExistingMovies(t *testing.T) {
+	movies := []model.Movie{{Title: "Matrix", ReleaseDate: "1999-3-31", Rating: 8}}
+
+	tests := []struct {
+		name  string
+		write func(db *sqlx.DB) error
+	}{
+		{
+			name: "create actor",
+			write: func(db *sqlx.DB) error {
+				_, err := NewActorPostgres(db).CreateActor(model.InputActor{Name: "Keanu Reeves", Gender: "male", BirthDate: "1964-9-2", Movies: movies})
+				return err
+			},
+		},
+		{
+			name: "update actor",
+			write: func(db *sqlx.DB) error {
+				_, err := NewActorPostgres(db).Update(1, model.InputActor{Movies: movies}, nil)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The listed movie already exists.
+			fake := &fakeDB{rows: map[string][]driver.Value{
+				"SELECT id FROM " + moviesTable + " WHERE": {int64(5)},
+				"LEFT JOIN": {int64(1), "Keanu Reeves", "male", "1964-09-02", int64(1), int64(5), "Matrix", "", "1999-03-31", int64(8)},
+			}}
+
+			if err := tt.write(fake.open()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var linked, touched bool
+			for _, statement := range fake.statements {
+				switch {
+				case strings.HasPrefix(statement.query, "INSERT INTO "+moviesTable+" "):
+					t.Errorf("Expected the existing movie to be reused, got %q", statement.query)
+				case strings.HasPrefix(statement.query, "INSERT INTO "+movieActorTable):
+					linked = true
+				case statement.query == "UPDATE "+moviesTable+" SET version = version + 1 WHERE id = $1":
+					touched = true
+				}
+			}
+			if !linked || !touched {
+				t.Errorf("Expected the existing movie to be linked and its version incremented, got linked=%t touched=%t", linked, touched)
+			}
+		})
+	}
+}
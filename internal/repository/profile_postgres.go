@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ProfilePostgres struct {
+	db *sqlx.DB
+}
+
+func NewProfilePostgres(db *sqlx.DB) *ProfilePostgres {
+	return &ProfilePostgres{db: db}
+}
+
+// profileRow is a users row joined with the preferences of the user.
+type profileRow struct {
+	model.Profile
+	MovieSort string `db:"movie_sort"`
+	Locale    string `db:"locale"`
+}
+
+func (r *ProfilePostgres) GetProfile(userID int) (model.Profile, error) {
+	var row profileRow
+	query := fmt.Sprintf(`
+		SELECT u.id, u.username, u.role, u.created_at,
+			COALESCE(p.movie_sort, '') AS movie_sort, COALESCE(p.locale, '') AS locale
+		FROM %s u
+		LEFT JOIN %s p ON p.user_id = u.id
+		WHERE u.id=$1`, usersTable, userPreferencesTable)
+
+	err := r.db.Get(&row, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Profile{}, ErrUserNotFound
+		}
+		return model.Profile{}, err
+	}
+
+	profile := row.Profile
+	profile.Preferences = model.Preferences{MovieSort: row.MovieSort, Locale: row.Locale}
+
+	return profile, nil
+}
+
+// GetPreferences returns the preferences of the user. A user who never changed
+// the preferences gets empty ones.
+func (r *ProfilePostgres) GetPreferences(userID int) (model.Preferences, error) {
+	var prefs model.Preferences
+	query := fmt.Sprintf("SELECT movie_sort, locale FROM %s WHERE user_id=$1", userPreferencesTable)
+
+	err := r.db.Get(&prefs, query, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return model.Preferences{}, err
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences stores the non-nil fields of input, creating the preferences
+// of the user on the first change.
+func (r *ProfilePostgres) UpdatePreferences(userID int, input model.UpdatePreferencesInput) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (user_id, movie_sort, locale)
+		VALUES ($1, COALESCE($2, ''), COALESCE($3, ''))
+		ON CONFLICT (user_id) DO UPDATE SET
+			movie_sort = COALESCE($2, %[1]s.movie_sort),
+			locale = COALESCE($3, %[1]s.locale),
+			updated_at = now()`, userPreferencesTable)
+
+	_, err := r.db.Exec(query, userID, input.MovieSort, input.Locale)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// queryArgs collects positional arguments of a query and hands out their placeholders.
+type queryArgs []interface{}
+
+func (a *queryArgs) add(value interface{}) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// sortKey is a single column of an ORDER BY clause.
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// withIDTieBreaker appends the id column so that rows with equal sort keys keep a stable order.
+func withIDTieBreaker(keys []sortKey) []sortKey {
+	result := make([]sortKey, 0, len(keys)+1)
+	result = append(result, keys...)
+	return append(result, sortKey{column: "id"})
+}
+
+// Columns of the sortable fields. Only these columns are ever put into an ORDER BY clause.
+var (
+	movieSortColumns = map[string]string{
+		"title":        "title",
+		"rating":       "rating",
+		"release_date": "release_date",
+	}
+	actorSortColumns = map[string]string{
+		"name":       "name",
+		"gender":     "gender",
+		"birth_date": "birth_date",
+	}
+)
+
+// sortKeys converts a requested sort into ORDER BY keys ending with the id tie-breaker.
+func sortKeys(sort model.Sort, columns map[string]string) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(sort))
+	for _, field := range sort {
+		column, ok := columns[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field: %s", field.Field)
+		}
+		keys = append(keys, sortKey{column: column, desc: field.Desc})
+	}
+
+	return withIDTieBreaker(keys), nil
+}
+
+func orderByClause(alias string, keys []sortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		direction := "ASC"
+		if key.desc {
+			direction = "DESC"
+		}
+		parts = append(parts, fmt.Sprintf("%s.%s %s", alias, key.column, direction))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// cursorColumns returns an expression that collects the values of the sort keys of a row
+// into a text array. The last key must be the id tie-breaker, it is stored in the cursor separately.
+func cursorColumns(alias string, keys []sortKey) string {
+	columns := make([]string, 0, len(keys))
+	for _, key := range keys[:len(keys)-1] {
+		columns = append(columns, fmt.Sprintf("(%s.%s)::text", alias, key.column))
+	}
+
+	return fmt.Sprintf("ARRAY[%s]::text[]", strings.Join(columns, ", "))
+}
+
+// keysetCondition builds a predicate that selects the rows following the cursor in the order given by keys,
+// which are the keys of sort. The last key must be the id tie-breaker. A cursor issued for another sort or
+// with values that do not fit the columns is rejected with model.ErrInvalidCursor.
+func keysetCondition(alias string, sort model.Sort, keys []sortKey, cursor *model.Cursor, args *queryArgs) (string, error) {
+	if cursor.Sort != sort.String() || len(cursor.Values) != len(keys)-1 {
+		return "", model.ErrInvalidCursor
+	}
+	for i, value := range cursor.Values {
+		if !validCursorValue(keys[i].column, value) {
+			return "", model.ErrInvalidCursor
+		}
+	}
+
+	values := append(append([]string{}, cursor.Values...), strconv.Itoa(cursor.ID))
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = args.add(value)
+	}
+
+	var alternatives []string
+	for i, key := range keys {
+		var conditions []string
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, fmt.Sprintf("%s.%s = %s", alias, keys[j].column, placeholders[j]))
+		}
+
+		operator := ">"
+		if key.desc {
+			operator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s.%s %s %s", alias, key.column, operator, placeholders[i]))
+
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", nil
+}
+
+// validCursorValue reports whether the value of a cursor can be compared with the column,
+// so that a tampered cursor does not make the query fail.
+func validCursorValue(column, value string) bool {
+	switch column {
+	case "rating":
+		_, err := strconv.Atoi(value)
+		return err == nil
+	case "release_date", "birth_date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// movieFilterConditions compiles a movie filter into WHERE conditions on the movie table aliased as alias.
+func movieFilterConditions(alias string, filter model.MovieFilter, args *queryArgs) []string {
+	var conditions []string
+
+	if filter.Title != "" {
+		conditions = append(conditions, fmt.Sprintf("%s.title ILIKE '%%' || %s || '%%'", alias, args.add(filter.Title)))
+	}
+
+	if filter.MinRating != nil {
+		conditions = append(conditions, fmt.Sprintf("%s.rating >= %s", alias, args.add(*filter.MinRating)))
+	}
+
+	if filter.MaxRating != nil {
+		conditions = append(conditions, fmt.Sprintf("%s.rating <= %s", alias, args.add(*filter.MaxRating)))
+	}
+
+	if filter.ReleasedAfter != "" {
+		conditions = append(conditions, fmt.Sprintf("%s.release_date >= %s", alias, args.add(filter.ReleasedAfter)))
+	}
+
+	if filter.ReleasedBefore != "" {
+		conditions = append(conditions, fmt.Sprintf("%s.release_date <= %s", alias, args.add(filter.ReleasedBefore)))
+	}
+
+	if len(filter.ActorIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s fma WHERE fma.movie_id = %s.id AND fma.actor_id = ANY(%s))",
+			movieActorTable, alias, args.add(pq.Array(filter.ActorIDs))))
+	}
+
+	return conditions
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// nullableActor holds the actor columns of a LEFT JOIN, which are NULL for movies without actors.
+type nullableActor struct {
+	ID        sql.NullInt64
+	Name      sql.NullString
+	Gender    sql.NullString
+	BirthDate sql.NullString
+}
+
+func (a nullableActor) actor() (model.Actor, bool) {
+	if !a.ID.Valid {
+		return model.Actor{}, false
+	}
+
+	return model.Actor{
+		ID:        int(a.ID.Int64),
+		Name:      a.Name.String,
+		Gender:    a.Gender.String,
+		BirthDate: a.BirthDate.String,
+	}, true
+}
+
+// nullableMovie holds the movie columns of a LEFT JOIN, which are NULL for actors without movies.
+type nullableMovie struct {
+	ID          sql.NullInt64
+	Title       sql.NullString
+	Description sql.NullString
+	ReleaseDate sql.NullString
+	Rating      sql.NullInt64
+}
+
+func (m nullableMovie) movie() (model.Movie, bool) {
+	if !m.ID.Valid {
+		return model.Movie{}, false
+	}
+
+	return model.Movie{
+		ID:          int(m.ID.Int64),
+		Title:       m.Title.String,
+		Description: m.Description.String,
+		ReleaseDate: m.ReleaseDate.String,
+		Rating:      int(m.Rating.Int64),
+	}, true
+}
+
+// movieCollector groups joined movie/actor rows into movies, keeping the order in which
+// the movies first appear in the result set.
+type movieCollector struct {
+	movies []model.MovieWithActors
+	index  map[int]int
+}
+
+func newMovieCollector() *movieCollector {
+	return &movieCollector{
+		movies: []model.MovieWithActors{},
+		index:  make(map[int]int),
+	}
+}
+
+// add records a row and returns the position of its movie in the result.
+func (c *movieCollector) add(movie model.MovieWithActors, actor nullableActor) int {
+	i, ok := c.index[movie.ID]
+	if !ok {
+		movie.Actors = []model.Actor{}
+		c.movies = append(c.movies, movie)
+		i = len(c.movies) - 1
+		c.index[movie.ID] = i
+	}
+
+	if a, ok := actor.actor(); ok {
+		c.movies[i].Actors = append(c.movies[i].Actors, a)
+	}
+
+	return i
+}
+
+// actorCollector groups joined actor/movie rows into actors, keeping the order in which
+// the actors first appear in the result set.
+type actorCollector struct {
+	actors []model.ActorWithMovies
+	index  map[int]int
+}
+
+func newActorCollector() *actorCollector {
+	return &actorCollector{
+		actors: []model.ActorWithMovies{},
+		index:  make(map[int]int),
+	}
+}
+
+// add records a row and returns the position of its actor in the result.
+func (c *actorCollector) add(actor model.ActorWithMovies, movie nullableMovie) int {
+	i, ok := c.index[actor.ID]
+	if !ok {
+		actor.Movies = []model.Movie{}
+		c.actors = append(c.actors, actor)
+		i = len(c.actors) - 1
+		c.index[actor.ID] = i
+	}
+
+	if m, ok := movie.movie(); ok {
+		c.actors[i].Movies = append(c.actors[i].Movies, m)
+	}
+
+	return i
+}
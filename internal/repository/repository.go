@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/avealice/filmhub/internal/model"
 
 	"github.com/jmoiron/sqlx"
@@ -8,31 +10,71 @@ import (
 
 type Authorization interface {
 	CreateUser(user model.User) (int, error)
-	GetUser(username, password string) (model.User, error)
+	GetUser(username string) (model.User, error)
+	UpdatePasswordHash(userID int, passwordHash string) error
+	GetUserByID(userID int) (model.User, error)
+	GetUserByIdentity(issuer, subject string) (model.User, error)
+	CreateUserWithIdentity(user model.User, issuer, subject string) (int, error)
+	CreateRefreshToken(token model.RefreshToken) error
+	RotateRefreshToken(tokenHash string, next model.RefreshToken) (model.RefreshToken, error)
+	RevokeRefreshTokenFamily(userID int, tokenHash string) error
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID string) (bool, error)
+	GetRolePermissions(role string) ([]string, error)
+	GetAllUsers(limit, offset int) (model.UsersPage, error)
+	GetUserAccount(userID int) (model.UserAccount, error)
+	UpdateUser(userID int, input model.UpdateUserInput) error
+	DeleteUser(userID int) error
+	ChangePassword(userID int, passwordHash string, revokedAt time.Time) error
+	CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time) error
+	GetPasswordResetTokenUser(tokenHash string) (int, error)
+	ResetPassword(tokenHash, passwordHash string, revokedAt time.Time) error
 }
 
 type Movie interface {
-	GetAllMovies(sortBy, sortOrder string) ([]model.MovieWithActors, error)
-	CreateMovie(movie model.InputMovie) error
+	GetAllMovies(filter model.MovieFilter, sort model.Sort, page model.Pagination) (model.MoviesPage, error)
+	CreateMovie(movie model.InputMovie) (model.MovieWithActors, error)
 	GetMovieByID(movieID int) (model.MovieWithActors, error)
-	DeleteByID(movieID int) error
-	UpdateMovie(movieID int, data model.InputMovie) error
-	GetMoviesByTitle(title string) ([]model.MovieWithActors, error)
-	GetMoviesByActor(actor string) ([]model.MovieWithActors, error)
+	DeleteByID(movieID int, ifMatch model.Versions) error
+	UpdateMovie(movieID int, data model.InputMovie, ifMatch model.Versions) (model.MovieWithActors, error)
+	PatchMovie(movieID int, input model.PatchMovieInput, ifMatch model.Versions) (model.MovieWithActors, error)
+	AddActor(movieID, actorID int) error
+	RemoveActor(movieID, actorID int) error
+	GetMoviesByTitle(title string, sort model.Sort) ([]model.MovieWithActors, error)
+	GetMoviesByActor(actor string, sort model.Sort) ([]model.MovieWithActors, error)
+	GetMoviesByActorFuzzy(actor string, threshold float64) ([]model.MovieWithActors, error)
+	SearchMovies(tsQuery string, limit, offset int) ([]model.MovieSearchHit, error)
 }
 
 type Actor interface {
-	GetAllActors() ([]model.ActorWithMovies, error)
-	CreateActor(actor model.InputActor) (int, error)
-	Delete(actorID int) error
+	GetAllActors(sort model.Sort, page model.Pagination) (model.ActorsPage, error)
+	CreateActor(actor model.InputActor) (model.ActorWithMovies, error)
+	Delete(actorID int, ifMatch model.Versions) error
 	Get(actorID int) (model.ActorWithMovies, error)
-	Update(actorID int, data model.InputActor) error
+	Update(actorID int, data model.InputActor, ifMatch model.Versions) (model.ActorWithMovies, error)
+	SearchActors(name string, threshold float64, limit int) ([]model.ActorSearchHit, error)
+}
+
+type APIKey interface {
+	CreateAPIKey(key model.APIKey, keyHash string) (model.APIKey, error)
+	GetAllAPIKeys() ([]model.APIKey, error)
+	GetActiveAPIKey(keyHash string) (model.APIKey, error)
+	TouchAPIKey(keyID int) error
+	RevokeAPIKey(keyID int) error
+}
+
+type Profile interface {
+	GetProfile(userID int) (model.Profile, error)
+	GetPreferences(userID int) (model.Preferences, error)
+	UpdatePreferences(userID int, input model.UpdatePreferencesInput) error
 }
 
 type Repository struct {
 	Authorization
 	Movie
 	Actor
+	APIKey
+	Profile
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -40,5 +82,7 @@ func NewRepository(db *sqlx.DB) *Repository {
 		Authorization: NewAuthPostgres(db),
 		Movie:         NewMoviePostgres(db),
 		Actor:         NewActorPostgres(db),
+		APIKey:        NewAPIKeyPostgres(db),
+		Profile:       NewProfilePostgres(db),
 	}
 }
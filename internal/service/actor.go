@@ -5,32 +5,55 @@ import (
 	"github.com/avealice/filmhub/internal/repository"
 )
 
+var (
+	ErrActorNotFound = repository.ErrActorNotFound
+	ErrActorExists   = repository.ErrActorExists
+)
+
 type ActorService struct {
-	r repository.Actor
+	r      repository.Actor
+	search SearchConfig
 }
 
-func NewActorService(r repository.Actor) *ActorService {
+func NewActorService(r repository.Actor, search SearchConfig) *ActorService {
 	return &ActorService{
-		r: r,
+		r:      r,
+		search: search,
 	}
 }
 
-func (s *ActorService) CreateActor(actor model.InputActor) (int, error) {
+func (s *ActorService) CreateActor(actor model.InputActor) (model.ActorWithMovies, error) {
+	if err := validateInputActor(actor, false); err != nil {
+		return model.ActorWithMovies{}, err
+	}
+
 	return s.r.CreateActor(actor)
 }
 
-func (s *ActorService) GetAllActors() ([]model.ActorWithMovies, error) {
-	return s.r.GetAllActors()
+func (s *ActorService) GetAllActors(sort model.Sort, page model.Pagination) (model.ActorsPage, error) {
+	if err := sort.Validate(model.ActorSortFields); err != nil {
+		return model.ActorsPage{}, err
+	}
+
+	return s.r.GetAllActors(sort, page)
 }
 
-func (s *ActorService) Delete(actorID int) error {
-	return s.r.Delete(actorID)
+func (s *ActorService) Delete(actorID int, ifMatch model.Versions) error {
+	return s.r.Delete(actorID, ifMatch)
 }
 
 func (s *ActorService) Get(actorID int) (model.ActorWithMovies, error) {
 	return s.r.Get(actorID)
 }
 
-func (s *ActorService) Update(actorID int, data model.InputActor) error {
-	return s.r.Update(actorID, data)
+func (s *ActorService) Update(actorID int, data model.InputActor, ifMatch model.Versions) (model.ActorWithMovies, error) {
+	if err := validateInputActor(data, true); err != nil {
+		return model.ActorWithMovies{}, err
+	}
+
+	return s.r.Update(actorID, data, ifMatch)
+}
+
+func (s *ActorService) SearchActors(name string, limit int) ([]model.ActorSearchHit, error) {
+	return s.r.SearchActors(name, s.search.similarityThreshold(), limit)
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// apiKeyPrefix starts every API key, so that leaked keys are easy to recognize.
+const apiKeyPrefix = "fh_"
+
+// apiKeyDisplayLength is the number of leading characters of a key stored in plain text.
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+var (
+	ErrAPIKeyNotFound     = repository.ErrAPIKeyNotFound
+	ErrInvalidAPIKey      = model.NewError(model.ErrUnauthorized, "invalid_api_key", "invalid api key")
+	ErrInvalidAPIKeyInput = model.NewError(model.ErrValidation, "invalid_api_key_input", "invalid api key data")
+)
+
+type APIKeyService struct {
+	r    repository.APIKey
+	auth repository.Authorization
+}
+
+func NewAPIKeyService(r repository.APIKey, auth repository.Authorization) *APIKeyService {
+	return &APIKeyService{
+		r:    r,
+		auth: auth,
+	}
+}
+
+// CreateAPIKey creates a key on behalf of the identity. The key can only be scoped to
+// permissions the identity has itself.
+func (s *APIKeyService) CreateAPIKey(identity model.Identity, input model.APIKeyInput) (model.CreatedAPIKey, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return model.CreatedAPIKey{}, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyInput)
+	}
+
+	if len(input.Permissions) == 0 {
+		return model.CreatedAPIKey{}, fmt.Errorf("%w: at least one permission is required", ErrInvalidAPIKeyInput)
+	}
+
+	permissions := slices.Clone(input.Permissions)
+	slices.Sort(permissions)
+	permissions = slices.Compact(permissions)
+
+	for _, permission := range permissions {
+		if !identity.HasPermission(permission) {
+			return model.CreatedAPIKey{}, fmt.Errorf("%w: permission %s is not granted to you", ErrInvalidAPIKeyInput, permission)
+		}
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return model.CreatedAPIKey{}, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyInput)
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	key := apiKeyPrefix + secret
+
+	created, err := s.r.CreateAPIKey(model.APIKey{
+		Name:        name,
+		Prefix:      key[:apiKeyDisplayLength],
+		UserID:      identity.UserID,
+		Permissions: permissions,
+		ExpiresAt:   input.ExpiresAt,
+	}, hashToken(key))
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	return model.CreatedAPIKey{APIKey: created, Key: key}, nil
+}
+
+func (s *APIKeyService) GetAllAPIKeys() ([]model.APIKey, error) {
+	return s.r.GetAllAPIKeys()
+}
+
+func (s *APIKeyService) RevokeAPIKey(keyID int) error {
+	return s.r.RevokeAPIKey(keyID)
+}
+
+// ParseAPIKey authenticates a request by an API key. The key acts on behalf of its creator:
+// it is rejected if the creator is disabled, and it keeps only those of its permissions
+// that the creator's role still has.
+func (s *APIKeyService) ParseAPIKey(key string) (model.Identity, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return model.Identity{}, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.r.GetActiveAPIKey(hashToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return model.Identity{}, ErrInvalidAPIKey
+		}
+		return model.Identity{}, err
+	}
+
+	user, err := s.auth.GetUserByID(apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return model.Identity{}, ErrInvalidAPIKey
+		}
+		return model.Identity{}, err
+	}
+	if user.Disabled {
+		return model.Identity{}, ErrUserDisabled
+	}
+
+	rolePermissions, err := s.auth.GetRolePermissions(user.Role)
+	if err != nil {
+		return model.Identity{}, err
+	}
+
+	permissions := make([]string, 0, len(apiKey.Permissions))
+	for _, permission := range apiKey.Permissions {
+		if slices.Contains(rolePermissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	if err := s.r.TouchAPIKey(apiKey.ID); err != nil {
+		return model.Identity{}, fmt.Errorf("updating api key usage: %w", err)
+	}
+
+	identity := model.Identity{
+		UserID:      user.ID,
+		Role:        user.Role,
+		Permissions: permissions,
+		APIKeyID:    apiKey.ID,
+	}
+	if apiKey.ExpiresAt != nil {
+		identity.ExpiresAt = *apiKey.ExpiresAt
+	}
+
+	return identity, nil
+}
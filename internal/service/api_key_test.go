@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// fakeAPIKeyRepository keeps API keys in memory.
+type fakeAPIKeyRepository struct {
+	keys   map[string]model.APIKey
+	hashes map[int]string
+}
+
+func newFakeAPIKeyRepository() *fakeAPIKeyRepository {
+	return &fakeAPIKeyRepository{
+		keys:   make(map[string]model.APIKey),
+		hashes: make(map[int]string),
+	}
+}
+
+func (r *fakeAPIKeyRepository) CreateAPIKey(key model.APIKey, keyHash string) (model.APIKey, error) {
+	key.ID = len(r.keys) + 1
+	key.CreatedAt = time.Now()
+	r.keys[keyHash] = key
+	r.hashes[key.ID] = keyHash
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepository) GetAllAPIKeys() ([]model.APIKey, error) {
+	keys := make([]model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepository) GetActiveAPIKey(keyHash string) (model.APIKey, error) {
+	key, ok := r.keys[keyHash]
+	if !ok || key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now())) {
+		return model.APIKey{}, repository.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepository) TouchAPIKey(keyID int) error {
+	hash := r.hashes[keyID]
+	key := r.keys[hash]
+	now := time.Now()
+	key.LastUsedAt = &now
+	r.keys[hash] = key
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) RevokeAPIKey(keyID int) error {
+	hash, ok := r.hashes[keyID]
+	if !ok || r.keys[hash].RevokedAt != nil {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	key := r.keys[hash]
+	now := time.Now()
+	key.RevokedAt = &now
+	r.keys[hash] = key
+	return nil
+}
+
+func newTestAPIKeyService() (*APIKeyService, *fakeAPIKeyRepository, *fakeAuthRepository) {
+	keys := newFakeAPIKeyRepository()
+	auth := newFakeAuthRepository(model.User{ID: 1, Username: "admin", Role: "admin"})
+
+	return NewAPIKeyService(keys, auth), keys, auth
+}
+
+var testAdminIdentity = model.Identity{
+	UserID:      1,
+	Role:        "admin",
+	Permissions: []string{model.PermissionMovieWrite, model.PermissionUserManage},
+}
+
+func TestAPIKeyService_CreateAndParse(t *testing.T) {
+	s, repo, _ := newTestAPIKeyService()
+
+	created, err := s.CreateAPIKey(testAdminIdentity, model.APIKeyInput{
+		Name:        "ingestion",
+		Permissions: []string{model.PermissionMovieWrite, model.PermissionMovieWrite},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(created.Key) <= apiKeyDisplayLength || created.Prefix != created.Key[:apiKeyDisplayLength] {
+		t.Errorf("Expected prefix %q to start key %q", created.Prefix, created.Key)
+	}
+
+	if _, ok := repo.keys[created.Key]; ok {
+		t.Error("Expected the key to be stored hashed")
+	}
+
+	identity, err := s.ParseAPIKey(created.Key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if identity.UserID != 1 || identity.APIKeyID != created.ID || len(identity.Permissions) != 1 || !identity.HasPermission(model.PermissionMovieWrite) {
+		t.Errorf("Unexpected identity %+v", identity)
+	}
+
+	if repo.keys[hashToken(created.Key)].LastUsedAt == nil {
+		t.Error("Expected the last usage time to be recorded")
+	}
+
+	if err := s.RevokeAPIKey(created.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ParseAPIKey(created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKeyService_CreateAPIKey_Invalid(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		input model.APIKeyInput
+	}{
+		{name: "empty name", input: model.APIKeyInput{Name: " ", Permissions: []string{model.PermissionMovieWrite}}},
+		{name: "no permissions", input: model.APIKeyInput{Name: "ingestion"}},
+		{name: "permission not granted", input: model.APIKeyInput{Name: "ingestion", Permissions: []string{model.PermissionMovieDelete}}},
+		{name: "expired", input: model.APIKeyInput{Name: "ingestion", Permissions: []string{model.PermissionMovieWrite}, ExpiresAt: &past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newTestAPIKeyService()
+
+			if _, err := s.CreateAPIKey(testAdminIdentity, tt.input); !errors.Is(err, ErrInvalidAPIKeyInput) {
+				t.Errorf("Expected invalid input error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_ParseAPIKey_FollowsOwner(t *testing.T) {
+	s, _, auth := newTestAPIKeyService()
+
+	created, err := s.CreateAPIKey(testAdminIdentity, model.APIKeyInput{
+		Name:        "ingestion",
+		Permissions: []string{model.PermissionMovieWrite},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	role := "user"
+	if err := auth.UpdateUser(1, model.UpdateUserInput{Role: &role}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	identity, err := s.ParseAPIKey(created.Key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(identity.Permissions) != 0 {
+		t.Errorf("Expected the key to lose permissions of a demoted owner, got %v", identity.Permissions)
+	}
+
+	disabled := true
+	if err := auth.UpdateUser(1, model.UpdateUserInput{Disabled: &disabled}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ParseAPIKey(created.Key); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("Expected the key of a disabled owner to be rejected, got %v", err)
+	}
+}
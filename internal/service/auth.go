@@ -1,88 +1,483 @@
 package service
 
 import (
-	"crypto/sha1"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/avealice/filmhub/internal/model"
 	"github.com/avealice/filmhub/internal/repository"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 )
 
+// Default token lifetimes used when they are not configured.
 const (
-	salt       = "djfdfnnvnfbnv"
-	signingKey = "dkdjmvfdivs"
-	tokenTTL   = 12 * time.Hour
+	defaultTokenTTL        = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	defaultResetTokenTTL   = time.Hour
+)
+
+var (
+	ErrInvalidCredentials  = model.NewError(model.ErrUnauthorized, "invalid_credentials", "invalid username or password")
+	ErrUsernameTaken       = repository.ErrUsernameTaken
+	ErrInvalidRefreshToken = model.NewError(model.ErrUnauthorized, "invalid_refresh_token", "invalid refresh token")
+	ErrTokenRevoked        = model.NewError(model.ErrUnauthorized, "token_revoked", "token has been revoked")
+	ErrInvalidToken        = model.NewError(model.ErrUnauthorized, "invalid_token", "invalid access token")
+	ErrUserDisabled        = model.NewError(model.ErrForbidden, "user_disabled", "user account is disabled")
+	ErrIncorrectPassword   = model.NewError(model.ErrBadRequest, "incorrect_password", "current password is incorrect")
+	ErrInvalidResetToken   = model.NewError(model.ErrBadRequest, "invalid_reset_token", "invalid or expired password reset token")
 )
 
 type tokenClaims struct {
-	jwt.StandardClaims
+	jwt.RegisteredClaims
 	Role   string `json:"role"`
 	UserID int    `json:"user_id"`
 }
 
 type AuthService struct {
-	r repository.Authorization
+	r          repository.Authorization
+	keys       *KeySet
+	ttl        time.Duration
+	refreshTTL time.Duration
+	resetTTL   time.Duration
+	throttler  *LoginThrottler
+	resets     *LoginThrottler
+	passwords  PasswordPolicy
+	notifier   Notifier
+
+	// verify checks passwords against stored hashes, it is replaced in tests.
+	verify func(hash, password string) (ok, needsRehash bool, err error)
 }
 
-func NewAuthService(r repository.Authorization) *AuthService {
+func NewAuthService(r repository.Authorization, cfg AuthConfig) *AuthService {
+	ttl := cfg.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+
+	refreshTTL := cfg.RefreshTokenTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+
+	resetTTL := cfg.PasswordResetTTL
+	if resetTTL <= 0 {
+		resetTTL = defaultResetTokenTTL
+	}
+
+	notifier := cfg.Notifier
+	if notifier == nil {
+		notifier = NewWriterNotifier(os.Stdout)
+	}
+
 	return &AuthService{
-		r: r,
+		r:          r,
+		keys:       cfg.Keys,
+		ttl:        ttl,
+		refreshTTL: refreshTTL,
+		resetTTL:   resetTTL,
+		throttler:  NewLoginThrottler(cfg.LoginThrottle),
+		resets:     newPasswordResetThrottler(cfg.LoginThrottle),
+		passwords:  cfg.PasswordPolicy.withDefaults(),
+		notifier:   notifier,
+		verify:     verifyPassword,
 	}
 }
 
+// CreateUser registers a user. The username and the password are validated first,
+// and all their problems are reported together as a *model.ValidationError.
 func (s *AuthService) CreateUser(user model.User) (int, error) {
-	user.Password = generatePasswordHash(user.Password)
+	var verr model.ValidationError
+	validateUsername(&verr, user.Username)
+	s.passwords.validate(&verr, "password", user.Username, user.Password)
+	if err := verr.Err(); err != nil {
+		return -1, err
+	}
+
+	hash, err := hashPassword(user.Password)
+	if err != nil {
+		return -1, err
+	}
+
+	user.Password = hash
 	return s.r.CreateUser(user)
 }
 
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
-	user, err := s.r.GetUser(username, generatePasswordHash(password))
+// GenerateToken signs the user in and issues an access token and a refresh token
+// that starts a new token family. Failed attempts are counted for the username and
+// the client address clientIP, and too many of them block sign-in for a while.
+func (s *AuthService) GenerateToken(username, password, clientIP string) (model.Tokens, error) {
+	if err := s.throttler.Check(username, clientIP); err != nil {
+		return model.Tokens{}, err
+	}
+
+	user, err := s.r.GetUser(username)
 	if err != nil {
-		return "", err
+		if errors.Is(err, repository.ErrUserNotFound) {
+			// Spend as much time as on a wrong password, so that response times do not tell which users exist.
+			if _, _, err := s.verify(dummyPasswordHash, password); err != nil {
+				return model.Tokens{}, err
+			}
+			return model.Tokens{}, s.failSignIn(username, clientIP)
+		}
+		return model.Tokens{}, err
 	}
 
-	claims := &tokenClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		Role:   user.Role,
-		UserID: user.ID,
+	ok, needsRehash, err := s.verify(user.Password, password)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+	if !ok {
+		return model.Tokens{}, s.failSignIn(username, clientIP)
+	}
+	if user.Disabled {
+		return model.Tokens{}, ErrUserDisabled
+	}
+
+	if err := s.throttler.Success(username); err != nil {
+		return model.Tokens{}, err
+	}
+
+	if needsRehash {
+		hash, err := hashPassword(password)
+		if err != nil {
+			return model.Tokens{}, err
+		}
+
+		if err := s.r.UpdatePasswordHash(user.ID, hash); err != nil {
+			return model.Tokens{}, fmt.Errorf("updating password hash: %w", err)
+		}
+	}
+
+	return s.startSession(user)
+}
+
+// startSession issues an access token and a refresh token that starts a new token family.
+func (s *AuthService) startSession(user model.User) (model.Tokens, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	refreshToken, stored, err := s.newRefreshToken()
+	if err != nil {
+		return model.Tokens{}, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	stored.UserID = user.ID
+	stored.FamilyID = familyID
+	if err := s.r.CreateRefreshToken(stored); err != nil {
+		return model.Tokens{}, err
+	}
 
-	return token.SignedString([]byte(signingKey))
+	return s.issueTokens(user, refreshToken)
 }
 
-func generatePasswordHash(password string) string {
-	hash := sha1.New()
-	hash.Write([]byte(password))
+// failSignIn records a failed sign-in attempt and returns the error to report to the client.
+func (s *AuthService) failSignIn(username, clientIP string) error {
+	if err := s.throttler.Failure(username, clientIP); err != nil {
+		return err
+	}
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+	return ErrInvalidCredentials
 }
 
-func (s *AuthService) ParseToken(accessToken string) (int, string, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+// RefreshTokens exchanges a refresh token for a new pair of tokens. Every refresh token
+// can be used only once; reusing it revokes all tokens obtained from the same sign-in.
+func (s *AuthService) RefreshTokens(refreshToken string) (model.Tokens, error) {
+	if refreshToken == "" {
+		return model.Tokens{}, ErrInvalidRefreshToken
+	}
+
+	nextToken, next, err := s.newRefreshToken()
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	stored, err := s.r.RotateRefreshToken(hashToken(refreshToken), next)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) || errors.Is(err, repository.ErrRefreshTokenReused) {
+			return model.Tokens{}, ErrInvalidRefreshToken
 		}
+		return model.Tokens{}, err
+	}
 
-		return []byte(signingKey), nil
-	})
+	user, err := s.r.GetUserByID(stored.UserID)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+	if user.Disabled {
+		return model.Tokens{}, ErrUserDisabled
+	}
+
+	return s.issueTokens(user, nextToken)
+}
+
+// Logout revokes the access token of the identity and, if given, the refresh token
+// together with all tokens obtained from the same sign-in.
+func (s *AuthService) Logout(identity model.Identity, refreshToken string) error {
+	if refreshToken != "" {
+		err := s.r.RevokeRefreshTokenFamily(identity.UserID, hashToken(refreshToken))
+		if err != nil && !errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return err
+		}
+	}
+
+	return s.r.RevokeAccessToken(identity.TokenID, identity.ExpiresAt)
+}
 
+// ParseToken authenticates a request by an access token. Tokens that are malformed, expired, revoked or
+// issued to a user that no longer exists are rejected with an error of the ErrUnauthorized kind, any other
+// error is a failure to check the token.
+func (s *AuthService) ParseToken(accessToken string) (model.Identity, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, s.keys.Keyfunc)
 	if err != nil {
-		return -1, "", err
+		return model.Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return -1, "", errors.New("token claims are not of type *tokenClaims")
+		return model.Identity{}, fmt.Errorf("%w: unexpected claims", ErrInvalidToken)
+	}
+
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return model.Identity{}, fmt.Errorf("%w: token has no id or expiration time", ErrInvalidToken)
+	}
+
+	revoked, err := s.r.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return model.Identity{}, err
+	}
+	if revoked {
+		return model.Identity{}, ErrTokenRevoked
+	}
+
+	// The account is checked on every request so that disabling a user or changing
+	// their role takes effect before their access token expires.
+	user, err := s.r.GetUserByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return model.Identity{}, ErrInvalidToken
+		}
+		return model.Identity{}, err
+	}
+	if user.Disabled {
+		return model.Identity{}, ErrUserDisabled
+	}
+	// Token times have a precision of one second, so a token issued within the same second
+	// as the revocation survives it. Rejecting those would also reject the tokens issued
+	// right after a password change.
+	if user.SessionsRevokedAt != nil && claims.IssuedAt != nil &&
+		claims.IssuedAt.Time.Before(user.SessionsRevokedAt.Truncate(time.Second)) {
+		return model.Identity{}, ErrTokenRevoked
+	}
+
+	permissions, err := s.r.GetRolePermissions(user.Role)
+	if err != nil {
+		return model.Identity{}, err
+	}
+
+	return model.Identity{
+		UserID:      user.ID,
+		Role:        user.Role,
+		Permissions: permissions,
+		TokenID:     claims.ID,
+		ExpiresAt:   claims.ExpiresAt.Time,
+	}, nil
+}
+
+// ChangePassword changes the password of the user after checking the current one.
+// All sessions of the user are revoked and a new pair of tokens is issued, so the caller
+// stays signed in. Wrong current passwords count as failed sign-in attempts.
+func (s *AuthService) ChangePassword(userID int, currentPassword, newPassword, clientIP string) (model.Tokens, error) {
+	user, err := s.r.GetUserByID(userID)
+	if err != nil {
+		return model.Tokens{}, err
 	}
 
-	return claims.UserID, claims.Role, nil
+	if err := s.throttler.Check(user.Username, clientIP); err != nil {
+		return model.Tokens{}, err
+	}
+
+	ok, _, err := s.verify(user.Password, currentPassword)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+	if !ok {
+		if err := s.throttler.Failure(user.Username, clientIP); err != nil {
+			return model.Tokens{}, err
+		}
+		return model.Tokens{}, ErrIncorrectPassword
+	}
+
+	hash, err := s.newPasswordHash(user.Username, newPassword)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	if err := s.r.ChangePassword(user.ID, hash, time.Now()); err != nil {
+		return model.Tokens{}, err
+	}
+
+	return s.startSession(user)
+}
+
+// RequestPasswordReset issues a one-time reset token and delivers it to the user through
+// the notifier. Unknown and disabled users are silently ignored, so that the result does
+// not reveal which usernames exist. Every request is counted for the username and the client
+// address clientIP, whether the user exists or not, and requests over the limit are silently
+// ignored as well, so that neither a user nor the table of reset tokens can be flooded.
+func (s *AuthService) RequestPasswordReset(username, clientIP string) error {
+	if err := s.resets.Check(username, clientIP); err != nil {
+		if errors.Is(err, ErrTooManyAttempts) {
+			return nil
+		}
+		return err
+	}
+	if err := s.resets.Failure(username, clientIP); err != nil {
+		return err
+	}
+
+	user, err := s.r.GetUser(username)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Disabled {
+		return nil
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.resetTTL)
+	if err := s.r.CreatePasswordResetToken(user.ID, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	if err := s.notifier.SendPasswordReset(user.Username, token, expiresAt); err != nil {
+		return fmt.Errorf("sending password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets a new password. All sessions of the user
+// are revoked, and the failed sign-in attempts of the username are forgotten.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	tokenHash := hashToken(token)
+
+	userID, err := s.r.GetPasswordResetTokenUser(tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.r.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return ErrUserDisabled
+	}
+
+	hash, err := s.newPasswordHash(user.Username, newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.r.ResetPassword(tokenHash, hash, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	return s.throttler.Success(user.Username)
+}
+
+// newPasswordHash checks a new password against the policy and hashes it.
+func (s *AuthService) newPasswordHash(username, password string) (string, error) {
+	var verr model.ValidationError
+	s.passwords.validate(&verr, "new_password", username, password)
+	if err := verr.Err(); err != nil {
+		return "", err
+	}
+
+	return hashPassword(password)
+}
+
+// issueTokens signs a new access token for the user and pairs it with the refresh token.
+func (s *AuthService) issueTokens(user model.User, refreshToken string) (model.Tokens, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	now := time.Now()
+	claims := &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Role:   user.Role,
+		UserID: user.ID,
+	}
+
+	accessToken, err := s.keys.Sign(claims)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	return model.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.ttl.Seconds()),
+	}, nil
+}
+
+// newRefreshToken generates a refresh token and the record that stores its hash.
+func (s *AuthService) newRefreshToken() (string, model.RefreshToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", model.RefreshToken{}, err
+	}
+
+	return token, model.RefreshToken{
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
+
+// randomToken returns n random bytes encoded as URL-safe base64.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex encoded SHA-256 hash under which a token is stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuthService) JWKS() model.JSONWebKeySet {
+	return s.keys.JWKS()
 }
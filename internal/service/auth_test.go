@@ -0,0 +1,584 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// fakeAuthRepository keeps users and tokens in memory and mirrors the rotation
+// rules of the Postgres implementation.
+type fakeAuthRepository struct {
+	users       map[int]model.User
+	tokens      map[string]*fakeRefreshToken
+	revoked     map[string]time.Time
+	resetTokens map[string]*fakeResetToken
+	identities  map[string]int
+}
+
+type fakeResetToken struct {
+	userID    int
+	expiresAt time.Time
+	used      bool
+}
+
+type fakeRefreshToken struct {
+	model.RefreshToken
+	rotated bool
+	revoked bool
+}
+
+func newFakeAuthRepository(users ...model.User) *fakeAuthRepository {
+	r := &fakeAuthRepository{
+		users:       make(map[int]model.User),
+		tokens:      make(map[string]*fakeRefreshToken),
+		revoked:     make(map[string]time.Time),
+		resetTokens: make(map[string]*fakeResetToken),
+		identities:  make(map[string]int),
+	}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+
+	return r
+}
+
+func (r *fakeAuthRepository) CreateUser(user model.User) (int, error) {
+	if _, err := r.GetUser(user.Username); err == nil {
+		return -1, repository.ErrUsernameTaken
+	}
+
+	user.ID = len(r.users) + 1
+	r.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (r *fakeAuthRepository) GetUser(username string) (model.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+
+	return model.User{}, repository.ErrUserNotFound
+}
+
+func (r *fakeAuthRepository) UpdatePasswordHash(userID int, passwordHash string) error {
+	user := r.users[userID]
+	user.Password = passwordHash
+	r.users[userID] = user
+	return nil
+}
+
+func (r *fakeAuthRepository) GetUserByID(userID int) (model.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return model.User{}, repository.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (r *fakeAuthRepository) GetUserByIdentity(issuer, subject string) (model.User, error) {
+	userID, ok := r.identities[issuer+" "+subject]
+	if !ok {
+		return model.User{}, repository.ErrUserNotFound
+	}
+
+	return r.users[userID], nil
+}
+
+func (r *fakeAuthRepository) CreateUserWithIdentity(user model.User, issuer, subject string) (int, error) {
+	if _, err := r.GetUser(user.Username); err == nil {
+		return -1, repository.ErrUsernameTaken
+	}
+
+	user.ID = len(r.users) + 1
+	r.users[user.ID] = user
+	r.identities[issuer+" "+subject] = user.ID
+	return user.ID, nil
+}
+
+func (r *fakeAuthRepository) CreateRefreshToken(token model.RefreshToken) error {
+	r.tokens[token.TokenHash] = &fakeRefreshToken{RefreshToken: token}
+	return nil
+}
+
+func (r *fakeAuthRepository) RotateRefreshToken(tokenHash string, next model.RefreshToken) (model.RefreshToken, error) {
+	current, ok := r.tokens[tokenHash]
+	if !ok {
+		return model.RefreshToken{}, repository.ErrRefreshTokenNotFound
+	}
+
+	if current.rotated || current.revoked {
+		r.revokeFamily(current.FamilyID)
+		return model.RefreshToken{}, repository.ErrRefreshTokenReused
+	}
+
+	current.rotated = true
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	r.tokens[next.TokenHash] = &fakeRefreshToken{RefreshToken: next}
+
+	return next, nil
+}
+
+func (r *fakeAuthRepository) RevokeRefreshTokenFamily(userID int, tokenHash string) error {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.UserID != userID {
+		return repository.ErrRefreshTokenNotFound
+	}
+
+	r.revokeFamily(token.FamilyID)
+	return nil
+}
+
+func (r *fakeAuthRepository) revokeFamily(familyID string) {
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID {
+			token.revoked = true
+		}
+	}
+}
+
+func (r *fakeAuthRepository) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	r.revoked[tokenID] = expiresAt
+	return nil
+}
+
+func (r *fakeAuthRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	_, ok := r.revoked[tokenID]
+	return ok, nil
+}
+
+func (r *fakeAuthRepository) GetRolePermissions(role string) ([]string, error) {
+	if role == "admin" {
+		return []string{model.PermissionMovieWrite, model.PermissionUserManage}, nil
+	}
+
+	return []string{}, nil
+}
+
+func (r *fakeAuthRepository) GetAllUsers(limit, offset int) (model.UsersPage, error) {
+	return model.UsersPage{}, errors.New("not implemented")
+}
+
+func (r *fakeAuthRepository) GetUserAccount(userID int) (model.UserAccount, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return model.UserAccount{}, repository.ErrUserNotFound
+	}
+
+	return model.UserAccount{ID: user.ID, Username: user.Username, Role: user.Role, Disabled: user.Disabled}, nil
+}
+
+func (r *fakeAuthRepository) UpdateUser(userID int, input model.UpdateUserInput) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	if input.Role != nil {
+		user.Role = *input.Role
+	}
+	if input.Disabled != nil {
+		user.Disabled = *input.Disabled
+	}
+	if input.Password != nil {
+		user.Password = *input.Password
+	}
+	r.users[userID] = user
+
+	return nil
+}
+
+func (r *fakeAuthRepository) DeleteUser(userID int) error {
+	if _, ok := r.users[userID]; !ok {
+		return repository.ErrUserNotFound
+	}
+
+	delete(r.users, userID)
+	return nil
+}
+
+func (r *fakeAuthRepository) ChangePassword(userID int, passwordHash string, revokedAt time.Time) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	user.Password = passwordHash
+	user.SessionsRevokedAt = &revokedAt
+	r.users[userID] = user
+
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			token.revoked = true
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeAuthRepository) CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time) error {
+	r.resetTokens[tokenHash] = &fakeResetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *fakeAuthRepository) GetPasswordResetTokenUser(tokenHash string) (int, error) {
+	token, ok := r.resetTokens[tokenHash]
+	if !ok || token.used || !token.expiresAt.After(time.Now()) {
+		return 0, repository.ErrResetTokenNotFound
+	}
+
+	return token.userID, nil
+}
+
+func (r *fakeAuthRepository) ResetPassword(tokenHash, passwordHash string, revokedAt time.Time) error {
+	userID, err := r.GetPasswordResetTokenUser(tokenHash)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range r.resetTokens {
+		if token.userID == userID {
+			token.used = true
+		}
+	}
+
+	return r.ChangePassword(userID, passwordHash, revokedAt)
+}
+
+func newTestAuthService(t *testing.T) (*AuthService, *fakeAuthRepository) {
+	t.Helper()
+
+	keys, err := NewKeySet([]KeyConfig{{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret}}, "hs-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	hash, err := hashPassword("qwerty")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	repo := newFakeAuthRepository(model.User{ID: 1, Username: "alice", Password: hash, Role: "user"})
+
+	return NewAuthService(repo, AuthConfig{Keys: keys}), repo
+}
+
+func TestAuthService_RefreshTokens_Rotation(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	first, err := s.GenerateToken("alice", "qwerty", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, err := s.RefreshTokens(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("Expected refresh to issue new tokens")
+	}
+
+	identity, err := s.ParseToken(second.AccessToken)
+	if err != nil || identity.UserID != 1 || identity.Role != "user" {
+		t.Errorf("Expected access token of user 1, got %+v, %v", identity, err)
+	}
+
+	if _, err := s.RefreshTokens(first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected reused token to be rejected, got %v", err)
+	}
+
+	if _, err := s.RefreshTokens(second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected reuse to revoke the whole family, got %v", err)
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	tokens, err := s.GenerateToken("alice", "qwerty", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	identity, err := s.ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := s.Logout(identity, tokens.RefreshToken); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ParseToken(tokens.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Expected revoked access token to be rejected, got %v", err)
+	}
+
+	if _, err := s.RefreshTokens(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected revoked refresh token to be rejected, got %v", err)
+	}
+}
+
+func TestAuthService_GenerateToken_Throttling(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	if _, err := s.GenerateToken("mallory", "qwerty", "192.0.2.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected unknown user to be reported as invalid credentials, got %v", err)
+	}
+
+	for i := 0; i < defaultUsernamePolicy.FreeAttempts; i++ {
+		if _, err := s.GenerateToken("alice", "wrong", "192.0.2.2"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Expected invalid credentials, got %v", err)
+		}
+	}
+
+	if _, err := s.GenerateToken("alice", "wrong", "192.0.2.2"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Expected invalid credentials, got %v", err)
+	}
+
+	if _, err := s.GenerateToken("alice", "qwerty", "192.0.2.3"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("Expected sign-in to be throttled even with the right password, got %v", err)
+	}
+}
+
+func TestAuthService_GenerateToken_UnknownUserVerifiesPassword(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	var hashes []string
+	s.verify = func(hash, password string) (bool, bool, error) {
+		hashes = append(hashes, hash)
+		return verifyPassword(hash, password)
+	}
+
+	if _, err := s.GenerateToken("mallory", "qwerty", "192.0.2.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Expected invalid credentials, got %v", err)
+	}
+
+	if len(hashes) != 1 || hashes[0] != dummyPasswordHash {
+		t.Errorf("Expected the password to be checked against the dummy hash, got %q", hashes)
+	}
+}
+
+func TestAuthService_GenerateToken_UpgradesLegacyHash(t *testing.T) {
+	s, repo := newTestAuthService(t)
+	repo.users[1] = model.User{ID: 1, Username: "alice", Password: legacyPasswordHash("qwerty"), Role: "user"}
+
+	if _, err := s.GenerateToken("alice", "qwerty", "192.0.2.1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !isBcryptHash(repo.users[1].Password) {
+		t.Errorf("Expected legacy hash to be replaced with bcrypt, got %q", repo.users[1].Password)
+	}
+}
+
+func TestAuthService_ParseToken_DisabledUser(t *testing.T) {
+	s, repo := newTestAuthService(t)
+
+	tokens, err := s.GenerateToken("alice", "qwerty", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	disabled := true
+	if err := repo.UpdateUser(1, model.UpdateUserInput{Disabled: &disabled}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ParseToken(tokens.AccessToken); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("Expected token of a disabled user to be rejected, got %v", err)
+	}
+
+	if _, err := s.GenerateToken("alice", "qwerty", "192.0.2.1"); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("Expected disabled user to be unable to sign in, got %v", err)
+	}
+}
+
+func TestAuthService_ParseToken_InvalidToken(t *testing.T) {
+	s, repo := newTestAuthService(t)
+
+	if _, err := s.ParseToken("not.a.token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected malformed token to be rejected with %v, got %v", ErrInvalidToken, err)
+	}
+
+	tokens, err := s.GenerateToken("alice", "qwerty", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := repo.DeleteUser(1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ParseToken(tokens.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected token of a deleted user to be rejected with %v, got %v", ErrInvalidToken, err)
+	}
+}
+
+func TestUserService_SelfModification(t *testing.T) {
+	repo := newFakeAuthRepository(model.User{ID: 1, Username: "admin", Role: "admin"})
+	s := NewUserService(repo, PasswordPolicy{})
+
+	user := "user"
+	disabled := true
+
+	if _, err := s.UpdateUser(1, 1, model.UpdateUserInput{Role: &user}); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Expected demoting yourself to fail, got %v", err)
+	}
+
+	if _, err := s.UpdateUser(1, 1, model.UpdateUserInput{Disabled: &disabled}); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Expected disabling yourself to fail, got %v", err)
+	}
+
+	if err := s.DeleteUser(1, 1); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Expected deleting yourself to fail, got %v", err)
+	}
+
+	password := "new password"
+	account, err := s.UpdateUser(1, 1, model.UpdateUserInput{Password: &password})
+	if err != nil || account.Role != "admin" {
+		t.Errorf("Expected changing your own password to succeed, got %+v, %v", account, err)
+	}
+
+	if !isBcryptHash(repo.users[1].Password) {
+		t.Errorf("Expected the new password to be hashed, got %q", repo.users[1].Password)
+	}
+}
+
+// recordingNotifier keeps the last password reset token it was asked to deliver.
+type recordingNotifier struct {
+	username string
+	token    string
+}
+
+func (n *recordingNotifier) SendPasswordReset(username, token string, expiresAt time.Time) error {
+	n.username = username
+	n.token = token
+	return nil
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	s, repo := newTestAuthService(t)
+
+	old, err := s.GenerateToken("alice", "qwerty", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ChangePassword(1, "wrong", "Correct-horse1", "192.0.2.1"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("Expected wrong current password to be rejected, got %v", err)
+	}
+
+	var verr *model.ValidationError
+	if _, err := s.ChangePassword(1, "qwerty", "short", "192.0.2.1"); !errors.As(err, &verr) || verr.Fields[0].Field != "new_password" {
+		t.Errorf("Expected weak new password to be rejected, got %v", err)
+	}
+
+	tokens, err := s.ChangePassword(1, "qwerty", "Correct-horse1", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.ParseToken(tokens.AccessToken); err != nil {
+		t.Errorf("Expected the new access token to be valid, got %v", err)
+	}
+
+	if _, err := s.RefreshTokens(old.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected old refresh token to be revoked, got %v", err)
+	}
+
+	// Access tokens have a precision of one second, so move the revocation past the
+	// issue time of the old token.
+	user := repo.users[1]
+	revokedAt := time.Now().Add(2 * time.Second)
+	user.SessionsRevokedAt = &revokedAt
+	repo.users[1] = user
+
+	if _, err := s.ParseToken(old.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Expected old access token to be revoked, got %v", err)
+	}
+
+	if _, err := s.GenerateToken("alice", "Correct-horse1", "192.0.2.1"); err != nil {
+		t.Errorf("Expected sign-in with the new password to succeed, got %v", err)
+	}
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	s, repo := newTestAuthService(t)
+	notifier := &recordingNotifier{}
+	s.notifier = notifier
+
+	if err := s.RequestPasswordReset("mallory", "192.0.2.1"); err != nil || notifier.token != "" {
+		t.Errorf("Expected unknown user to be silently ignored, got %v", err)
+	}
+
+	if err := s.RequestPasswordReset("alice", "192.0.2.1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if notifier.username != "alice" || notifier.token == "" {
+		t.Fatalf("Expected reset token to be sent to alice, got %+v", notifier)
+	}
+
+	if _, ok := repo.resetTokens[notifier.token]; ok {
+		t.Error("Expected the reset token to be stored hashed")
+	}
+
+	if err := s.ResetPassword("unknown", "Correct-horse1"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected unknown token to be rejected, got %v", err)
+	}
+
+	var verr *model.ValidationError
+	if err := s.ResetPassword(notifier.token, "short"); !errors.As(err, &verr) {
+		t.Errorf("Expected weak password to be rejected, got %v", err)
+	}
+
+	if err := s.ResetPassword(notifier.token, "Correct-horse1"); err != nil {
+		t.Fatalf("Expected the token to survive a rejected password, got %v", err)
+	}
+
+	if repo.users[1].SessionsRevokedAt == nil {
+		t.Error("Expected sessions to be revoked")
+	}
+
+	if err := s.ResetPassword(notifier.token, "Another-horse2"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected a used token to be rejected, got %v", err)
+	}
+
+	if _, err := s.GenerateToken("alice", "Correct-horse1", "192.0.2.1"); err != nil {
+		t.Errorf("Expected sign-in with the new password to succeed, got %v", err)
+	}
+}
+
+func TestAuthService_RequestPasswordReset_Throttling(t *testing.T) {
+	s, repo := newTestAuthService(t)
+	notifier := &recordingNotifier{}
+	s.notifier = notifier
+
+	for i := 0; i < defaultUsernamePolicy.FreeAttempts+1; i++ {
+		if err := s.RequestPasswordReset("alice", "192.0.2.1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	issued := len(repo.resetTokens)
+
+	notifier.token = ""
+	if err := s.RequestPasswordReset("alice", "192.0.2.2"); err != nil {
+		t.Fatalf("Expected a throttled request to be silently ignored, got %v", err)
+	}
+	if len(repo.resetTokens) != issued || notifier.token != "" {
+		t.Errorf("Expected no token to be issued while throttled, got %d tokens instead of %d", len(repo.resetTokens), issued)
+	}
+
+	// Reset requests are counted separately from sign-in attempts.
+	if _, err := s.GenerateToken("alice", "qwerty", "192.0.2.1"); err != nil {
+		t.Errorf("Expected sign-in not to be throttled by reset requests, got %v", err)
+	}
+}
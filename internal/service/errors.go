@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// Kinds of service errors. Every sentinel error of the services wraps one of them, so that
+// callers can map a whole kind of failures, for example to an HTTP status, with errors.Is.
+var (
+	ErrNotFound   = model.ErrNotFound
+	ErrConflict   = model.ErrConflict
+	ErrValidation = model.ErrValidation
+	ErrForbidden  = model.ErrForbidden
+
+	ErrUnauthorized = model.ErrUnauthorized
+	ErrBadRequest   = model.ErrBadRequest
+	ErrBadGateway   = model.ErrBadGateway
+
+	ErrPreconditionFailed = model.ErrPreconditionFailed
+)
+
+// ErrVersionMismatch is reported by conditional writes when the resource has been changed since the client read it.
+var ErrVersionMismatch = repository.ErrVersionMismatch
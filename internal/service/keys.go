@@ -0,0 +1,222 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported token signing algorithms.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// KeyConfig describes a single token signing key.
+type KeyConfig struct {
+	ID        string // Key identifier, put into the kid header of tokens
+	Algorithm string // HS256, RS256 or EdDSA
+	Secret    string // Shared secret of an HS256 key
+	File      string // Path to a PEM encoded private key of an RS256 or EdDSA key
+}
+
+// signingKey is a loaded key of a KeySet.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{} // []byte for HMAC, crypto.Signer otherwise
+	public  interface{} // []byte for HMAC, crypto.PublicKey otherwise
+}
+
+// KeySet holds the keys that verify access tokens and the active key that signs new ones.
+// Keeping retired keys in the set lets tokens issued before a rotation stay valid until they expire.
+type KeySet struct {
+	keys   map[string]*signingKey
+	order  []string
+	active *signingKey
+}
+
+// NewKeySet loads the keys and selects the key with identifier activeID for signing.
+func NewKeySet(keys []KeyConfig, activeID string) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*signingKey)}
+
+	for _, cfg := range keys {
+		if cfg.ID == "" {
+			return nil, errors.New("signing key id must not be empty")
+		}
+		if _, ok := set.keys[cfg.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key id %q", cfg.ID)
+		}
+
+		key, err := loadSigningKey(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("loading signing key %q: %w", cfg.ID, err)
+		}
+
+		set.keys[cfg.ID] = key
+		set.order = append(set.order, cfg.ID)
+	}
+
+	active, ok := set.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured", activeID)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+func loadSigningKey(cfg KeyConfig) (*signingKey, error) {
+	key := &signingKey{id: cfg.ID}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 bytes long")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.private = []byte(cfg.Secret)
+		key.public = []byte(cfg.Secret)
+		return key, nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	if cfg.File == "" {
+		return nil, fmt.Errorf("%s key requires a private key file", cfg.Algorithm)
+	}
+
+	data, err := os.ReadFile(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if cfg.Algorithm != AlgorithmRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used with %s", cfg.Algorithm)
+		}
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits long")
+		}
+		key.method = jwt.SigningMethodRS256
+		key.private = private
+		key.public = &private.PublicKey
+	case ed25519.PrivateKey:
+		if cfg.Algorithm != AlgorithmEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used with %s", cfg.Algorithm)
+		}
+		key.method = jwt.SigningMethodEdDSA
+		key.private = private
+		key.public = private.Public()
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+
+	return key, nil
+}
+
+// parsePrivateKey decodes a PEM encoded PKCS #8 or PKCS #1 private key.
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+
+		return signer, nil
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// Sign issues a token with the given claims signed by the active key.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.method, claims)
+	token.Header["kid"] = s.active.id
+
+	return token.SignedString(s.active.private)
+}
+
+// Keyfunc returns the verification key for a token, selected by its kid header.
+// The token algorithm must match the algorithm of the key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key id")
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return key.public, nil
+}
+
+// JWKS returns the public keys of the set. HS256 keys are shared secrets and are never published.
+func (s *KeySet) JWKS() model.JSONWebKeySet {
+	set := model.JSONWebKeySet{Keys: []model.JSONWebKey{}}
+
+	for _, id := range s.order {
+		key := s.keys[id]
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, model.JSONWebKey{
+				KeyType:   "RSA",
+				Use:       "sig",
+				KeyID:     key.id,
+				Algorithm: key.method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, model.JSONWebKey{
+				KeyType:   "OKP",
+				Use:       "sig",
+				KeyID:     key.id,
+				Algorithm: key.method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "test-secret-0123456789abcdef0123456789"
+
+func writeKeyFile(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error marshaling private key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Error writing key file: %v", err)
+	}
+
+	return path
+}
+
+func testClaims() *tokenClaims {
+	return &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role:   "user",
+		UserID: 7,
+	}
+}
+
+func parseTestToken(keys *KeySet, token string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, keys.Keyfunc)
+	return claims, err
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating RSA key: %v", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating Ed25519 key: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  KeyConfig
+	}{
+		{name: "HS256", key: KeyConfig{ID: "hs", Algorithm: AlgorithmHS256, Secret: testSecret}},
+		{name: "RS256", key: KeyConfig{ID: "rs", Algorithm: AlgorithmRS256, File: writeKeyFile(t, rsaKey)}},
+		{name: "EdDSA", key: KeyConfig{ID: "ed", Algorithm: AlgorithmEdDSA, File: writeKeyFile(t, edKey)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeySet([]KeyConfig{tt.key}, tt.key.ID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			token, err := keys.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			parsed, err := jwt.Parse(token, keys.Keyfunc)
+			if err != nil {
+				t.Fatalf("Error parsing token: %v", err)
+			}
+
+			if parsed.Header["kid"] != tt.key.ID || parsed.Method.Alg() != tt.key.Algorithm {
+				t.Errorf("Expected kid %q and alg %q, got %v and %q", tt.key.ID, tt.key.Algorithm, parsed.Header["kid"], parsed.Method.Alg())
+			}
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating Ed25519 key: %v", err)
+	}
+
+	oldKey := KeyConfig{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret}
+	newKey := KeyConfig{ID: "ed-1", Algorithm: AlgorithmEdDSA, File: writeKeyFile(t, edKey)}
+
+	before, err := NewKeySet([]KeyConfig{oldKey}, oldKey.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	token, err := before.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	during, err := NewKeySet([]KeyConfig{oldKey, newKey}, newKey.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if claims, err := parseTestToken(during, token); err != nil || claims.UserID != 7 {
+		t.Errorf("Expected token signed by the retired key to be valid, got %v", err)
+	}
+
+	after, err := NewKeySet([]KeyConfig{newKey}, newKey.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := parseTestToken(after, token); err == nil {
+		t.Error("Expected token signed by a removed key to be rejected")
+	}
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	keys, err := NewKeySet([]KeyConfig{{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret}}, "hs-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, testClaims())
+	token.Header["kid"] = "hs-1"
+	signed, err := token.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := parseTestToken(keys, signed); err == nil {
+		t.Error("Expected token with a different algorithm to be rejected")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating RSA key: %v", err)
+	}
+
+	keys, err := NewKeySet([]KeyConfig{
+		{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret},
+		{ID: "rs-1", Algorithm: AlgorithmRS256, File: writeKeyFile(t, rsaKey)},
+	}, "rs-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("Expected only the RSA key to be published, got %+v", jwks.Keys)
+	}
+
+	key := jwks.Keys[0]
+	if key.KeyID != "rs-1" || key.KeyType != "RSA" || key.Algorithm != AlgorithmRS256 || key.E != "AQAB" || key.N == "" {
+		t.Errorf("Unexpected JWK %+v", key)
+	}
+}
+
+func TestNewKeySet_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []KeyConfig
+		active string
+	}{
+		{name: "unknown active key", keys: []KeyConfig{{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret}}, active: "hs-2"},
+		{name: "short secret", keys: []KeyConfig{{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: "short"}}, active: "hs-1"},
+		{name: "duplicate id", keys: []KeyConfig{
+			{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret},
+			{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: testSecret},
+		}, active: "hs-1"},
+		{name: "unsupported algorithm", keys: []KeyConfig{{ID: "hs-1", Algorithm: "none"}}, active: "hs-1"},
+		{name: "missing key file", keys: []KeyConfig{{ID: "rs-1", Algorithm: AlgorithmRS256}}, active: "rs-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeySet(tt.keys, tt.active); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
@@ -49,28 +49,56 @@ func (mr *MockAuthorizationMockRecorder) CreateUser(user interface{}) *gomock.Ca
 }
 
 // GenerateToken mocks base method
-func (m *MockAuthorization) GenerateToken(username, password string) (string, error) {
+func (m *MockAuthorization) GenerateToken(username, password, clientIP string) (model.Tokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", username, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GenerateToken", username, password, clientIP)
+	ret0, _ := ret[0].(model.Tokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken
-func (mr *MockAuthorizationMockRecorder) GenerateToken(username, password interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) GenerateToken(username, password, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), username, password, clientIP)
+}
+
+// RefreshTokens mocks base method
+func (m *MockAuthorization) RefreshTokens(refreshToken string) (model.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", refreshToken)
+	ret0, _ := ret[0].(model.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens
+func (mr *MockAuthorizationMockRecorder) RefreshTokens(refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockAuthorization)(nil).RefreshTokens), refreshToken)
+}
+
+// Logout mocks base method
+func (m *MockAuthorization) Logout(identity model.Identity, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", identity, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout
+func (mr *MockAuthorizationMockRecorder) Logout(identity, refreshToken interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthorization)(nil).Logout), identity, refreshToken)
 }
 
 // ParseToken mocks base method
-func (m *MockAuthorization) ParseToken(token string) (int, string, error) {
+func (m *MockAuthorization) ParseToken(token string) (model.Identity, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ParseToken", token)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(model.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ParseToken indicates an expected call of ParseToken
@@ -79,6 +107,63 @@ func (mr *MockAuthorizationMockRecorder) ParseToken(token interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), token)
 }
 
+// JWKS mocks base method
+func (m *MockAuthorization) JWKS() model.JSONWebKeySet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(model.JSONWebKeySet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS
+func (mr *MockAuthorizationMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuthorization)(nil).JWKS))
+}
+
+// ChangePassword mocks base method
+func (m *MockAuthorization) ChangePassword(userID int, currentPassword, newPassword, clientIP string) (model.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", userID, currentPassword, newPassword, clientIP)
+	ret0, _ := ret[0].(model.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword
+func (mr *MockAuthorizationMockRecorder) ChangePassword(userID, currentPassword, newPassword, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthorization)(nil).ChangePassword), userID, currentPassword, newPassword, clientIP)
+}
+
+// RequestPasswordReset mocks base method
+func (m *MockAuthorization) RequestPasswordReset(username, clientIP string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", username, clientIP)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset
+func (mr *MockAuthorizationMockRecorder) RequestPasswordReset(username, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockAuthorization)(nil).RequestPasswordReset), username, clientIP)
+}
+
+// ResetPassword mocks base method
+func (m *MockAuthorization) ResetPassword(token, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", token, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockAuthorizationMockRecorder) ResetPassword(token, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthorization)(nil).ResetPassword), token, newPassword)
+}
+
 // MockMovie is a mock of Movie interface
 type MockMovie struct {
 	ctrl     *gomock.Controller
@@ -103,26 +188,27 @@ func (m *MockMovie) EXPECT() *MockMovieMockRecorder {
 }
 
 // GetAllMovies mocks base method
-func (m *MockMovie) GetAllMovies(sortBy, sortOrder string) ([]model.MovieWithActors, error) {
+func (m *MockMovie) GetAllMovies(filter model.MovieFilter, sort model.Sort, page model.Pagination) (model.MoviesPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllMovies", sortBy, sortOrder)
-	ret0, _ := ret[0].([]model.MovieWithActors)
+	ret := m.ctrl.Call(m, "GetAllMovies", filter, sort, page)
+	ret0, _ := ret[0].(model.MoviesPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllMovies indicates an expected call of GetAllMovies
-func (mr *MockMovieMockRecorder) GetAllMovies(sortBy, sortOrder interface{}) *gomock.Call {
+func (mr *MockMovieMockRecorder) GetAllMovies(filter, sort, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllMovies", reflect.TypeOf((*MockMovie)(nil).GetAllMovies), sortBy, sortOrder)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllMovies", reflect.TypeOf((*MockMovie)(nil).GetAllMovies), filter, sort, page)
 }
 
 // CreateMovie mocks base method
-func (m *MockMovie) CreateMovie(movie model.InputMovie) error {
+func (m *MockMovie) CreateMovie(movie model.InputMovie) (model.MovieWithActors, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateMovie", movie)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(model.MovieWithActors)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateMovie indicates an expected call of CreateMovie
@@ -147,61 +233,135 @@ func (mr *MockMovieMockRecorder) GetMovieByID(movieID interface{}) *gomock.Call
 }
 
 // DeleteByID mocks base method
-func (m *MockMovie) DeleteByID(movieID int) error {
+func (m *MockMovie) DeleteByID(movieID int, ifMatch model.Versions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", movieID)
+	ret := m.ctrl.Call(m, "DeleteByID", movieID, ifMatch)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteByID indicates an expected call of DeleteByID
-func (mr *MockMovieMockRecorder) DeleteByID(movieID interface{}) *gomock.Call {
+func (mr *MockMovieMockRecorder) DeleteByID(movieID, ifMatch interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockMovie)(nil).DeleteByID), movieID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockMovie)(nil).DeleteByID), movieID, ifMatch)
 }
 
 // UpdateMovie mocks base method
-func (m *MockMovie) UpdateMovie(movieID int, data model.InputMovie) error {
+func (m *MockMovie) UpdateMovie(movieID int, data model.InputMovie, ifMatch model.Versions) (model.MovieWithActors, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMovie", movieID, data, ifMatch)
+	ret0, _ := ret[0].(model.MovieWithActors)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMovie indicates an expected call of UpdateMovie
+func (mr *MockMovieMockRecorder) UpdateMovie(movieID, data, ifMatch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMovie", reflect.TypeOf((*MockMovie)(nil).UpdateMovie), movieID, data, ifMatch)
+}
+
+// PatchMovie mocks base method
+func (m *MockMovie) PatchMovie(movieID int, input model.PatchMovieInput, ifMatch model.Versions) (model.MovieWithActors, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMovie", movieID, data)
+	ret := m.ctrl.Call(m, "PatchMovie", movieID, input, ifMatch)
+	ret0, _ := ret[0].(model.MovieWithActors)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchMovie indicates an expected call of PatchMovie
+func (mr *MockMovieMockRecorder) PatchMovie(movieID, input, ifMatch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchMovie", reflect.TypeOf((*MockMovie)(nil).PatchMovie), movieID, input, ifMatch)
+}
+
+// AddActor mocks base method
+func (m *MockMovie) AddActor(movieID, actorID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddActor", movieID, actorID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateMovie indicates an expected call of UpdateMovie
-func (mr *MockMovieMockRecorder) UpdateMovie(movieID, data interface{}) *gomock.Call {
+// AddActor indicates an expected call of AddActor
+func (mr *MockMovieMockRecorder) AddActor(movieID, actorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActor", reflect.TypeOf((*MockMovie)(nil).AddActor), movieID, actorID)
+}
+
+// RemoveActor mocks base method
+func (m *MockMovie) RemoveActor(movieID, actorID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveActor", movieID, actorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveActor indicates an expected call of RemoveActor
+func (mr *MockMovieMockRecorder) RemoveActor(movieID, actorID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMovie", reflect.TypeOf((*MockMovie)(nil).UpdateMovie), movieID, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveActor", reflect.TypeOf((*MockMovie)(nil).RemoveActor), movieID, actorID)
 }
 
 // GetMoviesByActor mocks base method
-func (m *MockMovie) GetMoviesByActor(actor string) ([]model.MovieWithActors, error) {
+func (m *MockMovie) GetMoviesByActor(actor string, sort model.Sort) ([]model.MovieWithActors, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMoviesByActor", actor)
+	ret := m.ctrl.Call(m, "GetMoviesByActor", actor, sort)
 	ret0, _ := ret[0].([]model.MovieWithActors)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMoviesByActor indicates an expected call of GetMoviesByActor
-func (mr *MockMovieMockRecorder) GetMoviesByActor(actor interface{}) *gomock.Call {
+func (mr *MockMovieMockRecorder) GetMoviesByActor(actor, sort interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesByActor", reflect.TypeOf((*MockMovie)(nil).GetMoviesByActor), actor)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesByActor", reflect.TypeOf((*MockMovie)(nil).GetMoviesByActor), actor, sort)
 }
 
 // GetMoviesByTitle mocks base method
-func (m *MockMovie) GetMoviesByTitle(title string) ([]model.MovieWithActors, error) {
+func (m *MockMovie) GetMoviesByTitle(title string, sort model.Sort) ([]model.MovieWithActors, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMoviesByTitle", title)
+	ret := m.ctrl.Call(m, "GetMoviesByTitle", title, sort)
 	ret0, _ := ret[0].([]model.MovieWithActors)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMoviesByTitle indicates an expected call of GetMoviesByTitle
-func (mr *MockMovieMockRecorder) GetMoviesByTitle(title interface{}) *gomock.Call {
+func (mr *MockMovieMockRecorder) GetMoviesByTitle(title, sort interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesByTitle", reflect.TypeOf((*MockMovie)(nil).GetMoviesByTitle), title, sort)
+}
+
+// SearchMovies mocks base method
+func (m *MockMovie) SearchMovies(query string, limit, offset int) ([]model.MovieSearchHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMovies", query, limit, offset)
+	ret0, _ := ret[0].([]model.MovieSearchHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMovies indicates an expected call of SearchMovies
+func (mr *MockMovieMockRecorder) SearchMovies(query, limit, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesByTitle", reflect.TypeOf((*MockMovie)(nil).GetMoviesByTitle), title)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMovies", reflect.TypeOf((*MockMovie)(nil).SearchMovies), query, limit, offset)
+}
+
+// GetMoviesByActorFuzzy mocks base method
+func (m *MockMovie) GetMoviesByActorFuzzy(actor string) ([]model.MovieWithActors, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMoviesByActorFuzzy", actor)
+	ret0, _ := ret[0].([]model.MovieWithActors)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMoviesByActorFuzzy indicates an expected call of GetMoviesByActorFuzzy
+func (mr *MockMovieMockRecorder) GetMoviesByActorFuzzy(actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesByActorFuzzy", reflect.TypeOf((*MockMovie)(nil).GetMoviesByActorFuzzy), actor)
 }
 
 // MockActor is a mock of Actor interface
@@ -228,10 +388,10 @@ func (m *MockActor) EXPECT() *MockActorMockRecorder {
 }
 
 // CreateActor mocks base method
-func (m *MockActor) CreateActor(actor model.InputActor) (int, error) {
+func (m *MockActor) CreateActor(actor model.InputActor) (model.ActorWithMovies, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateActor", actor)
-	ret0, _ := ret[0].(int)
+	ret0, _ := ret[0].(model.ActorWithMovies)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -243,32 +403,32 @@ func (mr *MockActorMockRecorder) CreateActor(actor interface{}) *gomock.Call {
 }
 
 // GetAllActors mocks base method
-func (m *MockActor) GetAllActors() ([]model.ActorWithMovies, error) {
+func (m *MockActor) GetAllActors(sort model.Sort, page model.Pagination) (model.ActorsPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllActors")
-	ret0, _ := ret[0].([]model.ActorWithMovies)
+	ret := m.ctrl.Call(m, "GetAllActors", sort, page)
+	ret0, _ := ret[0].(model.ActorsPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllActors indicates an expected call of GetAllActors
-func (mr *MockActorMockRecorder) GetAllActors() *gomock.Call {
+func (mr *MockActorMockRecorder) GetAllActors(sort, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllActors", reflect.TypeOf((*MockActor)(nil).GetAllActors))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllActors", reflect.TypeOf((*MockActor)(nil).GetAllActors), sort, page)
 }
 
 // Delete mocks base method
-func (m *MockActor) Delete(actorID int) error {
+func (m *MockActor) Delete(actorID int, ifMatch model.Versions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", actorID)
+	ret := m.ctrl.Call(m, "Delete", actorID, ifMatch)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockActorMockRecorder) Delete(actorID interface{}) *gomock.Call {
+func (mr *MockActorMockRecorder) Delete(actorID, ifMatch interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockActor)(nil).Delete), actorID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockActor)(nil).Delete), actorID, ifMatch)
 }
 
 // Get mocks base method
@@ -287,15 +447,316 @@ func (mr *MockActorMockRecorder) Get(actorID interface{}) *gomock.Call {
 }
 
 // Update mocks base method
-func (m *MockActor) Update(actorID int, data model.InputActor) error {
+func (m *MockActor) Update(actorID int, data model.InputActor, ifMatch model.Versions) (model.ActorWithMovies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", actorID, data, ifMatch)
+	ret0, _ := ret[0].(model.ActorWithMovies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockActorMockRecorder) Update(actorID, data, ifMatch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockActor)(nil).Update), actorID, data, ifMatch)
+}
+
+// SearchActors mocks base method
+func (m *MockActor) SearchActors(name string, limit int) ([]model.ActorSearchHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchActors", name, limit)
+	ret0, _ := ret[0].([]model.ActorSearchHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchActors indicates an expected call of SearchActors
+func (mr *MockActorMockRecorder) SearchActors(name, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchActors", reflect.TypeOf((*MockActor)(nil).SearchActors), name, limit)
+}
+
+// MockUser is a mock of User interface
+type MockUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMockRecorder
+}
+
+// MockUserMockRecorder is the mock recorder for MockUser
+type MockUserMockRecorder struct {
+	mock *MockUser
+}
+
+// NewMockUser creates a new mock instance
+func NewMockUser(ctrl *gomock.Controller) *MockUser {
+	mock := &MockUser{ctrl: ctrl}
+	mock.recorder = &MockUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUser) EXPECT() *MockUserMockRecorder {
+	return m.recorder
+}
+
+// GetAllUsers mocks base method
+func (m *MockUser) GetAllUsers(limit, offset int) (model.UsersPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", actorID, data)
+	ret := m.ctrl.Call(m, "GetAllUsers", limit, offset)
+	ret0, _ := ret[0].(model.UsersPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllUsers indicates an expected call of GetAllUsers
+func (mr *MockUserMockRecorder) GetAllUsers(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUser)(nil).GetAllUsers), limit, offset)
+}
+
+// GetUser mocks base method
+func (m *MockUser) GetUser(userID int) (model.UserAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", userID)
+	ret0, _ := ret[0].(model.UserAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockUserMockRecorder) GetUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUser)(nil).GetUser), userID)
+}
+
+// UpdateUser mocks base method
+func (m *MockUser) UpdateUser(currentUserID, userID int, input model.UpdateUserInput) (model.UserAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", currentUserID, userID, input)
+	ret0, _ := ret[0].(model.UserAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser
+func (mr *MockUserMockRecorder) UpdateUser(currentUserID, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUser)(nil).UpdateUser), currentUserID, userID, input)
+}
+
+// DeleteUser mocks base method
+func (m *MockUser) DeleteUser(currentUserID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", currentUserID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update
-func (mr *MockActorMockRecorder) Update(actorID, data interface{}) *gomock.Call {
+// DeleteUser indicates an expected call of DeleteUser
+func (mr *MockUserMockRecorder) DeleteUser(currentUserID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUser)(nil).DeleteUser), currentUserID, userID)
+}
+
+// MockAPIKey is a mock of APIKey interface
+type MockAPIKey struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyMockRecorder
+}
+
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
+}
+
+// NewMockAPIKey creates a new mock instance
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method
+func (m *MockAPIKey) CreateAPIKey(identity model.Identity, input model.APIKeyInput) (model.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", identity, input)
+	ret0, _ := ret[0].(model.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey
+func (mr *MockAPIKeyMockRecorder) CreateAPIKey(identity, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKey)(nil).CreateAPIKey), identity, input)
+}
+
+// GetAllAPIKeys mocks base method
+func (m *MockAPIKey) GetAllAPIKeys() ([]model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllAPIKeys")
+	ret0, _ := ret[0].([]model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllAPIKeys indicates an expected call of GetAllAPIKeys
+func (mr *MockAPIKeyMockRecorder) GetAllAPIKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAPIKeys", reflect.TypeOf((*MockAPIKey)(nil).GetAllAPIKeys))
+}
+
+// RevokeAPIKey mocks base method
+func (m *MockAPIKey) RevokeAPIKey(keyID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey
+func (mr *MockAPIKeyMockRecorder) RevokeAPIKey(keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKey)(nil).RevokeAPIKey), keyID)
+}
+
+// ParseAPIKey mocks base method
+func (m *MockAPIKey) ParseAPIKey(key string) (model.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAPIKey", key)
+	ret0, _ := ret[0].(model.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseAPIKey indicates an expected call of ParseAPIKey
+func (mr *MockAPIKeyMockRecorder) ParseAPIKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAPIKey", reflect.TypeOf((*MockAPIKey)(nil).ParseAPIKey), key)
+}
+
+// MockProfile is a mock of Profile interface
+type MockProfile struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileMockRecorder
+}
+
+// MockProfileMockRecorder is the mock recorder for MockProfile
+type MockProfileMockRecorder struct {
+	mock *MockProfile
+}
+
+// NewMockProfile creates a new mock instance
+func NewMockProfile(ctrl *gomock.Controller) *MockProfile {
+	mock := &MockProfile{ctrl: ctrl}
+	mock.recorder = &MockProfileMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProfile) EXPECT() *MockProfileMockRecorder {
+	return m.recorder
+}
+
+// GetProfile mocks base method
+func (m *MockProfile) GetProfile(userID int) (model.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", userID)
+	ret0, _ := ret[0].(model.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile
+func (mr *MockProfileMockRecorder) GetProfile(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockProfile)(nil).GetProfile), userID)
+}
+
+// GetPreferences mocks base method
+func (m *MockProfile) GetPreferences(userID int) (model.Preferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", userID)
+	ret0, _ := ret[0].(model.Preferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences
+func (mr *MockProfileMockRecorder) GetPreferences(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockProfile)(nil).GetPreferences), userID)
+}
+
+// UpdatePreferences mocks base method
+func (m *MockProfile) UpdatePreferences(userID int, input model.UpdatePreferencesInput) (model.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", userID, input)
+	ret0, _ := ret[0].(model.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences
+func (mr *MockProfileMockRecorder) UpdatePreferences(userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockProfile)(nil).UpdatePreferences), userID, input)
+}
+
+// MockOIDC is a mock of OIDC interface
+type MockOIDC struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCMockRecorder
+}
+
+// MockOIDCMockRecorder is the mock recorder for MockOIDC
+type MockOIDCMockRecorder struct {
+	mock *MockOIDC
+}
+
+// NewMockOIDC creates a new mock instance
+func NewMockOIDC(ctrl *gomock.Controller) *MockOIDC {
+	mock := &MockOIDC{ctrl: ctrl}
+	mock.recorder = &MockOIDCMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOIDC) EXPECT() *MockOIDCMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method
+func (m *MockOIDC) AuthCodeURL() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL
+func (mr *MockOIDCMockRecorder) AuthCodeURL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDC)(nil).AuthCodeURL))
+}
+
+// SignIn mocks base method
+func (m *MockOIDC) SignIn(code, state string) (model.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIn", code, state)
+	ret0, _ := ret[0].(model.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignIn indicates an expected call of SignIn
+func (mr *MockOIDCMockRecorder) SignIn(code, state interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockActor)(nil).Update), actorID, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockOIDC)(nil).SignIn), code, state)
 }
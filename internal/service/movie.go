@@ -5,21 +5,38 @@ import (
 	"github.com/avealice/filmhub/internal/repository"
 )
 
+var (
+	ErrMovieNotFound = repository.ErrMovieNotFound
+	ErrMovieExists   = repository.ErrMovieExists
+
+	ErrMovieActorNotFound = repository.ErrMovieActorNotFound
+)
+
 type MovieService struct {
-	r repository.Movie
+	r      repository.Movie
+	search SearchConfig
 }
 
-func NewMovieService(r repository.Movie) *MovieService {
+func NewMovieService(r repository.Movie, search SearchConfig) *MovieService {
 	return &MovieService{
-		r: r,
+		r:      r,
+		search: search,
 	}
 }
 
-func (s *MovieService) GetAllMovies(sortBy, sortOrder string) ([]model.MovieWithActors, error) {
-	return s.r.GetAllMovies(sortBy, sortOrder)
+func (s *MovieService) GetAllMovies(filter model.MovieFilter, sort model.Sort, page model.Pagination) (model.MoviesPage, error) {
+	if err := sort.Validate(model.MovieSortFields); err != nil {
+		return model.MoviesPage{}, err
+	}
+
+	return s.r.GetAllMovies(filter, sort, page)
 }
 
-func (s *MovieService) CreateMovie(movie model.InputMovie) error {
+func (s *MovieService) CreateMovie(movie model.InputMovie) (model.MovieWithActors, error) {
+	if err := validateInputMovie(movie); err != nil {
+		return model.MovieWithActors{}, err
+	}
+
 	return s.r.CreateMovie(movie)
 }
 
@@ -27,18 +44,59 @@ func (s *MovieService) GetMovieByID(movieID int) (model.MovieWithActors, error)
 	return s.r.GetMovieByID(movieID)
 }
 
-func (s *MovieService) DeleteByID(movieID int) error {
-	return s.r.DeleteByID(movieID)
+func (s *MovieService) DeleteByID(movieID int, ifMatch model.Versions) error {
+	return s.r.DeleteByID(movieID, ifMatch)
 }
 
-func (s *MovieService) UpdateMovie(movieID int, data model.InputMovie) error {
-	return s.r.UpdateMovie(movieID, data)
+func (s *MovieService) UpdateMovie(movieID int, data model.InputMovie, ifMatch model.Versions) (model.MovieWithActors, error) {
+	if err := validateInputMovie(data); err != nil {
+		return model.MovieWithActors{}, err
+	}
+
+	return s.r.UpdateMovie(movieID, data, ifMatch)
 }
 
-func (s *MovieService) GetMoviesByTitle(title string) ([]model.MovieWithActors, error) {
-	return s.r.GetMoviesByTitle(title)
+func (s *MovieService) PatchMovie(movieID int, input model.PatchMovieInput, ifMatch model.Versions) (model.MovieWithActors, error) {
+	if err := validatePatchMovieInput(input); err != nil {
+		return model.MovieWithActors{}, err
+	}
+
+	return s.r.PatchMovie(movieID, input, ifMatch)
+}
+
+func (s *MovieService) AddActor(movieID, actorID int) error {
+	return s.r.AddActor(movieID, actorID)
+}
+
+func (s *MovieService) RemoveActor(movieID, actorID int) error {
+	return s.r.RemoveActor(movieID, actorID)
+}
+
+func (s *MovieService) GetMoviesByTitle(title string, sort model.Sort) ([]model.MovieWithActors, error) {
+	if err := sort.Validate(model.MovieSortFields); err != nil {
+		return nil, err
+	}
+
+	return s.r.GetMoviesByTitle(title, sort)
+}
+
+func (s *MovieService) GetMoviesByActor(actor string, sort model.Sort) ([]model.MovieWithActors, error) {
+	if err := sort.Validate(model.MovieSortFields); err != nil {
+		return nil, err
+	}
+
+	return s.r.GetMoviesByActor(actor, sort)
+}
+
+func (s *MovieService) SearchMovies(query string, limit, offset int) ([]model.MovieSearchHit, error) {
+	tsQuery := buildTSQuery(query)
+	if tsQuery == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	return s.r.SearchMovies(tsQuery, limit, offset)
 }
 
-func (s *MovieService) GetMoviesByActor(actor string) ([]model.MovieWithActors, error) {
-	return s.r.GetMoviesByActor(actor)
+func (s *MovieService) GetMoviesByActorFuzzy(actor string) ([]model.MovieWithActors, error) {
+	return s.r.GetMoviesByActorFuzzy(actor, s.search.similarityThreshold())
 }
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Notifier delivers messages to users. Users have no e-mail address or phone number
+// in FilmHub, so implementations identify the recipient by username.
+type Notifier interface {
+	// SendPasswordReset delivers a one-time password reset token that is valid until expiresAt.
+	SendPasswordReset(username, token string, expiresAt time.Time) error
+}
+
+// WriterNotifier writes notifications as lines of text to a writer such as stdout or a file.
+// It lets the reset flow work in development without an SMTP server.
+type WriterNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterNotifier(w io.Writer) *WriterNotifier {
+	return &WriterNotifier{w: w}
+}
+
+func (n *WriterNotifier) SendPasswordReset(username, token string, expiresAt time.Time) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := fmt.Fprintf(n.w, "%s password reset for %q: token %s, valid until %s\n",
+		time.Now().Format(time.RFC3339), username, token, expiresAt.Format(time.RFC3339))
+	return err
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// Defaults of the OpenID Connect settings used when they are not configured.
+const (
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCGroupsClaim   = "groups"
+	defaultOIDCRole          = "user"
+	defaultOIDCStateTTL      = 10 * time.Minute
+	defaultOIDCTimeout       = 10 * time.Second
+)
+
+var (
+	ErrInvalidOIDCState   = model.NewError(model.ErrBadRequest, "invalid_oidc_state", "invalid or expired sign-in state")
+	ErrOIDCAuthentication = model.NewError(model.ErrUnauthorized, "oidc_authentication_failed", "identity provider authentication failed")
+	ErrOIDCProvider       = model.NewError(model.ErrBadGateway, "oidc_provider_unavailable", "identity provider is unavailable")
+)
+
+// OIDCConfig holds the settings of sign-in through an OpenID Connect provider.
+type OIDCConfig struct {
+	Issuer        string            // URL of the provider, sign-in through the provider is disabled if empty
+	ClientID      string            // Client identifier registered at the provider
+	ClientSecret  string            // Client secret registered at the provider
+	RedirectURL   string            // URL of /auth/oidc/callback registered at the provider
+	Scopes        []string          // Requested scopes, openid is always requested
+	UsernameClaim string            // ID token claim with the username of new users, preferred_username by default
+	GroupsClaim   string            // ID token claim with the groups of the user, groups by default
+	RoleMapping   []OIDCRoleMapping // Roles of provider groups, the first rule matching a group of the user wins
+	DefaultRole   string            // Role of users in none of the mapped groups, user by default
+	StateTTL      time.Duration     // Time the user has to complete sign-in at the provider
+	HTTPClient    *http.Client      // Client of the provider requests, with a 10 second timeout by default
+}
+
+// OIDCRoleMapping grants a FilmHub role to the members of a provider group.
+type OIDCRoleMapping struct {
+	Group string
+	Role  string
+}
+
+// oidcLogin is a sign-in started by AuthCodeURL and waiting for the provider callback.
+type oidcLogin struct {
+	nonce     string
+	verifier  string
+	expiresAt time.Time
+}
+
+// OIDCService signs users in with the authorization code flow of an OpenID Connect provider.
+// Provider subjects are linked to FilmHub users, which are created on the first sign-in, and
+// the role of the user follows the provider groups on every sign-in.
+//
+// Pending sign-ins are kept in memory, so the callback must reach the instance that started
+// the sign-in.
+type OIDCService struct {
+	r        repository.Authorization
+	auth     *AuthService
+	cfg      OIDCConfig
+	provider *oidcProvider
+
+	mu     sync.Mutex
+	logins map[string]oidcLogin
+	now    func() time.Time
+}
+
+func NewOIDCService(r repository.Authorization, auth *AuthService, cfg OIDCConfig) *OIDCService {
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = defaultOIDCUsernameClaim
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = defaultOIDCGroupsClaim
+	}
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = defaultOIDCRole
+	}
+	if cfg.StateTTL <= 0 {
+		cfg.StateTTL = defaultOIDCStateTTL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: defaultOIDCTimeout}
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+
+	return &OIDCService{
+		r:        r,
+		auth:     auth,
+		cfg:      cfg,
+		provider: newOIDCProvider(cfg.Issuer, cfg.HTTPClient),
+		logins:   make(map[string]oidcLogin),
+		now:      time.Now,
+	}
+}
+
+// AuthCodeURL starts a sign-in and returns the URL of the provider the user is redirected to.
+// The request is protected by a one-time state, a nonce and a PKCE code challenge.
+func (s *OIDCService) AuthCodeURL() (string, error) {
+	metadata, err := s.provider.discover()
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	for key, login := range s.logins {
+		if !login.expiresAt.After(now) {
+			delete(s.logins, key)
+		}
+	}
+	s.logins[state] = oidcLogin{nonce: nonce, verifier: verifier, expiresAt: now.Add(s.cfg.StateTTL)}
+	s.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.cfg.ClientID},
+		"redirect_uri":          {s.cfg.RedirectURL},
+		"scope":                 {strings.Join(s.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// SignIn completes a sign-in started by AuthCodeURL: the authorization code is exchanged for an
+// ID token, the subject of the token is mapped to a FilmHub user and the usual tokens are issued.
+func (s *OIDCService) SignIn(code, state string) (model.Tokens, error) {
+	s.mu.Lock()
+	login, ok := s.logins[state]
+	delete(s.logins, state)
+	s.mu.Unlock()
+
+	if !ok || !login.expiresAt.After(s.now()) {
+		return model.Tokens{}, ErrInvalidOIDCState
+	}
+
+	metadata, err := s.provider.discover()
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	rawToken, err := s.provider.exchange(metadata, s.cfg, code, login.verifier)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	claims, err := s.provider.verifyIDToken(metadata, rawToken, s.cfg.ClientID, login.nonce)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return model.Tokens{}, fmt.Errorf("%w: ID token has no subject", ErrOIDCAuthentication)
+	}
+
+	role := s.mapRole(stringsClaim(claims[s.cfg.GroupsClaim]))
+
+	user, err := s.r.GetUserByIdentity(s.provider.issuer, subject)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		username, _ := claims[s.cfg.UsernameClaim].(string)
+		user, err = s.createUser(subject, username, role)
+		if err != nil {
+			return model.Tokens{}, err
+		}
+	case err != nil:
+		return model.Tokens{}, err
+	case user.Disabled:
+		return model.Tokens{}, ErrUserDisabled
+	case user.Role != role:
+		if err := s.r.UpdateUser(user.ID, model.UpdateUserInput{Role: &role}); err != nil {
+			return model.Tokens{}, fmt.Errorf("updating role of user %d: %w", user.ID, err)
+		}
+		user.Role = role
+	}
+
+	return s.auth.startSession(user)
+}
+
+// createUser registers the user of a provider subject on the first sign-in.
+func (s *OIDCService) createUser(subject, username, role string) (model.User, error) {
+	var verr model.ValidationError
+	validateUsername(&verr, username)
+	if verr.Err() != nil {
+		return model.User{}, fmt.Errorf("%w: claim %s is not a valid username", ErrOIDCAuthentication, s.cfg.UsernameClaim)
+	}
+
+	user := model.User{Username: username, Role: role}
+
+	id, err := s.r.CreateUserWithIdentity(user, s.provider.issuer, subject)
+	if err != nil {
+		return model.User{}, err
+	}
+	user.ID = id
+
+	return user, nil
+}
+
+// mapRole returns the role of the first mapping rule that matches one of the groups.
+func (s *OIDCService) mapRole(groups []string) string {
+	for _, mapping := range s.cfg.RoleMapping {
+		if slices.Contains(groups, mapping.Group) {
+			return mapping.Role
+		}
+	}
+
+	return s.cfg.DefaultRole
+}
+
+// stringsClaim converts a claim holding a string or a list of strings to a slice.
+func stringsClaim(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}
+	case []interface{}:
+		values := make([]string, 0, len(claim))
+		for _, v := range claim {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// oidcKeysRefreshInterval limits how often the provider keys are fetched again when an ID token
+// is signed by an unknown key, so that forged tokens cannot make FilmHub flood the provider.
+const oidcKeysRefreshInterval = time.Minute
+
+// oidcSigningMethods are the ID token algorithms accepted from a provider.
+var oidcSigningMethods = []string{"RS256", "ES256", "EdDSA"}
+
+// oidcMetadata is the part of the provider configuration document (OpenID Connect Discovery)
+// used by the authorization code flow.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider talks to an OpenID Connect provider. The configuration document and the signing
+// keys are fetched on first use and cached, so that FilmHub starts even if the provider is down.
+type oidcProvider struct {
+	issuer string
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func newOIDCProvider(issuer string, client *http.Client) *oidcProvider {
+	return &oidcProvider{
+		issuer: strings.TrimSuffix(issuer, "/"),
+		client: client,
+	}
+}
+
+// discover returns the configuration document of the provider.
+func (p *oidcProvider) discover() (oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return *p.metadata, nil
+	}
+
+	var metadata oidcMetadata
+	if err := p.getJSON(p.issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return oidcMetadata{}, err
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.issuer {
+		return oidcMetadata{}, fmt.Errorf("%w: configuration issuer %q does not match %q", ErrOIDCProvider, metadata.Issuer, p.issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return oidcMetadata{}, fmt.Errorf("%w: incomplete provider configuration", ErrOIDCProvider)
+	}
+
+	p.metadata = &metadata
+	return metadata, nil
+}
+
+// exchange redeems an authorization code at the token endpoint and returns the raw ID token.
+func (p *oidcProvider) exchange(metadata oidcMetadata, cfg OIDCConfig, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrOIDCProvider, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: decoding token response: %s", ErrOIDCProvider, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+		return "", fmt.Errorf("%w: token endpoint: %s %s", ErrOIDCAuthentication, body.Error, body.ErrorDescription)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%w: token endpoint returned %s", ErrOIDCProvider, resp.Status)
+	case body.IDToken == "":
+		return "", fmt.Errorf("%w: token response has no id_token", ErrOIDCAuthentication)
+	}
+
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiration and nonce of an ID token
+// and returns its claims.
+func (p *oidcProvider) verifyIDToken(metadata oidcMetadata, rawToken, clientID, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(oidcSigningMethods))
+
+	_, err := parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return p.key(metadata, token)
+	})
+	if err != nil {
+		if errors.Is(err, ErrOIDCProvider) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrOIDCAuthentication, err)
+	}
+
+	switch {
+	case !claims.VerifyIssuer(metadata.Issuer, true):
+		return nil, fmt.Errorf("%w: unexpected ID token issuer", ErrOIDCAuthentication)
+	case !claims.VerifyAudience(clientID, true):
+		return nil, fmt.Errorf("%w: ID token is issued to another client", ErrOIDCAuthentication)
+	case !claims.VerifyExpiresAt(time.Now().Unix(), true):
+		return nil, fmt.Errorf("%w: ID token has expired", ErrOIDCAuthentication)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: ID token nonce does not match", ErrOIDCAuthentication)
+	}
+
+	return claims, nil
+}
+
+// key returns the public key of the provider that signed the token, selected by its kid header.
+// Unknown keys trigger a refetch of the key set, which lets the provider rotate keys.
+func (p *oidcProvider) key(metadata oidcMetadata, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.findKey(kid)
+	if !ok && time.Since(p.keysFetchedAt) >= oidcKeysRefreshInterval {
+		if err := p.fetchKeys(metadata.JWKSURI); err != nil {
+			return nil, err
+		}
+		key, ok = p.findKey(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		ok = token.Method.Alg() == "RS256"
+	case *ecdsa.PublicKey:
+		ok = token.Method.Alg() == "ES256"
+	case ed25519.PublicKey:
+		ok = token.Method.Alg() == "EdDSA"
+	}
+	if !ok {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return key, nil
+}
+
+// findKey looks a key up by its identifier. A token without kid is accepted only
+// when the provider publishes a single key.
+func (p *oidcProvider) findKey(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(p.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *oidcProvider) fetchKeys(jwksURI string) error {
+	var set model.JSONWebKeySet
+	if err := p.getJSON(jwksURI, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			// Keys of unsupported types are skipped, the provider may publish them for other clients.
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	return nil
+}
+
+func (p *oidcProvider) getJSON(endpoint string, v interface{}) error {
+	resp, err := p.client.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrOIDCProvider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %s", ErrOIDCProvider, endpoint, resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("%w: decoding %s: %s", ErrOIDCProvider, endpoint, err)
+	}
+
+	return nil
+}
+
+// parseJSONWebKey converts an RSA, P-256 or Ed25519 public key from the JWK format.
+func parseJSONWebKey(jwk model.JSONWebKey) (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid EC public key")
+		}
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, errors.New("invalid EC public key")
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
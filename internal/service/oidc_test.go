@@ -0,0 +1,445 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testOIDCClientID     = "filmhub"
+	testOIDCClientSecret = "client-secret"
+	testOIDCRedirectURL  = "http://filmhub.test/auth/oidc/callback"
+)
+
+// mockIdP is a minimal OpenID Connect provider that signs in a configurable user
+// without asking for credentials.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	subject  string
+	username string
+	groups   []string
+	audience string
+	codes    map[string]mockAuthorization
+}
+
+// mockAuthorization is an authorization code issued by the mock provider.
+type mockAuthorization struct {
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// mockIdPKey is the signing key of the mock providers, generated once to keep the tests fast.
+var mockIdPKey = sync.OnceValues(func() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 2048)
+})
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	key, err := mockIdPKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	idp := &mockIdP{
+		key:      key,
+		audience: testOIDCClientID,
+		codes:    make(map[string]mockAuthorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.configuration)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// signInAs sets the user that the provider signs in.
+func (idp *mockIdP) signInAs(subject, username string, groups ...string) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.subject, idp.username, idp.groups = subject, username, groups
+}
+
+func (idp *mockIdP) configuration(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 idp.server.URL,
+		"authorization_endpoint": idp.server.URL + "/authorize",
+		"token_endpoint":         idp.server.URL + "/token",
+		"jwks_uri":               idp.server.URL + "/jwks",
+	})
+}
+
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != testOIDCClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	code, _ := randomToken(16)
+
+	idp.mu.Lock()
+	idp.codes[code] = mockAuthorization{
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		redirectURI: query.Get("redirect_uri"),
+	}
+	idp.mu.Unlock()
+
+	redirect := query.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, _ := r.BasicAuth()
+	if clientID != testOIDCClientID || secret != testOIDCClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	code := r.PostFormValue("code")
+	authorization, ok := idp.codes[code]
+	delete(idp.codes, code)
+
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || authorization.redirectURI != r.PostFormValue("redirect_uri") ||
+		authorization.challenge != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"aud":                idp.audience,
+		"sub":                idp.subject,
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              authorization.nonce,
+		"preferred_username": idp.username,
+		"groups":             idp.groups,
+	})
+	token.Header["kid"] = "idp-1"
+
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(model.JSONWebKeySet{Keys: []model.JSONWebKey{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		KeyID:     "idp-1",
+		Algorithm: "RS256",
+		N:         base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+	}}})
+}
+
+func newTestOIDCService(t *testing.T, idp *mockIdP) (*OIDCService, *AuthService, *fakeAuthRepository) {
+	t.Helper()
+
+	auth, repo := newTestAuthService(t)
+
+	s := NewOIDCService(repo, auth, OIDCConfig{
+		Issuer:       idp.server.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCClientSecret,
+		RedirectURL:  testOIDCRedirectURL,
+		Scopes:       []string{"profile", "groups"},
+		RoleMapping: []OIDCRoleMapping{
+			{Group: "filmhub-admins", Role: "admin"},
+			{Group: "filmhub-editors", Role: "editor"},
+		},
+	})
+
+	return s, auth, repo
+}
+
+// authorize starts a sign-in and follows it through the provider, returning the code and
+// the state the provider sends to the callback.
+func authorize(t *testing.T, s *OIDCService) (code, state string) {
+	t.Helper()
+
+	authURL, err := s.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatalf("Expected a redirect to the callback, got %s", resp.Status)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCService_SignIn(t *testing.T) {
+	idp := newMockIdP(t)
+	s, auth, repo := newTestOIDCService(t, idp)
+
+	idp.signInAs("subject-1", "bob", "staff", "filmhub-editors")
+
+	tokens, err := s.SignIn(authorize(t, s))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	identity, err := auth.ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	user := repo.users[identity.UserID]
+	if user.Username != "bob" || identity.Role != "editor" {
+		t.Errorf("Expected editor bob, got %q with role %q", user.Username, identity.Role)
+	}
+
+	if tokens.RefreshToken == "" {
+		t.Error("Expected a refresh token")
+	}
+
+	idp.signInAs("subject-1", "bob", "filmhub-admins", "filmhub-editors")
+
+	tokens, err = s.SignIn(authorize(t, s))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, err := auth.ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if second.UserID != identity.UserID || second.Role != "admin" {
+		t.Errorf("Expected user %d to become admin, got user %d with role %q", identity.UserID, second.UserID, second.Role)
+	}
+
+	if len(repo.users) != 2 {
+		t.Errorf("Expected a single user to be created, got %d users", len(repo.users)-1)
+	}
+
+	if _, err := auth.GenerateToken("bob", "", "192.0.2.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected a provider user to have no password, got %v", err)
+	}
+}
+
+func TestOIDCService_SignIn_DefaultRole(t *testing.T) {
+	idp := newMockIdP(t)
+	s, auth, _ := newTestOIDCService(t, idp)
+
+	idp.signInAs("subject-1", "bob")
+
+	tokens, err := s.SignIn(authorize(t, s))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	identity, err := auth.ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if identity.Role != "user" {
+		t.Errorf("Expected role user, got %q", identity.Role)
+	}
+}
+
+func TestOIDCService_SignIn_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(idp *mockIdP, s *OIDCService, repo *fakeAuthRepository)
+		state    func(code, state string) (string, string)
+		expected error
+	}{
+		{
+			name:     "unknown state",
+			state:    func(code, state string) (string, string) { return code, "forged" },
+			expected: ErrInvalidOIDCState,
+		},
+		{
+			name:     "invalid code",
+			state:    func(code, state string) (string, string) { return "forged", state },
+			expected: ErrOIDCAuthentication,
+		},
+		{
+			name: "token issued to another client",
+			setup: func(idp *mockIdP, s *OIDCService, repo *fakeAuthRepository) {
+				idp.audience = "another-client"
+			},
+			expected: ErrOIDCAuthentication,
+		},
+		{
+			name: "wrong client secret",
+			setup: func(idp *mockIdP, s *OIDCService, repo *fakeAuthRepository) {
+				s.cfg.ClientSecret = "wrong"
+			},
+			expected: ErrOIDCAuthentication,
+		},
+		{
+			name: "username of a local user",
+			setup: func(idp *mockIdP, s *OIDCService, repo *fakeAuthRepository) {
+				idp.signInAs("subject-2", "alice")
+			},
+			expected: ErrUsernameTaken,
+		},
+		{
+			name: "invalid username",
+			setup: func(idp *mockIdP, s *OIDCService, repo *fakeAuthRepository) {
+				idp.signInAs("subject-2", "bob smith")
+			},
+			expected: ErrOIDCAuthentication,
+		},
+		{
+			name: "disabled user",
+			setup: func(idp *mockIdP, s *OIDCService, repo *fakeAuthRepository) {
+				repo.users[2] = model.User{ID: 2, Username: "bob", Role: "user", Disabled: true}
+				repo.identities[idp.server.URL+" subject-1"] = 2
+			},
+			expected: ErrUserDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			s, _, repo := newTestOIDCService(t, idp)
+
+			idp.signInAs("subject-1", "bob")
+			if tt.setup != nil {
+				tt.setup(idp, s, repo)
+			}
+
+			code, state := authorize(t, s)
+			if tt.state != nil {
+				code, state = tt.state(code, state)
+			}
+
+			if _, err := s.SignIn(code, state); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected error %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestOIDCService_SignIn_StateIsSingleUse(t *testing.T) {
+	idp := newMockIdP(t)
+	s, _, _ := newTestOIDCService(t, idp)
+
+	idp.signInAs("subject-1", "bob")
+
+	code, state := authorize(t, s)
+	if _, err := s.SignIn(code, state); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := s.SignIn(code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Expected a replayed callback to be rejected, got %v", err)
+	}
+}
+
+func TestOIDCService_SignIn_ExpiredState(t *testing.T) {
+	idp := newMockIdP(t)
+	s, _, _ := newTestOIDCService(t, idp)
+
+	idp.signInAs("subject-1", "bob")
+
+	code, state := authorize(t, s)
+	s.now = func() time.Time { return time.Now().Add(defaultOIDCStateTTL) }
+
+	if _, err := s.SignIn(code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Expected an expired sign-in to be rejected, got %v", err)
+	}
+}
+
+func TestOIDCService_ProviderUnavailable(t *testing.T) {
+	idp := newMockIdP(t)
+	s, _, _ := newTestOIDCService(t, idp)
+	idp.server.Close()
+
+	if _, err := s.AuthCodeURL(); !errors.Is(err, ErrOIDCProvider) {
+		t.Errorf("Expected provider error, got %v", err)
+	}
+}
+
+func TestParseJSONWebKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+
+	tests := []struct {
+		name    string
+		jwk     model.JSONWebKey
+		wantErr bool
+	}{
+		{name: "EC", jwk: model.JSONWebKey{KeyType: "EC", Curve: "P-256", X: encode(ecKey.X.FillBytes(make([]byte, 32))), Y: encode(ecKey.Y.FillBytes(make([]byte, 32)))}},
+		{name: "Ed25519", jwk: model.JSONWebKey{KeyType: "OKP", Curve: "Ed25519", X: encode(edKey)}},
+		{name: "EC point not on curve", jwk: model.JSONWebKey{KeyType: "EC", Curve: "P-256", X: encode(make([]byte, 32)), Y: encode(make([]byte, 32))}, wantErr: true},
+		{name: "unsupported curve", jwk: model.JSONWebKey{KeyType: "EC", Curve: "P-384"}, wantErr: true},
+		{name: "symmetric key", jwk: model.JSONWebKey{KeyType: "oct"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJSONWebKey(tt.jwk)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordHashCost is the bcrypt cost of new password hashes. Hashes with a lower cost
+// are upgraded on the next successful sign-in.
+const passwordHashCost = 12
+
+// dummyPasswordHash is a bcrypt hash of a random password with the cost of new hashes. Sign-in checks the password
+// against it when there is no such user, so that unknown usernames take as long to reject as wrong passwords.
+const dummyPasswordHash = "$2a$12$zsFCNr.7NFMQ/lUQUZUhduKHrdzx1YGRhd7pTR.Z123JCgudXFr.e"
+
+// legacySalt was appended to the SHA-1 digest by the original password hashing.
+const legacySalt = "djfdfnnvnfbnv"
+
+// hashPassword returns a bcrypt hash of the password. bcrypt generates a random salt
+// for every hash and stores it in the result.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// verifyPassword checks the password against a stored hash, which is either a bcrypt hash
+// or a legacy SHA-1 one. needsRehash reports whether the hash should be replaced with a new one.
+func verifyPassword(hash, password string) (ok, needsRehash bool, err error) {
+	if hash == "" {
+		// Users created on sign-in through an identity provider have no password.
+		return false, false, nil
+	}
+
+	if !isBcryptHash(hash) {
+		ok = subtle.ConstantTimeCompare([]byte(hash), []byte(legacyPasswordHash(password))) == 1
+		return ok, ok, nil
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, err
+	}
+
+	return true, cost < passwordHashCost, nil
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// legacyPasswordHash reproduces the unsalted SHA-1 hashing used before bcrypt
+// so that existing users can still sign in and have their hashes upgraded.
+func legacyPasswordHash(password string) string {
+	hash := sha1.New()
+	hash.Write([]byte(password))
+
+	return fmt.Sprintf("%x", hash.Sum([]byte(legacySalt)))
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+// bcryptMaxPasswordLength is the number of bytes bcrypt takes into account; longer passwords
+// would be silently truncated.
+const bcryptMaxPasswordLength = 72
+
+const defaultMinPasswordLength = 8
+
+// usernameRe is the format of usernames: 3 to 32 latin letters, digits, dots, dashes and underscores.
+var usernameRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// PasswordPolicy describes the passwords accepted on sign-up and password changes.
+type PasswordPolicy struct {
+	MinLength        int                 // Minimum length in characters
+	MaxLength        int                 // Maximum length in bytes, at most 72
+	RequireLowercase bool                // Whether a lowercase letter is required
+	RequireUppercase bool                // Whether an uppercase letter is required
+	RequireDigit     bool                // Whether a digit is required
+	RequireSymbol    bool                // Whether a character other than a letter or a digit is required
+	Denylist         map[string]struct{} // Lowercased common passwords that are rejected
+}
+
+// withDefaults fills the unset length limits of the policy.
+func (p PasswordPolicy) withDefaults() PasswordPolicy {
+	if p.MinLength <= 0 {
+		p.MinLength = defaultMinPasswordLength
+	}
+	if p.MaxLength <= 0 || p.MaxLength > bcryptMaxPasswordLength {
+		p.MaxLength = bcryptMaxPasswordLength
+	}
+
+	return p
+}
+
+// ReadPasswordDenylist reads a denylist with one password per line. Empty lines and lines
+// starting with # are skipped.
+func ReadPasswordDenylist(r io.Reader) (map[string]struct{}, error) {
+	denylist := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist[strings.ToLower(line)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return denylist, nil
+}
+
+// validateUsername records an error in verr if the username has an invalid format.
+func validateUsername(verr *model.ValidationError, username string) {
+	if !usernameRe.MatchString(username) {
+		verr.Add("username", "must be 3 to 32 characters long and contain only latin letters, digits, dots, dashes and underscores")
+	}
+}
+
+// validate records in verr every rule of the policy the password breaks.
+func (p PasswordPolicy) validate(verr *model.ValidationError, field, username, password string) {
+	if len([]rune(password)) < p.MinLength {
+		verr.Add(field, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if len(password) > p.MaxLength {
+		verr.Add(field, fmt.Sprintf("must be at most %d bytes long", p.MaxLength))
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsDigit(c):
+			digit = true
+		case !unicode.IsLetter(c):
+			symbol = true
+		}
+	}
+
+	if p.RequireLowercase && !lower {
+		verr.Add(field, "must contain a lowercase letter")
+	}
+	if p.RequireUppercase && !upper {
+		verr.Add(field, "must contain an uppercase letter")
+	}
+	if p.RequireDigit && !digit {
+		verr.Add(field, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		verr.Add(field, "must contain a character other than a letter or a digit")
+	}
+
+	if username != "" && strings.EqualFold(password, username) {
+		verr.Add(field, "must not be the same as the username")
+	}
+	if _, ok := p.Denylist[strings.ToLower(password)]; ok {
+		verr.Add(field, "is too common")
+	}
+}
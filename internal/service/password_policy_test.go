@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	denylist, err := ReadPasswordDenylist(strings.NewReader("# comment\n\nPassword1\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	policy := PasswordPolicy{
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		Denylist:         denylist,
+	}.withDefaults()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		expected []string
+	}{
+		{name: "valid", username: "alice", password: "Correct-horse1"},
+		{name: "short", username: "alice", password: "Ab1!", expected: []string{"must be at least 8 characters long"}},
+		{name: "too long", username: "alice", password: "Ab1!" + strings.Repeat("a", 72), expected: []string{"must be at most 72 bytes long"}},
+		{
+			name:     "missing classes",
+			username: "alice",
+			password: "abcdefgh",
+			expected: []string{
+				"must contain an uppercase letter",
+				"must contain a digit",
+				"must contain a character other than a letter or a digit",
+			},
+		},
+		{name: "same as username", username: "Alice-2024", password: "alice-2024", expected: []string{"must contain an uppercase letter", "must not be the same as the username"}},
+		{name: "denylisted", username: "alice", password: "PASSWORD1", expected: []string{"must contain a lowercase letter", "must contain a character other than a letter or a digit", "is too common"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verr model.ValidationError
+			policy.validate(&verr, "password", tt.username, tt.password)
+
+			var messages []string
+			for _, field := range verr.Fields {
+				if field.Field != "password" {
+					t.Errorf("Expected password field, got %q", field.Field)
+				}
+				messages = append(messages, field.Message)
+			}
+
+			if strings.Join(messages, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected errors %q, got %q", tt.expected, messages)
+			}
+		})
+	}
+}
+
+func TestAuthService_CreateUser_Validation(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	_, err := s.CreateUser(model.User{Username: "a b", Password: "short"})
+
+	var verr *model.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+
+	if len(verr.Fields) != 2 || verr.Fields[0].Field != "username" || verr.Fields[1].Field != "password" {
+		t.Errorf("Expected username and password errors together, got %+v", verr.Fields)
+	}
+
+	if _, err := s.CreateUser(model.User{Username: "alice", Password: "long enough"}); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("Expected duplicate username to be rejected, got %v", err)
+	}
+}
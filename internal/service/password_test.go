@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword_Salted(t *testing.T) {
+	first, err := hashPassword("qwerty")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, err := hashPassword("qwerty")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if first == second {
+		t.Error("Expected hashes of the same password to differ")
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	current, err := hashPassword("qwerty")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	weak, err := bcrypt.GenerateFromPassword([]byte("qwerty"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		hash        string
+		password    string
+		ok          bool
+		needsRehash bool
+	}{
+		{name: "bcrypt hash", hash: current, password: "qwerty", ok: true},
+		{name: "bcrypt hash, wrong password", hash: current, password: "qwertz"},
+		{name: "bcrypt hash with low cost", hash: string(weak), password: "qwerty", ok: true, needsRehash: true},
+		{name: "legacy admin hash", hash: "646a6664666e6e766e66626e765116c28e651a19013822c09e5c70c9fc425a66dc", password: "kek", ok: true, needsRehash: true},
+		{name: "legacy hash, wrong password", hash: legacyPasswordHash("qwerty"), password: "qwertz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash, err := verifyPassword(tt.hash, tt.password)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if ok != tt.ok || needsRehash != tt.needsRehash {
+				t.Errorf("Expected ok=%t needsRehash=%t, got ok=%t needsRehash=%t", tt.ok, tt.needsRehash, ok, needsRehash)
+			}
+		})
+	}
+}
+
+func TestDummyPasswordHash(t *testing.T) {
+	// The dummy hash must cost as much to check as the hash of a real password.
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("Expected a valid bcrypt hash, got %v", err)
+	}
+	if cost != passwordHashCost {
+		t.Errorf("Expected cost %d, got %d", passwordHashCost, cost)
+	}
+}
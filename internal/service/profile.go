@@ -0,0 +1,59 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// localeRe is the format of preferred languages: a BCP 47 language tag such as "ru" or "en-US".
+var localeRe = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+type ProfileService struct {
+	r repository.Profile
+}
+
+func NewProfileService(r repository.Profile) *ProfileService {
+	return &ProfileService{r: r}
+}
+
+func (s *ProfileService) GetProfile(userID int) (model.Profile, error) {
+	return s.r.GetProfile(userID)
+}
+
+func (s *ProfileService) GetPreferences(userID int) (model.Preferences, error) {
+	return s.r.GetPreferences(userID)
+}
+
+// UpdatePreferences validates and stores the preferences of the user. The movie sort
+// is stored in its canonical form, so that it can be used as sort_by as is.
+func (s *ProfileService) UpdatePreferences(userID int, input model.UpdatePreferencesInput) (model.Profile, error) {
+	var verr model.ValidationError
+
+	if input.MovieSort != nil && *input.MovieSort != "" {
+		sort, err := model.ParseSort(*input.MovieSort, "asc", model.MovieSortFields)
+		if err != nil {
+			verr.Add("movie_sort", "must be a comma-separated list of "+strings.Join(model.MovieSortFields, ", ")+
+				" fields, a field prefixed with - is sorted in descending order")
+		} else {
+			movieSort := sort.String()
+			input.MovieSort = &movieSort
+		}
+	}
+
+	if input.Locale != nil && *input.Locale != "" && !localeRe.MatchString(*input.Locale) {
+		verr.Add("locale", "must be a language tag such as ru or en-US")
+	}
+
+	if err := verr.Err(); err != nil {
+		return model.Profile{}, err
+	}
+
+	if err := s.r.UpdatePreferences(userID, input); err != nil {
+		return model.Profile{}, err
+	}
+
+	return s.r.GetProfile(userID)
+}
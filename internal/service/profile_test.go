@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+// fakeProfileRepository keeps the profile of a single user in memory.
+type fakeProfileRepository struct {
+	profile model.Profile
+}
+
+func (r *fakeProfileRepository) GetProfile(userID int) (model.Profile, error) {
+	if userID != r.profile.ID {
+		return model.Profile{}, repository.ErrUserNotFound
+	}
+	return r.profile, nil
+}
+
+func (r *fakeProfileRepository) GetPreferences(userID int) (model.Preferences, error) {
+	return r.profile.Preferences, nil
+}
+
+func (r *fakeProfileRepository) UpdatePreferences(userID int, input model.UpdatePreferencesInput) error {
+	if userID != r.profile.ID {
+		return repository.ErrUserNotFound
+	}
+	if input.MovieSort != nil {
+		r.profile.Preferences.MovieSort = *input.MovieSort
+	}
+	if input.Locale != nil {
+		r.profile.Preferences.Locale = *input.Locale
+	}
+	return nil
+}
+
+func newTestProfileService() (*ProfileService, *fakeProfileRepository) {
+	repo := &fakeProfileRepository{
+		profile: model.Profile{ID: 1, Username: "alice", Role: "user", CreatedAt: time.Now()},
+	}
+
+	return NewProfileService(repo), repo
+}
+
+func TestProfileService_UpdatePreferences(t *testing.T) {
+	s, repo := newTestProfileService()
+
+	movieSort, locale := "title, -rating", "en-US"
+	profile, err := s.UpdatePreferences(1, model.UpdatePreferencesInput{MovieSort: &movieSort, Locale: &locale})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := model.Preferences{MovieSort: "title,-rating", Locale: "en-US"}
+	if profile.Preferences != expected {
+		t.Errorf("Expected preferences %+v, got %+v", expected, profile.Preferences)
+	}
+
+	reset := ""
+	if _, err := s.UpdatePreferences(1, model.UpdatePreferencesInput{MovieSort: &reset}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = model.Preferences{Locale: "en-US"}
+	if repo.profile.Preferences != expected {
+		t.Errorf("Expected preferences %+v, got %+v", expected, repo.profile.Preferences)
+	}
+}
+
+func TestProfileService_UpdatePreferences_Invalid(t *testing.T) {
+	s, repo := newTestProfileService()
+
+	movieSort, locale := "name", "english please"
+	_, err := s.UpdatePreferences(1, model.UpdatePreferencesInput{MovieSort: &movieSort, Locale: &locale})
+
+	var verr *model.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+
+	if len(verr.Fields) != 2 || verr.Fields[0].Field != "movie_sort" || verr.Fields[1].Field != "locale" {
+		t.Errorf("Unexpected field errors %+v", verr.Fields)
+	}
+
+	if repo.profile.Preferences != (model.Preferences{}) {
+		t.Errorf("Expected preferences to be left unchanged, got %+v", repo.profile.Preferences)
+	}
+}
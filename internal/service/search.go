@@ -0,0 +1,77 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+var ErrEmptySearchQuery = model.NewError(model.ErrBadRequest, "empty_search_query", "search query must contain at least one word")
+
+// buildTSQuery converts a user search query into PostgreSQL tsquery syntax.
+// Words are combined with AND, words in double quotes are matched as a phrase
+// and a word ending with "*" is matched as a prefix. Punctuation is dropped,
+// so the result never contains tsquery operators supplied by the user.
+func buildTSQuery(search string) string {
+	var terms []string
+
+	for i, part := range strings.Split(search, `"`) {
+		if i%2 == 1 {
+			if term := phraseTerm(strings.Fields(part)); term != "" {
+				terms = append(terms, term)
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			if term := phraseTerm([]string{word}); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// phraseTerm joins the lexemes of words into a tsquery phrase. A word made of several
+// lexemes, e.g. "spider-man", is matched as a phrase as well.
+func phraseTerm(words []string) string {
+	var lexemes []string
+
+	for _, word := range words {
+		prefix := strings.HasSuffix(word, "*")
+		parts := strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		for i, part := range parts {
+			lexeme := "'" + part + "'"
+			if prefix && i == len(parts)-1 {
+				lexeme += ":*"
+			}
+			lexemes = append(lexemes, lexeme)
+		}
+	}
+
+	switch len(lexemes) {
+	case 0:
+		return ""
+	case 1:
+		return lexemes[0]
+	default:
+		return "(" + strings.Join(lexemes, " <-> ") + ")"
+	}
+}
+
+// defaultSimilarityThreshold is used when the fuzzy search threshold is not configured.
+const defaultSimilarityThreshold = 0.5
+
+// similarityThreshold returns the configured threshold or the default one if it is not set.
+func (c SearchConfig) similarityThreshold() float64 {
+	if c.SimilarityThreshold <= 0 {
+		return defaultSimilarityThreshold
+	}
+
+	return c.SimilarityThreshold
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		search   string
+		expected string
+	}{
+		{name: "single word", search: "matrix", expected: "'matrix'"},
+		{name: "words", search: "  matrix   reloaded ", expected: "'matrix' & 'reloaded'"},
+		{name: "phrase", search: `"the matrix" reloaded`, expected: "('the' <-> 'matrix') & 'reloaded'"},
+		{name: "unterminated phrase", search: `reloaded "the matrix`, expected: "'reloaded' & ('the' <-> 'matrix')"},
+		{name: "prefix", search: "matr*", expected: "'matr':*"},
+		{name: "prefix in a phrase", search: `"the matr*"`, expected: "('the' <-> 'matr':*)"},
+		{name: "hyphenated word", search: "spider-man", expected: "('spider' <-> 'man')"},
+		{name: "hyphenated prefix", search: "spider-m*", expected: "('spider' <-> 'm':*)"},
+		{name: "punctuation", search: "matrix, reloaded.", expected: "'matrix' & 'reloaded'"},
+		{name: "operators", search: `!matrix | (neo) & 'trinity':*`, expected: "'matrix' & 'neo' & 'trinity':*"},
+		{name: "non-latin letters and digits", search: "матрица 1999", expected: "'матрица' & '1999'"},
+		{name: "empty", search: "", expected: ""},
+		{name: "spaces", search: "   ", expected: ""},
+		{name: "operators only", search: `!|&():'`, expected: ""},
+		{name: "empty phrase and prefix", search: `"" * "!"`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if query := buildTSQuery(tt.search); query != tt.expected {
+				t.Errorf("Expected tsquery %q, got %q", tt.expected, query)
+			}
+		})
+	}
+}
+
+func TestMovieService_SearchMovies_EmptyQuery(t *testing.T) {
+	// The query is rejected before it reaches the repository.
+	s := NewMovieService(nil, SearchConfig{})
+
+	if _, err := s.SearchMovies(`!|&():'`, 10, 0); !errors.Is(err, ErrEmptySearchQuery) {
+		t.Errorf("Expected error %v, got %v", ErrEmptySearchQuery, err)
+	}
+}
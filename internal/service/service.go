@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/avealice/filmhub/internal/model"
 	"github.com/avealice/filmhub/internal/repository"
 )
@@ -9,38 +11,114 @@ import (
 
 type Authorization interface {
 	CreateUser(user model.User) (int, error)
-	GenerateToken(username, password string) (string, error)
-	ParseToken(token string) (int, string, error)
+	GenerateToken(username, password, clientIP string) (model.Tokens, error)
+	RefreshTokens(refreshToken string) (model.Tokens, error)
+	Logout(identity model.Identity, refreshToken string) error
+	ParseToken(token string) (model.Identity, error)
+	JWKS() model.JSONWebKeySet
+	ChangePassword(userID int, currentPassword, newPassword, clientIP string) (model.Tokens, error)
+	RequestPasswordReset(username, clientIP string) error
+	ResetPassword(token, newPassword string) error
 }
 
 type Movie interface {
-	GetAllMovies(sortBy, sortOrder string) ([]model.MovieWithActors, error)
-	CreateMovie(movie model.InputMovie) error
+	GetAllMovies(filter model.MovieFilter, sort model.Sort, page model.Pagination) (model.MoviesPage, error)
+	CreateMovie(movie model.InputMovie) (model.MovieWithActors, error)
 	GetMovieByID(movieID int) (model.MovieWithActors, error)
-	DeleteByID(movieID int) error
-	UpdateMovie(movieID int, data model.InputMovie) error
-	GetMoviesByActor(actor string) ([]model.MovieWithActors, error)
-	GetMoviesByTitle(title string) ([]model.MovieWithActors, error)
+	DeleteByID(movieID int, ifMatch model.Versions) error
+	UpdateMovie(movieID int, data model.InputMovie, ifMatch model.Versions) (model.MovieWithActors, error)
+	PatchMovie(movieID int, input model.PatchMovieInput, ifMatch model.Versions) (model.MovieWithActors, error)
+	AddActor(movieID, actorID int) error
+	RemoveActor(movieID, actorID int) error
+	GetMoviesByActor(actor string, sort model.Sort) ([]model.MovieWithActors, error)
+	GetMoviesByTitle(title string, sort model.Sort) ([]model.MovieWithActors, error)
+	SearchMovies(query string, limit, offset int) ([]model.MovieSearchHit, error)
+	GetMoviesByActorFuzzy(actor string) ([]model.MovieWithActors, error)
 }
 
 type Actor interface {
-	CreateActor(actor model.InputActor) (int, error)
-	GetAllActors() ([]model.ActorWithMovies, error)
-	Delete(actorID int) error
+	CreateActor(actor model.InputActor) (model.ActorWithMovies, error)
+	GetAllActors(sort model.Sort, page model.Pagination) (model.ActorsPage, error)
+	Delete(actorID int, ifMatch model.Versions) error
 	Get(actorID int) (model.ActorWithMovies, error)
-	Update(actorID int, data model.InputActor) error
+	Update(actorID int, data model.InputActor, ifMatch model.Versions) (model.ActorWithMovies, error)
+	SearchActors(name string, limit int) ([]model.ActorSearchHit, error)
+}
+
+type User interface {
+	GetAllUsers(limit, offset int) (model.UsersPage, error)
+	GetUser(userID int) (model.UserAccount, error)
+	UpdateUser(currentUserID, userID int, input model.UpdateUserInput) (model.UserAccount, error)
+	DeleteUser(currentUserID, userID int) error
+}
+
+type APIKey interface {
+	CreateAPIKey(identity model.Identity, input model.APIKeyInput) (model.CreatedAPIKey, error)
+	GetAllAPIKeys() ([]model.APIKey, error)
+	RevokeAPIKey(keyID int) error
+	ParseAPIKey(key string) (model.Identity, error)
+}
+
+type Profile interface {
+	GetProfile(userID int) (model.Profile, error)
+	GetPreferences(userID int) (model.Preferences, error)
+	UpdatePreferences(userID int, input model.UpdatePreferencesInput) (model.Profile, error)
+}
+
+// OIDC signs users in through an external OpenID Connect provider.
+type OIDC interface {
+	AuthCodeURL() (string, error)
+	SignIn(code, state string) (model.Tokens, error)
 }
 
 type Service struct {
 	Authorization
 	Movie
 	Actor
+	User
+	APIKey
+	Profile
+	OIDC // nil if sign-in through a provider is not configured
 }
 
-func NewService(r *repository.Repository) *Service {
-	return &Service{
-		Authorization: NewAuthService(r.Authorization),
-		Movie:         NewMovieService(r.Movie),
-		Actor:         NewActorService(r.Actor),
+// Config holds the tunable settings of the services.
+type Config struct {
+	Auth   AuthConfig
+	OIDC   OIDCConfig
+	Search SearchConfig
+}
+
+// AuthConfig holds the settings of access tokens and sign-in.
+type AuthConfig struct {
+	TokenTTL         time.Duration // Lifetime of access tokens
+	RefreshTokenTTL  time.Duration // Lifetime of refresh tokens
+	Keys             *KeySet       // Keys that sign and verify access tokens
+	LoginThrottle    ThrottleConfig
+	PasswordPolicy   PasswordPolicy
+	PasswordResetTTL time.Duration // Lifetime of password reset tokens
+	Notifier         Notifier      // Delivers password reset tokens, defaults to stdout
+}
+
+// SearchConfig holds the settings of the fuzzy search.
+type SearchConfig struct {
+	SimilarityThreshold float64 // Minimum pg_trgm word similarity of a match, from 0 to 1
+}
+
+func NewService(r *repository.Repository, cfg Config) *Service {
+	auth := NewAuthService(r.Authorization, cfg.Auth)
+
+	services := &Service{
+		Authorization: auth,
+		Movie:         NewMovieService(r.Movie, cfg.Search),
+		Actor:         NewActorService(r.Actor, cfg.Search),
+		User:          NewUserService(r.Authorization, cfg.Auth.PasswordPolicy),
+		APIKey:        NewAPIKeyService(r.APIKey, r.Authorization),
+		Profile:       NewProfileService(r.Profile),
+	}
+
+	if cfg.OIDC.Issuer != "" {
+		services.OIDC = NewOIDCService(r.Authorization, auth, cfg.OIDC)
 	}
+
+	return services
 }
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTooManyAttempts is matched by the ThrottledError returned while sign-in is blocked.
+var ErrTooManyAttempts = errors.New("too many failed sign-in attempts")
+
+// ThrottledError reports that sign-in is temporarily blocked for a username or a client address.
+type ThrottledError struct {
+	RetryAfter time.Duration // Time left until the next attempt is allowed
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// LoginAttempts is the history of failed sign-in attempts under a single key.
+type LoginAttempts struct {
+	Failures    int       // Consecutive failures within the reset window
+	LastFailure time.Time // Time of the latest failure
+}
+
+// AttemptStore keeps failed sign-in attempts. Keys are opaque strings such as "user:alice"
+// or "ip:192.0.2.1". Implementations must be safe for concurrent use.
+type AttemptStore interface {
+	// Get returns the attempts recorded under key, or the zero value if there are none.
+	Get(key string) (LoginAttempts, error)
+	// AddFailure records a failure at now and returns the updated attempts. The counter
+	// starts over if the previous failure happened longer than window ago.
+	AddFailure(key string, now time.Time, window time.Duration) (LoginAttempts, error)
+	// Reset forgets the attempts recorded under key.
+	Reset(key string) error
+}
+
+// ThrottlePolicy describes how failed attempts under one kind of key are penalized.
+type ThrottlePolicy struct {
+	FreeAttempts    int           // Failures allowed without any delay
+	BaseDelay       time.Duration // Delay after the first failure past FreeAttempts, doubled by each next one
+	MaxDelay        time.Duration // Upper bound of the backoff delay
+	LockoutAfter    int           // Failures that lock the key out for LockoutDuration
+	LockoutDuration time.Duration // Length of the lockout
+	ResetAfter      time.Duration // Failures are forgotten after this long without a new one
+}
+
+// ThrottleConfig holds the sign-in throttling policies per username and per client address.
+type ThrottleConfig struct {
+	Username ThrottlePolicy
+	IP       ThrottlePolicy
+	Store    AttemptStore // Defaults to an in-memory store
+}
+
+var (
+	defaultUsernamePolicy = ThrottlePolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutAfter:    10,
+		LockoutDuration: 15 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+	defaultIPPolicy = ThrottlePolicy{
+		FreeAttempts:    10,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutAfter:    50,
+		LockoutDuration: 15 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+)
+
+// withDefaults fills the unset fields of the policy from def.
+func (p ThrottlePolicy) withDefaults(def ThrottlePolicy) ThrottlePolicy {
+	if p.FreeAttempts <= 0 {
+		p.FreeAttempts = def.FreeAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	if p.LockoutAfter <= 0 {
+		p.LockoutAfter = def.LockoutAfter
+	}
+	if p.LockoutDuration <= 0 {
+		p.LockoutDuration = def.LockoutDuration
+	}
+	if p.ResetAfter <= 0 {
+		p.ResetAfter = def.ResetAfter
+	}
+
+	return p
+}
+
+// delay returns how long the key is blocked after its latest failure.
+func (p ThrottlePolicy) delay(failures int) time.Duration {
+	if failures >= p.LockoutAfter {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	exp := failures - p.FreeAttempts - 1
+	if exp >= 32 {
+		return p.MaxDelay
+	}
+
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(exp)))
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
+
+// LoginThrottler slows down password guessing. Every failed sign-in is counted both for
+// the username and for the client address, and once the free attempts are used up the
+// key is blocked for an exponentially growing delay and eventually locked out.
+type LoginThrottler struct {
+	store    AttemptStore
+	prefix   string // Prefix of the keys, which separates throttlers sharing a store
+	username ThrottlePolicy
+	ip       ThrottlePolicy
+	now      func() time.Time
+}
+
+func NewLoginThrottler(cfg ThrottleConfig) *LoginThrottler {
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryAttemptStore()
+	}
+
+	return &LoginThrottler{
+		store:    store,
+		username: cfg.Username.withDefaults(defaultUsernamePolicy),
+		ip:       cfg.IP.withDefaults(defaultIPPolicy),
+		now:      time.Now,
+	}
+}
+
+// newPasswordResetThrottler returns a throttler of password reset requests with the same policies as
+// sign-in, whose keys do not clash with the sign-in ones, so that flooding resets does not block sign-in.
+func newPasswordResetThrottler(cfg ThrottleConfig) *LoginThrottler {
+	t := NewLoginThrottler(cfg)
+	t.prefix = "reset:"
+
+	return t
+}
+
+type throttleKey struct {
+	key    string
+	policy ThrottlePolicy
+}
+
+func (t *LoginThrottler) keys(username, ip string) []throttleKey {
+	keys := []throttleKey{{key: t.prefix + "user:" + strings.ToLower(username), policy: t.username}}
+	if ip != "" {
+		keys = append(keys, throttleKey{key: t.prefix + "ip:" + ip, policy: t.ip})
+	}
+
+	return keys
+}
+
+// Check returns a *ThrottledError if sign-in is currently blocked for the username or the address.
+func (t *LoginThrottler) Check(username, ip string) error {
+	now := t.now()
+
+	var retryAfter time.Duration
+	for _, k := range t.keys(username, ip) {
+		attempts, err := t.store.Get(k.key)
+		if err != nil {
+			return err
+		}
+
+		blockedUntil := attempts.LastFailure.Add(k.policy.delay(attempts.Failures))
+		if wait := blockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &ThrottledError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// Failure records a failed sign-in for the username and the address.
+func (t *LoginThrottler) Failure(username, ip string) error {
+	now := t.now()
+
+	for _, k := range t.keys(username, ip) {
+		if _, err := t.store.AddFailure(k.key, now, k.policy.ResetAfter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Success forgets the failures of the username. Failures of the address are kept,
+// so that signing in to one's own account does not reset a guessing attempt on others.
+func (t *LoginThrottler) Success(username string) error {
+	return t.store.Reset(t.prefix + "user:" + strings.ToLower(username))
+}
+
+// memoryAttemptStoreSweepInterval is how often expired entries are removed from a MemoryAttemptStore.
+const memoryAttemptStoreSweepInterval = time.Minute
+
+// MemoryAttemptStore keeps failed attempts in memory. It is enough for a single instance
+// of the application; several instances need a shared store.
+type MemoryAttemptStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryAttempts
+	lastSweep time.Time
+}
+
+type memoryAttempts struct {
+	LoginAttempts
+	expiresAt time.Time
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{entries: make(map[string]memoryAttempts)}
+}
+
+func (s *MemoryAttemptStore) Get(key string) (LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entries[key].LoginAttempts, nil
+}
+
+func (s *MemoryAttemptStore) AddFailure(key string, now time.Time, window time.Duration) (LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = memoryAttempts{}
+	}
+
+	entry.Failures++
+	entry.LastFailure = now
+	entry.expiresAt = now.Add(window)
+	s.entries[key] = entry
+
+	return entry.LoginAttempts, nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep removes expired entries so that the store does not grow without bound.
+func (s *MemoryAttemptStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryAttemptStoreSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestThrottler(now *time.Time) *LoginThrottler {
+	t := NewLoginThrottler(ThrottleConfig{
+		Username: ThrottlePolicy{
+			FreeAttempts:    2,
+			BaseDelay:       time.Second,
+			MaxDelay:        4 * time.Second,
+			LockoutAfter:    6,
+			LockoutDuration: time.Minute,
+			ResetAfter:      time.Hour,
+		},
+		IP: ThrottlePolicy{
+			FreeAttempts:    3,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Second,
+			LockoutAfter:    100,
+			LockoutDuration: time.Minute,
+			ResetAfter:      time.Hour,
+		},
+	})
+	t.now = func() time.Time { return *now }
+
+	return t
+}
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+
+	if err == nil {
+		return 0
+	}
+
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) || !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("Expected a throttled error, got %v", err)
+	}
+
+	return throttled.RetryAfter
+}
+
+func TestLoginThrottler_Backoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttler := newTestThrottler(&now)
+
+	// The delay after each failure from different addresses: two free attempts,
+	// then 1s, 2s, 4s capped by MaxDelay and finally the lockout.
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Minute}
+
+	for i, want := range expected {
+		if err := throttler.Failure("Alice", ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got := retryAfter(t, throttler.Check("alice", "")); got != want {
+			t.Errorf("After failure %d expected retry after %s, got %s", i+1, want, got)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if err := throttler.Check("alice", ""); err != nil {
+		t.Errorf("Expected the lockout to end, got %v", err)
+	}
+
+	if err := throttler.Success("alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := throttler.Failure("alice", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := throttler.Check("alice", ""); err != nil {
+		t.Errorf("Expected a successful sign-in to reset the failures, got %v", err)
+	}
+}
+
+func TestLoginThrottler_PerIP(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttler := newTestThrottler(&now)
+
+	for _, username := range []string{"alice", "bob", "carol", "dave"} {
+		if err := throttler.Failure(username, "192.0.2.1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if got := retryAfter(t, throttler.Check("eve", "192.0.2.1")); got != time.Second {
+		t.Errorf("Expected the address to be blocked for 1s, got %s", got)
+	}
+
+	if err := throttler.Check("eve", "192.0.2.2"); err != nil {
+		t.Errorf("Expected another address to be allowed, got %v", err)
+	}
+
+	if err := throttler.Success("alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := throttler.Check("alice", "192.0.2.1"); err == nil {
+		t.Error("Expected a successful sign-in to keep the failures of the address")
+	}
+}
+
+func TestMemoryAttemptStore_ResetAfter(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.AddFailure("user:alice", now, time.Hour); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	attempts, err := store.AddFailure("user:alice", now.Add(2*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if attempts.Failures != 1 {
+		t.Errorf("Expected the failures to start over after the window, got %d", attempts.Failures)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"github.com/avealice/filmhub/internal/model"
+	"github.com/avealice/filmhub/internal/repository"
+)
+
+var (
+	ErrUserNotFound     = repository.ErrUserNotFound
+	ErrUnknownRole      = repository.ErrUnknownRole
+	ErrSelfModification = model.NewError(model.ErrConflict, "self_modification", "you cannot disable, demote or delete your own account")
+)
+
+type UserService struct {
+	r         repository.Authorization
+	passwords PasswordPolicy
+}
+
+func NewUserService(r repository.Authorization, passwords PasswordPolicy) *UserService {
+	return &UserService{
+		r:         r,
+		passwords: passwords.withDefaults(),
+	}
+}
+
+func (s *UserService) GetAllUsers(limit, offset int) (model.UsersPage, error) {
+	return s.r.GetAllUsers(limit, offset)
+}
+
+func (s *UserService) GetUser(userID int) (model.UserAccount, error) {
+	return s.r.GetUserAccount(userID)
+}
+
+// UpdateUser changes the role, the disabled flag or the password of a user on behalf of
+// the administrator currentUserID. Administrators cannot disable or demote themselves,
+// so that at least one administrator always remains.
+func (s *UserService) UpdateUser(currentUserID, userID int, input model.UpdateUserInput) (model.UserAccount, error) {
+	if currentUserID == userID {
+		current, err := s.r.GetUserAccount(userID)
+		if err != nil {
+			return model.UserAccount{}, err
+		}
+
+		demoted := input.Role != nil && *input.Role != current.Role
+		disabled := input.Disabled != nil && *input.Disabled
+		if demoted || disabled {
+			return model.UserAccount{}, ErrSelfModification
+		}
+	}
+
+	if input.Password != nil {
+		user, err := s.r.GetUserAccount(userID)
+		if err != nil {
+			return model.UserAccount{}, err
+		}
+
+		var verr model.ValidationError
+		s.passwords.validate(&verr, "password", user.Username, *input.Password)
+		if err := verr.Err(); err != nil {
+			return model.UserAccount{}, err
+		}
+
+		hash, err := hashPassword(*input.Password)
+		if err != nil {
+			return model.UserAccount{}, err
+		}
+		input.Password = &hash
+	}
+
+	if err := s.r.UpdateUser(userID, input); err != nil {
+		return model.UserAccount{}, err
+	}
+
+	return s.r.GetUserAccount(userID)
+}
+
+func (s *UserService) DeleteUser(currentUserID, userID int) error {
+	if currentUserID == userID {
+		return ErrSelfModification
+	}
+
+	return s.r.DeleteUser(userID)
+}
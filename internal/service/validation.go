@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+// The limits below mirror the CHECK constraints of the movie and actor tables, so that invalid input is rejected
+// with a validation error instead of failing in the database.
+const (
+	maxMovieTitleLength       = 150
+	maxMovieDescriptionLength = 1000
+	minMovieRating            = 0
+	maxMovieRating            = 10
+	maxActorNameLength        = 255
+
+	// dateLayout is the format of movie release dates and actor birth dates, e.g. 2003-9-2 or 2003-09-02.
+	dateLayout = "2006-1-2"
+)
+
+var (
+	minReleaseDate = time.Date(1800, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxReleaseDate = time.Date(2050, time.December, 31, 0, 0, 0, 0, time.UTC)
+	minBirthDate   = time.Date(1800, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	actorGenders = []string{"male", "female", "other"}
+)
+
+// validateInputMovie checks a movie and the actors listed with it.
+func validateInputMovie(movie model.InputMovie) error {
+	verr := &model.ValidationError{}
+
+	validateMovie(verr, "", model.Movie{
+		Title:       movie.Title,
+		Description: movie.Description,
+		ReleaseDate: movie.ReleaseDate,
+		Rating:      movie.Rating,
+	})
+	for i, actor := range movie.Actors {
+		validateActor(verr, fmt.Sprintf("actors[%d].", i), actor, false)
+	}
+
+	return verr.Err()
+}
+
+// validatePatchMovieInput checks the fields set in a movie patch.
+func validatePatchMovieInput(input model.PatchMovieInput) error {
+	verr := &model.ValidationError{}
+
+	if input.Title != nil {
+		validateTitle(verr, "title", *input.Title)
+	}
+	if input.Description != nil {
+		validateDescription(verr, "description", *input.Description)
+	}
+	if input.ReleaseDate != nil {
+		validateDate(verr, "release_date", *input.ReleaseDate, minReleaseDate, maxReleaseDate)
+	}
+	if input.Rating != nil {
+		validateRating(verr, "rating", *input.Rating)
+	}
+
+	return verr.Err()
+}
+
+// validateInputActor checks an actor and the movies listed with it. When partial is set, as for updates, empty fields
+// of the actor itself mean "leave unchanged" and are not checked; listed movies are always checked in full, since
+// they are created if they do not exist yet.
+func validateInputActor(actor model.InputActor, partial bool) error {
+	verr := &model.ValidationError{}
+
+	validateActor(verr, "", model.Actor{
+		Name:      actor.Name,
+		Gender:    actor.Gender,
+		BirthDate: actor.BirthDate,
+	}, partial)
+	for i, movie := range actor.Movies {
+		validateMovie(verr, fmt.Sprintf("movies[%d].", i), movie)
+	}
+
+	return verr.Err()
+}
+
+func validateMovie(verr *model.ValidationError, prefix string, movie model.Movie) {
+	validateTitle(verr, prefix+"title", movie.Title)
+	validateDescription(verr, prefix+"description", movie.Description)
+	validateDate(verr, prefix+"release_date", movie.ReleaseDate, minReleaseDate, maxReleaseDate)
+	validateRating(verr, prefix+"rating", movie.Rating)
+}
+
+func validateTitle(verr *model.ValidationError, field, title string) {
+	if length := utf8.RuneCountInString(title); length < 1 || length > maxMovieTitleLength {
+		verr.Add(field, fmt.Sprintf("must be 1 to %d characters long", maxMovieTitleLength))
+	}
+}
+
+func validateDescription(verr *model.ValidationError, field, description string) {
+	if utf8.RuneCountInString(description) > maxMovieDescriptionLength {
+		verr.Add(field, fmt.Sprintf("must be at most %d characters long", maxMovieDescriptionLength))
+	}
+}
+
+func validateRating(verr *model.ValidationError, field string, rating int) {
+	if rating < minMovieRating || rating > maxMovieRating {
+		verr.Add(field, fmt.Sprintf("must be between %d and %d", minMovieRating, maxMovieRating))
+	}
+}
+
+func validateActor(verr *model.ValidationError, prefix string, actor model.Actor, partial bool) {
+	if actor.Name != "" || !partial {
+		if strings.TrimSpace(actor.Name) == "" || utf8.RuneCountInString(actor.Name) > maxActorNameLength {
+			verr.Add(prefix+"name", fmt.Sprintf("must be 1 to %d characters long", maxActorNameLength))
+		}
+	}
+
+	if actor.Gender != "" || !partial {
+		if !slices.Contains(actorGenders, actor.Gender) {
+			verr.Add(prefix+"gender", "must be one of "+strings.Join(actorGenders, ", "))
+		}
+	}
+
+	if actor.BirthDate != "" || !partial {
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		validateDate(verr, prefix+"birth_date", actor.BirthDate, minBirthDate, today)
+	}
+}
+
+// validateDate records an error unless value is a date in the dateLayout format between min and max inclusive.
+func validateDate(verr *model.ValidationError, field, value string, min, max time.Time) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		verr.Add(field, "must be a date in the YYYY-MM-DD format")
+		return
+	}
+
+	if date.Before(min) || date.After(max) {
+		verr.Add(field, fmt.Sprintf("must be between %s and %s", min.Format(time.DateOnly), max.Format(time.DateOnly)))
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avealice/filmhub/internal/model"
+)
+
+// fieldNames returns the names of the invalid fields reported by err, or nil if err is not a validation error.
+func fieldNames(err error) []string {
+	var verr *model.ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+
+	names := make([]string, 0, len(verr.Fields))
+	for _, field := range verr.Fields {
+		names = append(names, field.Field)
+	}
+
+	return names
+}
+
+func TestValidateInputMovie(t *testing.T) {
+	valid := model.InputMovie{Title: "Matrix", Description: "Neo", ReleaseDate: "1999-3-31", Rating: 9}
+
+	tests := []struct {
+		name     string
+		movie    func(m *model.InputMovie)
+		expected []string
+	}{
+		{name: "valid", movie: func(m *model.InputMovie) {}},
+		{name: "zero-padded date", movie: func(m *model.InputMovie) { m.ReleaseDate = "1999-03-31" }},
+		{name: "lowest rating", movie: func(m *model.InputMovie) { m.Rating = 0 }},
+		{name: "empty title", movie: func(m *model.InputMovie) { m.Title = "" }, expected: []string{"title"}},
+		{name: "long title", movie: func(m *model.InputMovie) { m.Title = strings.Repeat("a", 151) }, expected: []string{"title"}},
+		{name: "title of 150 cyrillic letters", movie: func(m *model.InputMovie) { m.Title = strings.Repeat("я", 150) }},
+		{name: "long description", movie: func(m *model.InputMovie) { m.Description = strings.Repeat("a", 1001) }, expected: []string{"description"}},
+		{name: "rating of 11", movie: func(m *model.InputMovie) { m.Rating = 11 }, expected: []string{"rating"}},
+		{name: "negative rating", movie: func(m *model.InputMovie) { m.Rating = -1 }, expected: []string{"rating"}},
+		{name: "malformed date", movie: func(m *model.InputMovie) { m.ReleaseDate = "31.03.1999" }, expected: []string{"release_date"}},
+		{name: "date too early", movie: func(m *model.InputMovie) { m.ReleaseDate = "1799-12-31" }, expected: []string{"release_date"}},
+		{name: "date too late", movie: func(m *model.InputMovie) { m.ReleaseDate = "2051-1-1" }, expected: []string{"release_date"}},
+		{
+			name: "all errors at once",
+			movie: func(m *model.InputMovie) {
+				*m = model.InputMovie{Rating: 11, Actors: []model.Actor{{Name: "Keanu Reeves", Gender: "unknown", BirthDate: "1964-9-2"}}}
+			},
+			expected: []string{"title", "release_date", "rating", "actors[0].gender"},
+		},
+		{
+			name: "actors are checked in full",
+			movie: func(m *model.InputMovie) {
+				m.Actors = []model.Actor{{Name: "Keanu Reeves"}}
+			},
+			expected: []string{"actors[0].gender", "actors[0].birth_date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movie := valid
+			tt.movie(&movie)
+
+			err := validateInputMovie(movie)
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			if got := fieldNames(err); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected invalid fields %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidatePatchMovieInput(t *testing.T) {
+	title, empty, rating, date := "Matrix", "", 11, "1999-3-31"
+
+	tests := []struct {
+		name     string
+		input    model.PatchMovieInput
+		expected []string
+	}{
+		{name: "empty patch", input: model.PatchMovieInput{}},
+		{name: "valid fields", input: model.PatchMovieInput{Title: &title, ReleaseDate: &date}},
+		{name: "cleared description", input: model.PatchMovieInput{Description: &empty}},
+		{name: "removed title", input: model.PatchMovieInput{Title: &empty}, expected: []string{"title"}},
+		{name: "invalid fields", input: model.PatchMovieInput{ReleaseDate: &empty, Rating: &rating}, expected: []string{"release_date", "rating"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePatchMovieInput(tt.input)
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if got := fieldNames(err); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected invalid fields %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateInputActor(t *testing.T) {
+	valid := model.InputActor{Name: "Keanu Reeves", Gender: "male", BirthDate: "1964-9-2"}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(dateLayout)
+
+	tests := []struct {
+		name     string
+		actor    func(a *model.InputActor)
+		partial  bool
+		expected []string
+	}{
+		{name: "valid", actor: func(a *model.InputActor) {}},
+		{name: "other gender", actor: func(a *model.InputActor) { a.Gender = "other" }},
+		{name: "blank name", actor: func(a *model.InputActor) { a.Name = "  " }, expected: []string{"name"}},
+		{name: "long name", actor: func(a *model.InputActor) { a.Name = strings.Repeat("a", 256) }, expected: []string{"name"}},
+		{name: "unknown gender", actor: func(a *model.InputActor) { a.Gender = "Male" }, expected: []string{"gender"}},
+		{name: "birth date in the future", actor: func(a *model.InputActor) { a.BirthDate = tomorrow }, expected: []string{"birth_date"}},
+		{name: "birth date too early", actor: func(a *model.InputActor) { a.BirthDate = "1799-1-1" }, expected: []string{"birth_date"}},
+		{
+			name: "invalid movie",
+			actor: func(a *model.InputActor) {
+				a.Movies = []model.Movie{{Title: "Matrix", ReleaseDate: "1999-3-31", Rating: 12}}
+			},
+			expected: []string{"movies[0].rating"},
+		},
+		{name: "partial update skips empty fields", actor: func(a *model.InputActor) { *a = model.InputActor{} }, partial: true},
+		{name: "partial update checks given fields", actor: func(a *model.InputActor) { *a = model.InputActor{Gender: "x"} }, partial: true, expected: []string{"gender"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := valid
+			tt.actor(&actor)
+
+			err := validateInputActor(actor, tt.partial)
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			if got := fieldNames(err); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected invalid fields %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMovieService_CreateMovie_Invalid(t *testing.T) {
+	// The repository is not set: invalid input must be rejected before it is reached.
+	s := &MovieService{}
+
+	_, err := s.CreateMovie(model.InputMovie{Title: "Matrix", ReleaseDate: "1999-3-31", Rating: 11})
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+}
+
+func TestActorService_CreateActor_Invalid(t *testing.T) {
+	s := &ActorService{}
+
+	_, err := s.CreateActor(model.InputActor{Name: "Keanu Reeves", Gender: "unknown", BirthDate: "1964-9-2"})
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+}
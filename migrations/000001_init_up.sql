@@ -27,4 +27,5 @@ CREATE TABLE IF NOT EXISTS users (
     role VARCHAR(5) CHECK (role IN ('admin', 'user')) NOT NULL
 );
 
-INSERT INTO users (username, password_hash, role) VALUES ('admin', '646a6664666e6e766e66626e765116c28e651a19013822c09e5c70c9fc425a66dc', 'admin');
+INSERT INTO users (username, password_hash, role) VALUES ('admin', '646a6664666e6e766e66626e765116c28e651a19013822c09e5c70c9fc425a66dc', 'admin')
+ON CONFLICT (username) DO NOTHING;
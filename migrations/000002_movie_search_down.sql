@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS movie_search_vector_idx;
+
+ALTER TABLE movie DROP COLUMN IF EXISTS search_vector;
\ No newline at end of file
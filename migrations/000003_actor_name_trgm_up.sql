@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS actor_name_trgm_idx ON actor USING GIN (name gin_trgm_ops);
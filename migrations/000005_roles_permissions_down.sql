@@ -0,0 +1,13 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+
+UPDATE users SET role = 'user' WHERE role NOT IN ('admin', 'user');
+
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(5);
+
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user'));
+
+DROP TABLE IF EXISTS role_permissions;
+
+DROP TABLE IF EXISTS permissions;
+
+DROP TABLE IF EXISTS roles;
\ No newline at end of file
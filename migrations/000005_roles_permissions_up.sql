@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(32) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(32) REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(64) REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access'),
+    ('editor', 'Creates and edits catalogue data'),
+    ('user', 'Reads catalogue data')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('movie:write', 'Create and update movies'),
+    ('movie:delete', 'Delete movies'),
+    ('actor:write', 'Create and update actors'),
+    ('actor:delete', 'Delete actors'),
+    ('user:manage', 'Manage user accounts and roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'movie:write'),
+    ('admin', 'movie:delete'),
+    ('admin', 'actor:write'),
+    ('admin', 'actor:delete'),
+    ('admin', 'user:manage'),
+    ('editor', 'movie:write'),
+    ('editor', 'actor:write')
+ON CONFLICT (role, permission) DO NOTHING;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(32);
+
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name);
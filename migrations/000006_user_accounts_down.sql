@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS created_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS disabled;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS sessions_revoked_at;
+
+DROP TABLE IF EXISTS password_reset_tokens;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    movie_sort VARCHAR(255) NOT NULL DEFAULT '',
+    locale VARCHAR(35) NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
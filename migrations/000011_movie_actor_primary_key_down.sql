@@ -0,0 +1,3 @@
+ALTER TABLE movie_actor DROP CONSTRAINT IF EXISTS movie_actor_pkey;
+ALTER TABLE movie_actor ALTER COLUMN movie_id DROP NOT NULL;
+ALTER TABLE movie_actor ALTER COLUMN actor_id DROP NOT NULL;
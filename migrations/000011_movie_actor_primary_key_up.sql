@@ -0,0 +1,7 @@
+DELETE FROM movie_actor WHERE movie_id IS NULL OR actor_id IS NULL;
+
+DELETE FROM movie_actor a
+USING movie_actor b
+WHERE a.ctid < b.ctid AND a.movie_id = b.movie_id AND a.actor_id = b.actor_id;
+
+ALTER TABLE movie_actor ADD PRIMARY KEY (movie_id, actor_id);
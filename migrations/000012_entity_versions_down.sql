@@ -0,0 +1,2 @@
+ALTER TABLE actor DROP COLUMN IF EXISTS version;
+ALTER TABLE movie DROP COLUMN IF EXISTS version;
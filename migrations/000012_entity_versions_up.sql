@@ -0,0 +1,2 @@
+ALTER TABLE movie ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE actor ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL migrations of the database schema.
+//
+// Every migration consists of two files, NNNNNN_name_up.sql and NNNNNN_name_down.sql,
+// where NNNNNN is the version of the migration.
+package migrations
+
+import "embed"
+
+// FS contains the SQL files of all migrations.
+//
+//go:embed *.sql
+var FS embed.FS